@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RemoteSyncSpec defines the desired state of RemoteSync
+type RemoteSyncSpec struct {
+	// Resources selects the objects in the namespace of the RemoteSync that
+	// are copied to the clusters
+	// +kubebuilder:validation:MinItems=1
+	Resources []ResourceSelector `json:"resources"`
+
+	// ClusterNames are the names of the clusters the objects are copied to
+	ClusterNames []string `json:"clusterNames,omitempty"`
+
+	// ClusterSelector selects the clusters the objects are copied to by their
+	// labels, the clusters are added to the ClusterNames
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// TargetNamespace is the namespace of the copies in the clusters, the
+	// namespace of the RemoteSync when not set
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// Values are available in the templates of the string values of the
+	// objects as {{ .Values.<key> }}, only objects with the annotation
+	// replication.nephio.org/render: "true" are rendered
+	Values map[string]string `json:"values,omitempty"`
+
+	// Overrides holds the values and target namespace of specific clusters
+	Overrides []ClusterOverride `json:"overrides,omitempty"`
+}
+
+// ResourceSelector selects namespaced objects of a kind by their labels
+type ResourceSelector struct {
+	// APIVersion of the objects, e.g. v1
+	APIVersion string `json:"apiVersion"`
+	// Kind of the objects, e.g. ConfigMap
+	Kind string `json:"kind"`
+	// Selector selects the objects by their labels, all objects of the kind
+	// when not set
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ClusterOverride overrides the values and the target namespace of a cluster
+type ClusterOverride struct {
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+	// TargetNamespace overrides the target namespace of the spec
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// Values are merged with the values of the spec
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// RemoteSyncStatus defines the observed state of RemoteSync
+type RemoteSyncStatus struct {
+	// ObservedGeneration is the generation of the spec of the status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions of the RemoteSync, Ready is true when the objects are copied
+	// to all clusters
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Clusters is the sync status per cluster
+	Clusters []ClusterStatus `json:"clusters,omitempty"`
+}
+
+// ClusterStatus is the sync status of the objects on a cluster
+type ClusterStatus struct {
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+	// State is Synced, Pending, Failed or Deleting
+	State string `json:"state"`
+	// Message explains the state
+	Message string `json:"message,omitempty"`
+	// Objects are the copies on the cluster, they are deleted when they are no
+	// longer selected
+	Objects []ObjectReference `json:"objects,omitempty"`
+}
+
+// ObjectReference references a copy on a cluster
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RemoteSync is the Schema for the remote sync API, it copies objects of the
+// management cluster to workload clusters
+type RemoteSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RemoteSyncSpec   `json:"spec,omitempty"`
+	Status RemoteSyncStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RemoteSyncList contains a list of RemoteSyncs
+type RemoteSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemoteSync `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RemoteSync{}, &RemoteSyncList{})
+}
+
+// RemoteSync type metadata.
+var (
+	RemoteSyncKind             = reflect.TypeOf(RemoteSync{}).Name()
+	RemoteSyncGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: RemoteSyncKind}.String()
+	RemoteSyncKindAPIVersion   = RemoteSyncKind + "." + GroupVersion.String()
+	RemoteSyncGroupVersionKind = GroupVersion.WithKind(RemoteSyncKind)
+)
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -57,6 +58,16 @@ func (r *Capi) GetClusterClient(ctx context.Context) (resource.APIPatchingApplic
 	return getCapiClusterClient(r.Secret)
 }
 
+// GetRESTConfig returns the rest config of the kubeconfig in the secret
+func (r *Capi) GetRESTConfig() (*rest.Config, error) {
+	return clientcmd.RESTConfigFromKubeConfig(r.Secret.Data["value"])
+}
+
+// IsReady returns true when the cluster api Cluster is ready
+func (r *Capi) IsReady(ctx context.Context) (bool, error) {
+	return r.isCapiClusterReady(ctx), nil
+}
+
 func (r *Capi) isCapiClusterReady(ctx context.Context) bool {
 	r.l = log.FromContext(ctx)
 	name := r.GetClusterName()
@@ -92,7 +103,7 @@ func isReady(cs capiv1beta1.Conditions) bool {
 
 func getCapiClusterClient(secret *corev1.Secret) (resource.APIPatchingApplicator, bool, error) {
 	//provide a rest config from the secret value
-	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["value"])
+	config, err := (&Capi{Secret: secret}).GetRESTConfig()
 	if err != nil {
 		return resource.APIPatchingApplicator{}, false, err
 	}
@@ -19,8 +19,10 @@ package cluster
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"github.com/nephio-project/nephio/controllers/pkg/cluster/capi"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,18 +30,87 @@ import (
 
 type Cluster struct {
 	client.Client
+	// Pool caches the clients of PooledClusterClients, DefaultPool when nil
+	Pool *Pool
+}
+
+// ClientFactory returns the ClusterClient of the secret, false when the secret
+// is not handled by the factory
+type ClientFactory func(c client.Client, secret *corev1.Secret) (ClusterClient, bool)
+
+type namedFactory struct {
+	name    string
+	factory ClientFactory
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   []namedFactory
+)
+
+func init() {
+	Register("capi", func(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+		if string(secret.Type) == capiSecretType && strings.Contains(secret.GetName(), "kubeconfig") {
+			return &capi.Capi{Client: c, Secret: secret}, true
+		}
+		return nil, false
+	})
+	Register("kubeconfig", func(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+		return kubeconfig.NewKubeconfig(c, secret)
+	})
+	Register("serviceaccount", func(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+		return kubeconfig.NewServiceAccount(c, secret)
+	})
+}
+
+// Register adds a ClientFactory, the factories are tried in the order they
+// are registered and the first one that handles the secret is used.
+// Registering a name again replaces the factory.
+func Register(name string, factory ClientFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	for i := range factories {
+		if factories[i].name == name {
+			factories[i].factory = factory
+			return
+		}
+	}
+	factories = append(factories, namedFactory{name: name, factory: factory})
 }
 
 func (r Cluster) GetClusterClient(secret *corev1.Secret) (ClusterClient, bool) {
-	switch string(secret.Type) {
-	case "cluster.x-k8s.io/secret":
-		if strings.Contains(secret.GetName(), "kubeconfig") {
-			return &capi.Capi{Client: r.Client, Secret: secret}, true
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	for _, f := range factories {
+		if clusterClient, ok := f.factory(r.Client, secret); ok {
+			if pooled, ok := clusterClient.(PooledClusterClient); ok {
+				return r.pooled(pooled, secret), true
+			}
+			return clusterClient, true
 		}
 	}
 	return nil, false
 }
 
+func (r Cluster) pooled(clusterClient PooledClusterClient, secret *corev1.Secret) ClusterClient {
+	pool := r.Pool
+	if pool == nil {
+		pool = DefaultPool
+	}
+	return &pooledClusterClient{PooledClusterClient: clusterClient, pool: pool, secret: secret}
+}
+
+// pooledClusterClient returns the client of the cluster from the pool
+type pooledClusterClient struct {
+	PooledClusterClient
+	pool   *Pool
+	secret *corev1.Secret
+}
+
+func (r *pooledClusterClient) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
+	return r.pool.GetClusterClient(ctx, r.PooledClusterClient, r.secret)
+}
+
 type ClusterClient interface {
 	GetClusterClient(context.Context) (resource.APIPatchingApplicator, bool, error)
 	GetClusterName() string
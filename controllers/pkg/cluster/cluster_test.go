@@ -20,8 +20,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestGetClusterClient(t *testing.T) {
@@ -42,6 +44,33 @@ func TestGetClusterClient(t *testing.T) {
 			},
 			want: true,
 		},
+		"CapiNoKubeconfig": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "a-ca",
+				},
+				Type: corev1.SecretType("cluster.x-k8s.io/secret"),
+			},
+			want: false,
+		},
+		"Kubeconfig": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a-credentials",
+					Labels: map[string]string{ClusterNameLabel: "a"},
+				},
+			},
+			want: true,
+		},
+		"ServiceAccount": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a-token",
+					Labels: map[string]string{ClusterNameLabel: "a", kubeconfig.ClusterTypeLabel: kubeconfig.TypeServiceAccount},
+				},
+			},
+			want: true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -55,3 +84,24 @@ func TestGetClusterClient(t *testing.T) {
 		})
 	}
 }
+
+type testClusterClient struct {
+	ClusterClient
+}
+
+func TestRegister(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"test": "true"}}}
+	Register("test", func(_ client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+		if secret.GetLabels()["test"] != "true" {
+			return nil, false
+		}
+		return &testClusterClient{}, true
+	})
+	got, ok := Cluster{}.GetClusterClient(secret)
+	if !ok {
+		t.Fatalf("GetClusterClient() ok = false, want true")
+	}
+	if _, ok := got.(*testClusterClient); !ok {
+		t.Errorf("GetClusterClient() = %T, want *testClusterClient", got)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeconfig implements the ClusterClient of clusters that are not
+// managed by cluster api, their credentials are a kubeconfig or a service
+// account token in a secret with the cluster.nephio.org/name label.
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ClusterNameLabel holds the name of the cluster of the credentials
+	ClusterNameLabel = "cluster.nephio.org/name"
+	// ClusterTypeLabel selects the kind of credentials, kubeconfig (default)
+	// or serviceaccount
+	ClusterTypeLabel = "cluster.nephio.org/type"
+	// ServerKey is the annotation with the url of the api server of a service
+	// account token secret
+	ServerKey = "cluster.nephio.org/server"
+
+	TypeKubeconfig     = "kubeconfig"
+	TypeServiceAccount = "serviceaccount"
+
+	// execPluginsEnv is a comma separated list of the exec credential plugins
+	// a kubeconfig is allowed to run, e.g. kubelogin,aws
+	execPluginsEnv = "CLUSTER_EXEC_PLUGINS"
+	// readyTimeout limits the request that checks the api server is reachable
+	readyTimeout = 10 * time.Second
+)
+
+// kubeconfigKeys are the keys of the kubeconfig in the secret, value is the
+// key cluster api uses
+var kubeconfigKeys = []string{"kubeconfig", "value"}
+
+func clusterType(secret *corev1.Secret) (string, bool) {
+	if secret.GetLabels()[ClusterNameLabel] == "" {
+		return "", false
+	}
+	if t := secret.GetLabels()[ClusterTypeLabel]; t != "" {
+		return t, true
+	}
+	return TypeKubeconfig, true
+}
+
+// Kubeconfig is the ClusterClient of a kubeconfig secret
+type Kubeconfig struct {
+	Secret *corev1.Secret
+}
+
+// NewKubeconfig returns the ClusterClient of a secret with a kubeconfig and
+// the cluster name label
+func NewKubeconfig(_ client.Client, secret *corev1.Secret) (*Kubeconfig, bool) {
+	if t, ok := clusterType(secret); !ok || t != TypeKubeconfig {
+		return nil, false
+	}
+	return &Kubeconfig{Secret: secret}, true
+}
+
+func (r *Kubeconfig) GetClusterName() string {
+	return r.Secret.GetLabels()[ClusterNameLabel]
+}
+
+func (r *Kubeconfig) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
+	config, err := r.GetRESTConfig()
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	return getClusterClient(ctx, config)
+}
+
+// IsReady returns true, the cluster is ready when its api server answers
+func (r *Kubeconfig) IsReady(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetRESTConfig returns the rest config of the kubeconfig, exec plugins must be
+// allowed in CLUSTER_EXEC_PLUGINS
+func (r *Kubeconfig) GetRESTConfig() (*rest.Config, error) {
+	var data []byte
+	for _, key := range kubeconfigKeys {
+		if v, ok := r.Secret.Data[key]; ok {
+			data = v
+			break
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("secret %s has no kubeconfig in %s", r.Secret.GetName(), strings.Join(kubeconfigKeys, " or "))
+	}
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	for name, authInfo := range cfg.AuthInfos {
+		if authInfo.Exec == nil {
+			continue
+		}
+		if !execPluginAllowed(authInfo.Exec.Command) {
+			return nil, fmt.Errorf("exec plugin %q of user %s is not allowed, see %s", authInfo.Exec.Command, name, execPluginsEnv)
+		}
+	}
+	return clientcmd.NewDefaultClientConfig(*cfg, &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+// execPluginAllowed returns true when the command is in the exec plugins env,
+// a kubeconfig in a secret must not be able to run any command in the
+// controller
+func execPluginAllowed(command string) bool {
+	allowed := []string{}
+	for _, p := range strings.Split(os.Getenv(execPluginsEnv), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			allowed = append(allowed, p)
+		}
+	}
+	return slices.Contains(allowed, command) || slices.Contains(allowed, filepath.Base(command))
+}
+
+// ServiceAccount is the ClusterClient of a service account token secret
+type ServiceAccount struct {
+	Secret *corev1.Secret
+}
+
+// NewServiceAccount returns the ClusterClient of a secret with the token and
+// ca.crt of a service account of the cluster, the server annotation and the
+// serviceaccount type label
+func NewServiceAccount(_ client.Client, secret *corev1.Secret) (*ServiceAccount, bool) {
+	if t, ok := clusterType(secret); !ok || t != TypeServiceAccount {
+		return nil, false
+	}
+	return &ServiceAccount{Secret: secret}, true
+}
+
+func (r *ServiceAccount) GetClusterName() string {
+	return r.Secret.GetLabels()[ClusterNameLabel]
+}
+
+func (r *ServiceAccount) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
+	config, err := r.GetRESTConfig()
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	return getClusterClient(ctx, config)
+}
+
+// IsReady returns true, the cluster is ready when its api server answers
+func (r *ServiceAccount) IsReady(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetRESTConfig returns the rest config of the token
+func (r *ServiceAccount) GetRESTConfig() (*rest.Config, error) {
+	server := r.Secret.GetAnnotations()[ServerKey]
+	if server == "" {
+		return nil, fmt.Errorf("secret %s has no %s annotation", r.Secret.GetName(), ServerKey)
+	}
+	token := r.Secret.Data[corev1.ServiceAccountTokenKey]
+	if len(token) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", r.Secret.GetName(), corev1.ServiceAccountTokenKey)
+	}
+	return &rest.Config{
+		Host:        server,
+		BearerToken: string(token),
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: r.Secret.Data[corev1.ServiceAccountRootCAKey],
+		},
+	}, nil
+}
+
+// getClusterClient returns the client of the cluster, the cluster is ready
+// when its api server answers
+func getClusterClient(ctx context.Context, config *rest.Config) (resource.APIPatchingApplicator, bool, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(withTimeout(config))
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	if _, err := dc.ServerVersion(); err != nil {
+		log.FromContext(ctx).Info("cluster api server not reachable", "host", config.Host, "err", err.Error())
+		return resource.APIPatchingApplicator{}, false, nil
+	}
+	clClient, err := client.New(config, client.Options{})
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	return resource.NewAPIPatchingApplicator(clClient), true, nil
+}
+
+func withTimeout(config *rest.Config) *rest.Config {
+	c := rest.CopyConfig(config)
+	c.Timeout = readyTimeout
+	return c
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newTestKubeconfig(t *testing.T, server string, exec *clientcmdapi.ExecConfig) []byte {
+	t.Helper()
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["edge01"] = &clientcmdapi.Cluster{Server: server}
+	cfg.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "token", Exec: exec}
+	cfg.Contexts["edge01"] = &clientcmdapi.Context{Cluster: "edge01", AuthInfo: "admin"}
+	cfg.CurrentContext = "edge01"
+	b, err := clientcmd.Write(*cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func newTestSecret(labels, annotations map[string]string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01-credentials", Labels: labels, Annotations: annotations},
+		Data:       data,
+	}
+}
+
+func TestFactories(t *testing.T) {
+	cases := map[string]struct {
+		labels             map[string]string
+		wantKubeconfig     bool
+		wantServiceAccount bool
+	}{
+		"NoLabel": {},
+		"Kubeconfig": {
+			labels:         map[string]string{ClusterNameLabel: "edge01"},
+			wantKubeconfig: true,
+		},
+		"ExplicitKubeconfig": {
+			labels:         map[string]string{ClusterNameLabel: "edge01", ClusterTypeLabel: TypeKubeconfig},
+			wantKubeconfig: true,
+		},
+		"ServiceAccount": {
+			labels:             map[string]string{ClusterNameLabel: "edge01", ClusterTypeLabel: TypeServiceAccount},
+			wantServiceAccount: true,
+		},
+		"UnknownType": {
+			labels: map[string]string{ClusterNameLabel: "edge01", ClusterTypeLabel: "other"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			secret := newTestSecret(tc.labels, nil, nil)
+			if _, ok := NewKubeconfig(nil, secret); ok != tc.wantKubeconfig {
+				t.Errorf("NewKubeconfig() = %t, want %t", ok, tc.wantKubeconfig)
+			}
+			if _, ok := NewServiceAccount(nil, secret); ok != tc.wantServiceAccount {
+				t.Errorf("NewServiceAccount() = %t, want %t", ok, tc.wantServiceAccount)
+			}
+		})
+	}
+}
+
+func TestKubeconfigGetRESTConfig(t *testing.T) {
+	exec := &clientcmdapi.ExecConfig{Command: "/usr/local/bin/kubelogin", APIVersion: "client.authentication.k8s.io/v1", InteractiveMode: clientcmdapi.NeverExecInteractiveMode}
+	cases := map[string]struct {
+		key         string
+		exec        *clientcmdapi.ExecConfig
+		execPlugins string
+		wantErr     bool
+	}{
+		"Kubeconfig": {key: "kubeconfig"},
+		"CapiKey":    {key: "value"},
+		"NoKey":      {key: "other", wantErr: true},
+		"ExecNotAllowed": {
+			key:     "kubeconfig",
+			exec:    exec,
+			wantErr: true,
+		},
+		"ExecAllowed": {
+			key:         "kubeconfig",
+			exec:        exec,
+			execPlugins: "aws, kubelogin",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(execPluginsEnv, tc.execPlugins)
+			secret := newTestSecret(map[string]string{ClusterNameLabel: "edge01"}, nil, map[string][]byte{tc.key: newTestKubeconfig(t, "https://edge01:6443", tc.exec)})
+			config, err := (&Kubeconfig{Secret: secret}).GetRESTConfig()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetRESTConfig() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if err == nil && config.Host != "https://edge01:6443" {
+				t.Errorf("GetRESTConfig() host = %s, want https://edge01:6443", config.Host)
+			}
+		})
+	}
+}
+
+func TestServiceAccountGetRESTConfig(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		data        map[string][]byte
+		wantErr     bool
+	}{
+		"Token": {
+			annotations: map[string]string{ServerKey: "https://edge01:6443"},
+			data:        map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token"), corev1.ServiceAccountRootCAKey: []byte("ca")},
+		},
+		"NoServer": {
+			data:    map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")},
+			wantErr: true,
+		},
+		"NoToken": {
+			annotations: map[string]string{ServerKey: "https://edge01:6443"},
+			wantErr:     true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			secret := newTestSecret(map[string]string{ClusterNameLabel: "edge01", ClusterTypeLabel: TypeServiceAccount}, tc.annotations, tc.data)
+			config, err := (&ServiceAccount{Secret: secret}).GetRESTConfig()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetRESTConfig() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if err == nil && (config.BearerToken != "token" || string(config.CAData) != "ca") {
+				t.Errorf("GetRESTConfig() = %+v, want token and ca", config)
+			}
+		})
+	}
+}
+
+func TestGetClusterClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(version.Info{Major: "1", Minor: "33"})
+	}))
+	defer srv.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	cases := map[string]struct {
+		server    string
+		wantReady bool
+	}{
+		"Ready":    {server: srv.URL, wantReady: true},
+		"NotReady": {server: down.URL},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			secret := newTestSecret(map[string]string{ClusterNameLabel: "edge01"}, nil, map[string][]byte{"kubeconfig": newTestKubeconfig(t, tc.server, nil)})
+			c := &Kubeconfig{Secret: secret}
+			if c.GetClusterName() != "edge01" {
+				t.Errorf("GetClusterName() = %s, want edge01", c.GetClusterName())
+			}
+			_, ready, err := c.GetClusterClient(context.TODO())
+			if err != nil {
+				t.Fatalf("GetClusterClient() error = %v", err)
+			}
+			if ready != tc.wantReady {
+				t.Errorf("GetClusterClient() ready = %t, want %t", ready, tc.wantReady)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// defaultFailureThreshold is the number of consecutive failed requests
+	// that open the circuit of a cluster
+	defaultFailureThreshold = 3
+	// defaultCooldown is the time the circuit stays open before a request is
+	// let through again
+	defaultCooldown = 30 * time.Second
+	// defaultIdleTimeout drops the clients of clusters that are not used
+	// anymore, e.g. deleted clusters
+	defaultIdleTimeout = time.Hour
+	// probeTimeout limits the request that checks the api server is reachable
+	probeTimeout = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned by the requests of a pooled client while the api
+// server of the cluster is considered unreachable
+var ErrCircuitOpen = errors.New("cluster api server unreachable, circuit open")
+
+// PooledClusterClient is a ClusterClient whose clients are cached in a Pool
+type PooledClusterClient interface {
+	ClusterClient
+	// GetRESTConfig returns the rest config of the credentials
+	GetRESTConfig() (*rest.Config, error)
+	// IsReady returns true when the cluster can be used, apart from its api
+	// server being reachable which is tracked by the pool
+	IsReady(ctx context.Context) (bool, error)
+}
+
+// DefaultPool caches the clients returned by Cluster.GetClusterClient
+var DefaultPool = NewPool()
+
+// Pool caches the client of every cluster credentials secret, the client is
+// built once and rebuilt when the content of the secret changes. The requests
+// of the clients are tracked per cluster, consecutive failures open a circuit
+// breaker and the cluster is reported as not ready without contacting it
+// until the cooldown passed.
+type Pool struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+	IdleTimeout      time.Duration
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*poolEntry
+	now     func() time.Time
+}
+
+// NewPool returns a Pool with the default failure threshold, cooldown and idle
+// timeout
+func NewPool() *Pool {
+	return &Pool{
+		FailureThreshold: defaultFailureThreshold,
+		Cooldown:         defaultCooldown,
+		IdleTimeout:      defaultIdleTimeout,
+		entries:          map[types.NamespacedName]*poolEntry{},
+		now:              time.Now,
+	}
+}
+
+type poolEntry struct {
+	clusterName string
+	hash        string
+	config      *rest.Config
+	breaker     *breaker
+	lastUsed    time.Time
+
+	mu     sync.Mutex
+	client client.Client
+}
+
+// GetClusterClient returns the cached client of the cluster, false when the
+// cluster is not ready, its api server is not reachable or its circuit is open
+func (r *Pool) GetClusterClient(ctx context.Context, cc PooledClusterClient, secret *corev1.Secret) (resource.APIPatchingApplicator, bool, error) {
+	log := log.FromContext(ctx).WithValues("cluster", cc.GetClusterName())
+	ready, err := cc.IsReady(ctx)
+	if err != nil || !ready {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	e, err := r.getEntry(cc, secret)
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	if !e.breaker.allow() {
+		log.Info("cluster api server not reachable, circuit open")
+		return resource.APIPatchingApplicator{}, false, nil
+	}
+	// a new client or a half open circuit checks the api server first
+	if e.getClient() == nil || e.breaker.getState() == stateHalfOpen {
+		if err := e.probe(); err != nil {
+			log.Info("cluster api server not reachable", "err", err.Error())
+			return resource.APIPatchingApplicator{}, false, nil
+		}
+	}
+	c, err := e.getOrCreateClient()
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	return resource.NewAPIPatchingApplicator(c), true, nil
+}
+
+// getEntry returns the entry of the secret, a new entry is built when there is
+// none or the content of the secret changed
+func (r *Pool) getEntry(cc PooledClusterClient, secret *corev1.Secret) (*poolEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.evictIdle(now)
+
+	key := types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}
+	hash := hashSecret(secret)
+	if e, ok := r.entries[key]; ok && e.hash == hash {
+		e.lastUsed = now
+		return e, nil
+	}
+	config, err := cc.GetRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	e := &poolEntry{
+		clusterName: cc.GetClusterName(),
+		hash:        hash,
+		breaker:     newBreaker(cc.GetClusterName(), r.FailureThreshold, r.Cooldown, r.now),
+		lastUsed:    now,
+	}
+	e.config = rest.CopyConfig(config)
+	e.config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &roundTripper{cluster: e.clusterName, breaker: e.breaker, next: rt}
+	})
+	r.entries[key] = e
+	clientBuilds.WithLabelValues(e.clusterName).Inc()
+	return e, nil
+}
+
+// evictIdle drops the entries that have not been used for the idle timeout
+func (r *Pool) evictIdle(now time.Time) {
+	for key, e := range r.entries {
+		if now.Sub(e.lastUsed) > r.IdleTimeout {
+			delete(r.entries, key)
+			circuitState.DeleteLabelValues(e.clusterName)
+		}
+	}
+}
+
+// probe requests the version of the api server, the result is recorded by the
+// circuit breaker of the entry
+func (r *poolEntry) probe() error {
+	config := rest.CopyConfig(r.config)
+	config.Timeout = probeTimeout
+	dc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = dc.ServerVersion()
+	return err
+}
+
+func (r *poolEntry) getClient() client.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.client
+}
+
+func (r *poolEntry) getOrCreateClient() (client.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		return r.client, nil
+	}
+	c, err := client.New(r.config, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+	r.client = c
+	return c, nil
+}
+
+// hashSecret returns the hash of the data and annotations of the secret, the
+// annotations hold e.g. the server of a service account token
+func hashSecret(secret *corev1.Secret) string {
+	h := sha256.New()
+	write := func(m map[string][]byte) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			h.Write([]byte(k))
+			h.Write([]byte{0})
+			h.Write(m[k])
+			h.Write([]byte{0})
+		}
+	}
+	write(secret.Data)
+	annotations := map[string][]byte{}
+	for k, v := range secret.GetAnnotations() {
+		annotations[k] = []byte(v)
+	}
+	write(annotations)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type circuit int
+
+const (
+	stateClosed circuit = iota
+	stateHalfOpen
+	stateOpen
+)
+
+// breaker opens after threshold consecutive failures, after the cooldown it
+// is half open and the next result closes or opens it again
+type breaker struct {
+	cluster   string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    circuit
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(cluster string, threshold int, cooldown time.Duration, now func() time.Time) *breaker {
+	circuitState.WithLabelValues(cluster).Set(float64(stateClosed))
+	return &breaker{cluster: cluster, threshold: threshold, cooldown: cooldown, now: now}
+}
+
+func (r *breaker) getState() circuit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// allow returns false while the circuit is open, an open circuit becomes half
+// open when the cooldown passed
+func (r *breaker) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == stateOpen {
+		if r.now().Sub(r.openedAt) < r.cooldown {
+			return false
+		}
+		r.setState(stateHalfOpen)
+	}
+	return true
+}
+
+func (r *breaker) success() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = 0
+	r.setState(stateClosed)
+}
+
+func (r *breaker) failure() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	if r.state == stateHalfOpen || r.failures >= r.threshold {
+		r.openedAt = r.now()
+		r.setState(stateOpen)
+	}
+}
+
+func (r *breaker) setState(state circuit) {
+	r.state = state
+	circuitState.WithLabelValues(r.cluster).Set(float64(state))
+}
+
+// roundTripper records the requests of a cluster in the metrics and its
+// circuit breaker and fails fast while the circuit is open
+type roundTripper struct {
+	cluster string
+	breaker *breaker
+	next    http.RoundTripper
+}
+
+func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !r.breaker.allow() {
+		requests.WithLabelValues(r.cluster, "circuit_open").Inc()
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
+	resp, err := r.next.RoundTrip(req)
+	requestDuration.WithLabelValues(r.cluster).Observe(time.Since(start).Seconds())
+	if err != nil {
+		requests.WithLabelValues(r.cluster, "error").Inc()
+		// a request canceled by the caller says nothing about the cluster
+		if req.Context().Err() == nil {
+			r.breaker.failure()
+		}
+		return nil, err
+	}
+	requests.WithLabelValues(r.cluster, strconv.Itoa(resp.StatusCode)).Inc()
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		r.breaker.failure()
+	default:
+		r.breaker.success()
+	}
+	return resp, nil
+}
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nephio_cluster_client_requests_total",
+		Help: "Requests to the api server of a cluster by status code, error or circuit_open",
+	}, []string{"cluster", "code"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nephio_cluster_client_request_duration_seconds",
+		Help:    "Latency of the requests to the api server of a cluster",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"cluster"})
+	circuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nephio_cluster_client_circuit_state",
+		Help: "Circuit breaker state of a cluster, 0 closed, 1 half open, 2 open",
+	}, []string{"cluster"})
+	clientBuilds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nephio_cluster_client_builds_total",
+		Help: "Clients built for a cluster, a client is rebuilt when its credentials change",
+	}, []string{"cluster"})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(requests, requestDuration, circuitState, clientBuilds)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// testServer is an api server that answers the version request, it returns
+// 503 while it is down
+type testServer struct {
+	*httptest.Server
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(version.Info{Major: "1", Minor: "33"})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestTokenSecret(cluster, server, token string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        cluster + "-credentials",
+			Labels:      map[string]string{ClusterNameLabel: cluster, kubeconfig.ClusterTypeLabel: kubeconfig.TypeServiceAccount},
+			Annotations: map[string]string{kubeconfig.ServerKey: server},
+		},
+		Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte(token)},
+	}
+}
+
+func getPooledClient(t *testing.T, pool *Pool, secret *corev1.Secret) bool {
+	t.Helper()
+	clusterClient, ok := Cluster{Pool: pool}.GetClusterClient(secret)
+	if !ok {
+		t.Fatalf("GetClusterClient() no cluster client for %s", secret.GetName())
+	}
+	_, ready, err := clusterClient.GetClusterClient(context.TODO())
+	if err != nil {
+		t.Fatalf("GetClusterClient() error = %v", err)
+	}
+	return ready
+}
+
+func TestPoolCachesClient(t *testing.T) {
+	srv := newTestServer(t)
+	pool := NewPool()
+	secret := newTestTokenSecret("pool-cache", srv.URL, "token")
+
+	for range 3 {
+		if !getPooledClient(t, pool, secret) {
+			t.Fatalf("GetClusterClient() ready = false, want true")
+		}
+	}
+	if got := srv.requests.Load(); got != 1 {
+		t.Errorf("api server requests = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(clientBuilds.WithLabelValues("pool-cache")); got != 1 {
+		t.Errorf("client builds = %v, want 1", got)
+	}
+
+	// rotated credentials rebuild the client
+	rotated := newTestTokenSecret("pool-cache", srv.URL, "rotated")
+	if !getPooledClient(t, pool, rotated) {
+		t.Fatalf("GetClusterClient() ready = false, want true")
+	}
+	if got := testutil.ToFloat64(clientBuilds.WithLabelValues("pool-cache")); got != 2 {
+		t.Errorf("client builds = %v, want 2", got)
+	}
+	if got := srv.requests.Load(); got != 2 {
+		t.Errorf("api server requests = %d, want 2", got)
+	}
+}
+
+func TestPoolCircuitBreaker(t *testing.T) {
+	srv := newTestServer(t)
+	srv.down.Store(true)
+	now := time.Now()
+	pool := NewPool()
+	pool.FailureThreshold = 2
+	pool.now = func() time.Time { return now }
+	secret := newTestTokenSecret("pool-breaker", srv.URL, "token")
+
+	for range 2 {
+		if getPooledClient(t, pool, secret) {
+			t.Fatalf("GetClusterClient() ready = true, want false")
+		}
+	}
+	if got := testutil.ToFloat64(circuitState.WithLabelValues("pool-breaker")); got != float64(stateOpen) {
+		t.Errorf("circuit state = %v, want open", got)
+	}
+
+	// the open circuit does not contact the api server
+	srv.down.Store(false)
+	if getPooledClient(t, pool, secret) {
+		t.Fatalf("GetClusterClient() ready = true, want false")
+	}
+	if got := srv.requests.Load(); got != 2 {
+		t.Errorf("api server requests = %d, want 2", got)
+	}
+
+	// after the cooldown the half open circuit is closed by a successful probe
+	now = now.Add(pool.Cooldown)
+	if !getPooledClient(t, pool, secret) {
+		t.Fatalf("GetClusterClient() ready = false, want true")
+	}
+	if got := testutil.ToFloat64(circuitState.WithLabelValues("pool-breaker")); got != float64(stateClosed) {
+		t.Errorf("circuit state = %v, want closed", got)
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	now := time.Now()
+	b := newBreaker("rt", 1, time.Minute, func() time.Time { return now })
+	rt := &roundTripper{cluster: "rt", breaker: b, next: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	req := httptest.NewRequest(http.MethodGet, "https://rt/version", nil)
+
+	if _, err := rt.RoundTrip(req); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("RoundTrip() error = %v, want connection error", err)
+	}
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("RoundTrip() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := testutil.ToFloat64(requests.WithLabelValues("rt", "circuit_open")); got != 1 {
+		t.Errorf("circuit open requests = %v, want 1", got)
+	}
+
+	// a canceled request does not count as a failure
+	b = newBreaker("rt", 1, time.Minute, func() time.Time { return now })
+	rt.breaker = b
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, err := rt.RoundTrip(req.WithContext(ctx)); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("RoundTrip() error = %v, want connection error", err)
+	}
+	if b.getState() != stateClosed {
+		t.Errorf("circuit state = %v, want closed", b.getState())
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
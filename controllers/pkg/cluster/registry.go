@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=clusterprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+
+const (
+	// ClusterNameIndex indexes the secrets by the name of the cluster they
+	// hold the credentials of
+	ClusterNameIndex = "clusterName"
+	// ClusterNameLabel registers a secret as the credentials of the cluster
+	// in its value explicitly
+	ClusterNameLabel = kubeconfig.ClusterNameLabel
+	// KubeconfigSecretKey is the annotation of a ClusterProfile that
+	// references the secret with the credentials of the cluster as
+	// [<namespace>/]<name>, the default namespace is the one of the profile
+	KubeconfigSecretKey = "nephio.org/kubeconfig-secret"
+
+	// capiClusterNameLabel is set by cluster api on the secrets of a cluster
+	capiClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+	capiSecretType       = "cluster.x-k8s.io/secret"
+)
+
+// ClusterProfileGVK is the kind of the ClusterProfile objects of the
+// multicluster api, they are read as unstructured so the api is optional
+var ClusterProfileGVK = schema.GroupVersionKind{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Kind: "ClusterProfile"}
+
+// capiClusterGVK is the kind of the cluster api Clusters, they are read as
+// unstructured so cluster api is optional
+var capiClusterGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+// IndexClusterName returns the cluster names a secret holds the credentials
+// of, the explicit label or the cluster api label of a kubeconfig secret
+func IndexClusterName(o client.Object) []string {
+	secret, ok := o.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
+	if name := secret.GetLabels()[ClusterNameLabel]; name != "" {
+		return []string{name}
+	}
+	// cluster api labels all secrets of a cluster, only the kubeconfig is
+	// relevant
+	if name := secret.GetLabels()[capiClusterNameLabel]; name != "" &&
+		string(secret.Type) == capiSecretType &&
+		secret.GetName() == name+"-kubeconfig" {
+		return []string{name}
+	}
+	return nil
+}
+
+var (
+	indexersMu sync.Mutex
+	indexers   = map[client.FieldIndexer]bool{}
+)
+
+// SetupIndexer adds the cluster name index of the secrets to the field
+// indexer of a manager, every reconciler that uses the registry calls it and
+// the index is only added once
+func SetupIndexer(ctx context.Context, indexer client.FieldIndexer) error {
+	indexersMu.Lock()
+	defer indexersMu.Unlock()
+	if indexers[indexer] {
+		return nil
+	}
+	if err := indexer.IndexField(ctx, &corev1.Secret{}, ClusterNameIndex, IndexClusterName); err != nil {
+		return err
+	}
+	indexers[indexer] = true
+	return nil
+}
+
+// Registry resolves the credentials of a cluster by its name, the client is
+// expected to be the cached client of a manager with the cluster name index
+type Registry struct {
+	client.Client
+}
+
+// GetClusterSecret returns the secret with the credentials of the cluster, nil
+// when no secret is found. The secret referenced by a ClusterProfile of the
+// cluster is used first, then a secret with the explicit label and last the
+// kubeconfig secret of cluster api.
+func (r Registry) GetClusterSecret(ctx context.Context, clusterName string) (*corev1.Secret, error) {
+	secret, err := r.getProfileSecret(ctx, clusterName)
+	if err != nil || secret != nil {
+		return secret, err
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.MatchingFields{ClusterNameIndex: clusterName}); err != nil {
+		return nil, err
+	}
+	var found *corev1.Secret
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		if s.GetLabels()[ClusterNameLabel] == clusterName {
+			return s, nil
+		}
+		if found == nil {
+			found = s
+		}
+	}
+	return found, nil
+}
+
+// getProfileSecret returns the secret referenced by the ClusterProfile of the
+// cluster, nil when there is no profile, it does not reference a secret or
+// the ClusterProfile api is not installed
+func (r Registry) getProfileSecret(ctx context.Context, clusterName string) (*corev1.Secret, error) {
+	profiles := &unstructured.UnstructuredList{}
+	profiles.SetGroupVersionKind(ClusterProfileGVK.GroupVersion().WithKind(ClusterProfileGVK.Kind + "List"))
+	if err := r.List(ctx, profiles); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, profile := range profiles.Items {
+		ref := profile.GetAnnotations()[KubeconfigSecretKey]
+		if profile.GetName() != clusterName || ref == "" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: profile.GetNamespace(), Name: ref}
+		if ns, name, ok := strings.Cut(ref, "/"); ok {
+			key = types.NamespacedName{Namespace: ns, Name: name}
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, key, secret); err != nil {
+			if resource.IgnoreNotFound(err) == nil {
+				return nil, fmt.Errorf("secret %s of cluster profile %s not found", key, clusterName)
+			}
+			return nil, err
+		}
+		return secret, nil
+	}
+	return nil, nil
+}
+
+// ListClusterNames returns the sorted names of the clusters whose labels match
+// the selector, the labels of cluster api Clusters, ClusterProfiles and secrets
+// with the explicit cluster name label are matched. The apis of cluster api
+// and ClusterProfiles are optional.
+func (r Registry) ListClusterNames(ctx context.Context, selector labels.Selector) ([]string, error) {
+	names := sets.New[string]()
+	for _, gvk := range []schema.GroupVersionKind{capiClusterGVK, ClusterProfileGVK} {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, l, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, o := range l.Items {
+			names.Insert(o.GetName())
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets.Items {
+		if name := secret.GetLabels()[ClusterNameLabel]; name != "" {
+			names.Insert(name)
+		}
+	}
+	return sets.List(names), nil
+}
+
+// GetClusterClient returns the client of the cluster, false when no
+// credentials of the cluster are found
+func (r Registry) GetClusterClient(ctx context.Context, clusterName string) (ClusterClient, bool, error) {
+	secret, err := r.GetClusterSecret(ctx, clusterName)
+	if err != nil || secret == nil {
+		return nil, false, err
+	}
+	clusterClient, ok := Cluster{Client: r.Client}.GetClusterClient(secret)
+	return clusterClient, ok, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestSecret(name string, labels map[string]string, secretType string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+		Type:       corev1.SecretType(secretType),
+	}
+}
+
+func TestIndexClusterName(t *testing.T) {
+	cases := map[string]struct {
+		secret corev1.Secret
+		want   []string
+	}{
+		"CapiKubeconfig": {
+			secret: newTestSecret("edge1-kubeconfig", map[string]string{capiClusterNameLabel: "edge1"}, capiSecretType),
+			want:   []string{"edge1"},
+		},
+		"CapiOtherSecret": {
+			secret: newTestSecret("edge1-ca", map[string]string{capiClusterNameLabel: "edge1"}, capiSecretType),
+		},
+		"CapiWrongType": {
+			secret: newTestSecret("edge1-kubeconfig", map[string]string{capiClusterNameLabel: "edge1"}, "Opaque"),
+		},
+		"ExplicitLabel": {
+			secret: newTestSecret("my-credentials", map[string]string{ClusterNameLabel: "edge1"}, "Opaque"),
+			want:   []string{"edge1"},
+		},
+		"NameOnly": {
+			secret: newTestSecret("edge10-kubeconfig", nil, capiSecretType),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, IndexClusterName(&tc.secret)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetClusterSecret(t *testing.T) {
+	cases := map[string]struct {
+		profiles   []unstructured.Unstructured
+		profileErr error
+		secrets    []corev1.Secret
+		want       string
+	}{
+		"Capi": {
+			profileErr: &meta.NoKindMatchError{},
+			secrets: []corev1.Secret{
+				newTestSecret("edge1-kubeconfig", map[string]string{capiClusterNameLabel: "edge1"}, capiSecretType),
+			},
+			want: "edge1-kubeconfig",
+		},
+		"ExplicitLabelFirst": {
+			secrets: []corev1.Secret{
+				newTestSecret("edge1-kubeconfig", map[string]string{capiClusterNameLabel: "edge1"}, capiSecretType),
+				newTestSecret("edge1-admin", map[string]string{ClusterNameLabel: "edge1"}, capiSecretType),
+			},
+			want: "edge1-admin",
+		},
+		"ClusterProfile": {
+			profiles: func() []unstructured.Unstructured {
+				p := unstructured.Unstructured{}
+				p.SetGroupVersionKind(ClusterProfileGVK)
+				p.SetNamespace("fleet")
+				p.SetName("edge1")
+				p.SetAnnotations(map[string]string{KubeconfigSecretKey: "default/profile-secret"})
+				return []unstructured.Unstructured{p}
+			}(),
+			secrets: []corev1.Secret{
+				newTestSecret("edge1-kubeconfig", map[string]string{capiClusterNameLabel: "edge1"}, capiSecretType),
+			},
+			want: "profile-secret",
+		},
+		"NotFound": {},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clientMock := new(mocks.MockClient)
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList")).Return(tc.profileErr).Run(func(args mock.Arguments) {
+				args.Get(1).(*unstructured.UnstructuredList).Items = tc.profiles
+			})
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.SecretList"), client.MatchingFields{ClusterNameIndex: "edge1"}).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(1).(*corev1.SecretList).Items = tc.secrets
+			})
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "profile-secret"}, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*corev1.Secret).SetName("profile-secret")
+			})
+
+			secret, err := Registry{Client: clientMock}.GetClusterSecret(context.TODO(), "edge1")
+			if err != nil {
+				t.Fatalf("GetClusterSecret() error = %v", err)
+			}
+			got := ""
+			if secret != nil {
+				got = secret.GetName()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListClusterNames(t *testing.T) {
+	newObject := func(gvk schema.GroupVersionKind, name string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		u.SetName(name)
+		return u
+	}
+	cases := map[string]struct {
+		capiErr    error
+		profileErr error
+		want       []string
+	}{
+		"All": {
+			want: []string{"edge1", "edge2", "edge3"},
+		},
+		"NoOptionalApis": {
+			capiErr:    &meta.NoKindMatchError{},
+			profileErr: &meta.NoKindMatchError{},
+			want:       []string{"edge3"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			selector := labels.SelectorFromSet(labels.Set{"region": "west"})
+			clientMock := new(mocks.MockClient)
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList"), client.MatchingLabelsSelector{Selector: selector}).Return(func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+				ul := l.(*unstructured.UnstructuredList)
+				switch ul.GetKind() {
+				case "ClusterList":
+					if tc.capiErr != nil {
+						return tc.capiErr
+					}
+					ul.Items = []unstructured.Unstructured{newObject(capiClusterGVK, "edge1")}
+				case "ClusterProfileList":
+					if tc.profileErr != nil {
+						return tc.profileErr
+					}
+					ul.Items = []unstructured.Unstructured{newObject(ClusterProfileGVK, "edge2"), newObject(ClusterProfileGVK, "edge1")}
+				}
+				return nil
+			})
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.SecretList"), client.MatchingLabelsSelector{Selector: selector}).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(1).(*corev1.SecretList).Items = []corev1.Secret{
+					newTestSecret("edge3-credentials", map[string]string{ClusterNameLabel: "edge3", "region": "west"}, "Opaque"),
+					newTestSecret("other", map[string]string{"region": "west"}, "Opaque"),
+				}
+			})
+
+			got, err := Registry{Client: clientMock}.ListClusterNames(context.TODO(), selector)
+			if err != nil {
+				t.Fatalf("ListClusterNames() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GitClient is the client of the git server shared by the reconcilers, it
+// connects to the git server in the background with the provider selected by
+// the GIT_PROVIDER environment variable. The client reconnects when the
+// secret or the url of the git server change or the health probe fails.
+type GitClient interface {
+	Start(ctx context.Context)
+	IsInitialized() bool
+	// Generation is incremented each time the client (re)connects to the git
+	// server, 0 means the client never connected
+	Generation() int64
+	// Refresh triggers a check of the secret and the connection, e.g. when
+	// the secret changed
+	Refresh()
+	// Subscribe returns a channel that receives the generation after the
+	// client (re)connected
+	Subscribe() <-chan int64
+	Provider
+}
+
+const (
+	defaultGitSecretName = "git-user-secret"
+	// defaultHealthProbeInterval is the interval of the health probe and the
+	// check of the secret, overwritten by GIT_HEALTH_PROBE_INTERVAL
+	defaultHealthProbeInterval = 30 * time.Second
+	// retryInterval is the interval to retry a failed connection
+	retryInterval = 5 * time.Second
+	// secretURLKey in the secret overwrites GIT_URL, so the url changes
+	// without a restart of the controller
+	secretURLKey = "url"
+)
+
+var lock = &sync.Mutex{}
+
+var singleInstance *gc
+
+func GetClient(ctx context.Context, client resource.APIPatchingApplicator) (GitClient, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("failed creating git client, value of ctx cannot be nil")
+	}
+
+	if client.Client == nil {
+		return nil, fmt.Errorf("failed creating git client, value of client.Client cannot be nil")
+	}
+	// check if an instance is created using check-lock-check pattern implementation
+	if singleInstance == nil {
+		// Create a lock
+		lock.Lock()
+		defer lock.Unlock()
+		// Check instance is still null as another thread of execution may have initialized it before the lock was acquired.
+		if singleInstance == nil {
+			singleInstance = newClient(ctx, client)
+			log.FromContext(ctx).Info("Git Client Instance created now.")
+			go singleInstance.Start(ctx)
+		} else {
+			log.FromContext(ctx).Info("Git Client Instance already created.")
+		}
+	} else {
+		log.FromContext(ctx).Info("Git Client Instance already created.")
+	}
+	return singleInstance, nil
+}
+
+type gc struct {
+	client        resource.APIPatchingApplicator
+	probeInterval time.Duration
+	refresh       chan struct{}
+	l             logr.Logger
+
+	m           sync.RWMutex
+	provider    Provider
+	healthy     bool
+	fingerprint string
+	generation  int64
+	subscribers []chan int64
+}
+
+func newClient(ctx context.Context, client resource.APIPatchingApplicator) *gc {
+	probeInterval := defaultHealthProbeInterval
+	if v, ok := os.LookupEnv("GIT_HEALTH_PROBE_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.FromContext(ctx).Error(err, "invalid GIT_HEALTH_PROBE_INTERVAL, using default", "value", v, "default", defaultHealthProbeInterval)
+		} else {
+			probeInterval = d
+		}
+	}
+	return &gc{
+		client:        client,
+		probeInterval: probeInterval,
+		refresh:       make(chan struct{}, 1),
+	}
+}
+
+func (r *gc) Start(ctx context.Context) {
+	r.l = log.FromContext(ctx)
+	for {
+		// The Idea for continuously retrying is for enabling the user to
+		// create a secret eventually even after the controllers are started,
+		// once connected the loop checks the secret and the health of the
+		// git server.
+		interval := r.probeInterval
+		if err := r.connect(ctx); err != nil {
+			r.l.Error(err, "cannot connect to git server")
+			interval = retryInterval
+		}
+		select {
+		// The context is the one returned by ctrl.SetupSignalHandler().
+		// cancel() of this context will trigger <- ctx.Done().
+		case <-ctx.Done():
+			r.l.Info("controller manager context cancelled: Exit")
+			return
+		case <-r.refresh:
+		case <-time.After(interval):
+		}
+	}
+}
+
+// connect creates a new provider when the secret or the url changed or the
+// health probe of the current provider fails
+func (r *gc) connect(ctx context.Context) error {
+	providerKind, gitURL, secret, err := r.getConnectionConfig(ctx)
+	if err != nil {
+		r.setHealthy(false)
+		return err
+	}
+	fingerprint := connectionFingerprint(providerKind, gitURL, secret)
+
+	r.m.RLock()
+	provider, current := r.provider, r.fingerprint
+	r.m.RUnlock()
+	if provider != nil && fingerprint == current {
+		_, err := provider.GetMyUserInfo()
+		if err == nil {
+			r.setHealthy(true)
+			return nil
+		}
+		r.l.Error(err, "git server health probe failed, reconnecting")
+	} else if provider != nil {
+		r.l.Info("git server secret or url changed, reconnecting")
+	}
+
+	provider, err = New(providerKind, gitURL, secret)
+	if err != nil {
+		r.setHealthy(false)
+		return fmt.Errorf("cannot authenticate to git server with provider %s: %w", providerKind, err)
+	}
+
+	r.m.Lock()
+	r.provider = provider
+	r.fingerprint = fingerprint
+	r.healthy = true
+	r.generation++
+	generation := r.generation
+	subscribers := r.subscribers
+	r.m.Unlock()
+
+	r.l.Info("git client init done", "provider", providerKind, "generation", generation)
+	for _, ch := range subscribers {
+		// a pending notification already triggers the subscriber
+		select {
+		case ch <- generation:
+		default:
+		}
+	}
+	return nil
+}
+
+// getConnectionConfig returns the provider kind, the url and the secret to
+// connect to the git server
+func (r *gc) getConnectionConfig(ctx context.Context) (string, string, *corev1.Secret, error) {
+	providerKind := ProviderGitea
+	if gitProvider, ok := os.LookupEnv("GIT_PROVIDER"); ok {
+		providerKind = gitProvider
+	}
+
+	// get secret that was created when installing the git server
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, GetSecretKey(), secret); err != nil {
+		return "", "", nil, fmt.Errorf("cannot get secret, please follow README and create the git secret: %w", err)
+	}
+
+	gitURL := os.Getenv("GIT_URL")
+	if u := string(secret.Data[secretURLKey]); u != "" {
+		gitURL = u
+	}
+	if gitURL == "" {
+		return "", "", nil, fmt.Errorf("git url not defined")
+	}
+	return providerKind, gitURL, secret, nil
+}
+
+// GetSecretKey returns the namespace and name of the secret to connect to the
+// git server
+func GetSecretKey() types.NamespacedName {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if gitNamespace, ok := os.LookupEnv("GIT_NAMESPACE"); ok {
+		namespace = gitNamespace
+	}
+	secretName := defaultGitSecretName
+	if gitSecretName, ok := os.LookupEnv("GIT_SECRET_NAME"); ok {
+		secretName = gitSecretName
+	}
+	return types.NamespacedName{Namespace: namespace, Name: secretName}
+}
+
+// connectionFingerprint returns a hash of the connection config, a different
+// fingerprint requires a new provider
+func connectionFingerprint(providerKind, gitURL string, secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, v := range append([]string{providerKind, gitURL}, keys...) {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	for _, k := range keys {
+		h.Write(secret.Data[k])
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *gc) setHealthy(healthy bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.healthy = healthy
+}
+
+func (r *gc) getProvider() Provider {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.provider
+}
+
+func (r *gc) IsInitialized() bool {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.provider != nil && r.healthy
+}
+
+func (r *gc) Generation() int64 {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.generation
+}
+
+func (r *gc) Refresh() {
+	select {
+	case r.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *gc) Subscribe() <-chan int64 {
+	r.m.Lock()
+	defer r.m.Unlock()
+	ch := make(chan int64, 1)
+	r.subscribers = append(r.subscribers, ch)
+	return ch
+}
+
+func (r *gc) GetMyUserInfo() (*User, error) {
+	return r.getProvider().GetMyUserInfo()
+}
+
+func (r *gc) GetRepo(owner, name string) (*Repository, error) {
+	return r.getProvider().GetRepo(owner, name)
+}
+
+func (r *gc) CreateRepo(opts CreateRepoOptions) (*Repository, error) {
+	return r.getProvider().CreateRepo(opts)
+}
+
+func (r *gc) EditRepo(owner, name string, opts EditRepoOptions) (*Repository, error) {
+	return r.getProvider().EditRepo(owner, name, opts)
+}
+
+func (r *gc) DeleteRepo(owner, name string) error {
+	return r.getProvider().DeleteRepo(owner, name)
+}
+
+func (r *gc) CreateRepoFromTemplate(templateOwner, templateName string, opts CreateRepoFromTemplateOptions) (*Repository, error) {
+	return r.getProvider().CreateRepoFromTemplate(templateOwner, templateName, opts)
+}
+
+func (r *gc) CommitFiles(owner, repo string, opts CommitFilesOptions) error {
+	return r.getProvider().CommitFiles(owner, repo, opts)
+}
+
+func (r *gc) GetLatestCommit(owner, repo string) (*Commit, error) {
+	return r.getProvider().GetLatestCommit(owner, repo)
+}
+
+func (r *gc) ListAccessTokens() ([]*AccessToken, error) {
+	return r.getProvider().ListAccessTokens()
+}
+
+func (r *gc) CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error) {
+	return r.getProvider().CreateAccessToken(opts)
+}
+
+func (r *gc) DeleteAccessToken(name string) error {
+	return r.getProvider().DeleteAccessToken(name)
+}
+
+func (r *gc) ListDeployKeys(owner, repo string) ([]*DeployKey, error) {
+	return r.getProvider().ListDeployKeys(owner, repo)
+}
+
+func (r *gc) CreateDeployKey(owner, repo string, opts CreateDeployKeyOptions) (*DeployKey, error) {
+	return r.getProvider().CreateDeployKey(owner, repo, opts)
+}
+
+func (r *gc) DeleteDeployKey(owner, repo string, id int64) error {
+	return r.getProvider().DeleteDeployKey(owner, repo, id)
+}
+
+func (r *gc) GetUser(name string) (*User, error) {
+	return r.getProvider().GetUser(name)
+}
+
+func (r *gc) CreateUser(opts CreateUserOptions) (*User, error) {
+	return r.getProvider().CreateUser(opts)
+}
+
+func (r *gc) DeleteUser(name string) error {
+	return r.getProvider().DeleteUser(name)
+}
+
+func (r *gc) AddCollaborator(owner, repo, user, permission string) error {
+	return r.getProvider().AddCollaborator(owner, repo, user, permission)
+}
+
+func (r *gc) AddTeamRepo(org, team, repo, permission string) error {
+	return r.getProvider().AddTeamRepo(org, team, repo, permission)
+}
+
+func (r *gc) ProtectBranch(owner, repo string, opts BranchProtectionOptions) error {
+	return r.getProvider().ProtectBranch(owner, repo, opts)
+}
+
+func (r *gc) ListWebhooks(owner, repo string) ([]*Webhook, error) {
+	return r.getProvider().ListWebhooks(owner, repo)
+}
+
+func (r *gc) CreateWebhook(owner, repo string, opts WebhookOptions) (*Webhook, error) {
+	return r.getProvider().CreateWebhook(owner, repo, opts)
+}
+
+func (r *gc) EditWebhook(owner, repo string, id int64, opts WebhookOptions) error {
+	return r.getProvider().EditWebhook(owner, repo, id, opts)
+}
@@ -0,0 +1,129 @@
+/*
+ Copyright 2025 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestClient(t *testing.T) {
+	ctx := ctrl.SetupSignalHandler()
+
+	type args struct {
+		ctx    context.Context
+		client resource.APIPatchingApplicator
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    GitClient
+		wantErr bool
+	}{
+
+		{
+			name:    "ctx nil check",
+			args:    args{nil, resource.NewAPIPatchingApplicator(nil)},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "client nil check",
+			args:    args{ctx, resource.NewAPIPatchingApplicator(nil)},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetClient(tt.args.ctx, tt.args.client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetClient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetClient() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	srv, _ := newFakeGitLab(t)
+	defer srv.Close()
+	t.Setenv("GIT_PROVIDER", ProviderGitLab)
+	t.Setenv("GIT_URL", "http://invalid.local")
+	t.Setenv("GIT_NAMESPACE", "default")
+
+	// the url in the secret overwrites GIT_URL
+	secretData := map[string][]byte{"token": []byte("secret"), "url": []byte(srv.URL)}
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), GetSecretKey(), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*corev1.Secret).Data = secretData
+	})
+
+	r := newClient(context.TODO(), resource.NewAPIPatchingApplicator(clientMock))
+	generations := r.Subscribe()
+
+	if err := r.connect(context.TODO()); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	if !r.IsInitialized() || r.Generation() != 1 || <-generations != 1 {
+		t.Errorf("connect() initialized = %t, generation = %d, want connected with generation 1", r.IsInitialized(), r.Generation())
+	}
+
+	// the health probe succeeds, no reconnect
+	if err := r.connect(context.TODO()); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	if r.Generation() != 1 || len(generations) != 0 {
+		t.Errorf("connect() generation = %d, want 1", r.Generation())
+	}
+
+	// a rotated secret with invalid credentials marks the client unhealthy
+	secretData = map[string][]byte{"token": []byte("wrong"), "url": []byte(srv.URL)}
+	if err := r.connect(context.TODO()); err == nil {
+		t.Errorf("connect() expected error for invalid token")
+	}
+	if r.IsInitialized() || r.Generation() != 1 {
+		t.Errorf("connect() initialized = %t, generation = %d, want unhealthy with generation 1", r.IsInitialized(), r.Generation())
+	}
+
+	secretData = map[string][]byte{"token": []byte("secret"), "url": []byte(srv.URL), "username": []byte("nephio")}
+	if err := r.connect(context.TODO()); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	if !r.IsInitialized() || r.Generation() != 2 || <-generations != 2 {
+		t.Errorf("connect() initialized = %t, generation = %d, want reconnected with generation 2", r.IsInitialized(), r.Generation())
+	}
+
+	// the git server is gone, the health probe fails
+	srv.Close()
+	if err := r.connect(context.TODO()); err == nil {
+		t.Errorf("connect() expected error for unreachable git server")
+	}
+	if r.IsInitialized() {
+		t.Errorf("connect() initialized = %t, want unhealthy", r.IsInitialized())
+	}
+}
@@ -0,0 +1,455 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// giteaScopeReadRepository is the fine grained read scope of gitea 1.19+
+	giteaScopeReadRepository gitea.AccessTokenScope = "read:repository"
+	// giteaPageSize is the number of items per page of a list, the default
+	// maximum of the gitea server
+	giteaPageSize = 50
+)
+
+type giteaProvider struct {
+	client *gitea.Client
+	// rest is used for the apis the sdk does not cover
+	rest *restClient
+}
+
+// NewGiteaProvider returns a gitea provider, to create/list tokens gitea only
+// accepts basic authentication so the secret needs a username and password
+func NewGiteaProvider(url string, secret *corev1.Secret) (Provider, error) {
+	username, password := string(secret.Data["username"]), string(secret.Data["password"])
+	client, err := gitea.NewClient(url, gitea.SetBasicAuth(username, password))
+	if err != nil {
+		return nil, err
+	}
+	return &giteaProvider{
+		client: client,
+		rest: newRESTClient(strings.TrimSuffix(url, "/")+"/api/v1", func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}),
+	}, nil
+}
+
+// giteaChangeFiles is the body of the change files api of gitea 1.20+
+type giteaChangeFiles struct {
+	Branch  string                     `json:"branch"`
+	Message string                     `json:"message"`
+	Files   []giteaChangeFileOperation `json:"files"`
+}
+
+type giteaChangeFileOperation struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	// Content is base64 encoded
+	Content string `json:"content"`
+	// SHA of the file to update
+	SHA string `json:"sha,omitempty"`
+}
+
+// giteaList returns the items of all pages of a gitea list api, the next page
+// is taken from the link header of the response
+func giteaList[T any](list func(opts gitea.ListOptions) ([]T, *gitea.Response, error)) ([]T, error) {
+	items := []T{}
+	for page := 1; page != 0; {
+		pageItems, resp, err := list(gitea.ListOptions{Page: page, PageSize: giteaPageSize})
+		if err != nil {
+			return nil, giteaError(resp, err)
+		}
+		items = append(items, pageItems...)
+		page = 0
+		if resp != nil {
+			page = resp.NextPage
+		}
+	}
+	return items, nil
+}
+
+// giteaError maps the not found responses to ErrNotFound
+func giteaError(resp *gitea.Response, err error) error {
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, err.Error())
+	}
+	return err
+}
+
+func (r *giteaProvider) GetMyUserInfo() (*User, error) {
+	u, resp, err := r.client.GetMyUserInfo()
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return &User{ID: u.ID, UserName: u.UserName, Email: u.Email}, nil
+}
+
+func (r *giteaProvider) GetRepo(owner, name string) (*Repository, error) {
+	repo, resp, err := r.client.GetRepo(owner, name)
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaRepository(repo), nil
+}
+
+func (r *giteaProvider) CreateRepo(opts CreateRepoOptions) (*Repository, error) {
+	create := gitea.CreateRepoOption{
+		Name:          opts.Name,
+		Description:   opts.Description,
+		Private:       opts.Private,
+		IssueLabels:   opts.IssueLabels,
+		Gitignores:    opts.Gitignores,
+		License:       opts.License,
+		Readme:        opts.Readme,
+		DefaultBranch: opts.DefaultBranch,
+		TrustModel:    gitea.TrustModel(opts.TrustModel),
+		AutoInit:      opts.AutoInit,
+	}
+	var repo *gitea.Repository
+	var resp *gitea.Response
+	var err error
+	if opts.Organization != "" {
+		repo, resp, err = r.client.CreateOrgRepo(opts.Organization, create)
+	} else {
+		repo, resp, err = r.client.CreateRepo(create)
+	}
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaRepository(repo), nil
+}
+
+func (r *giteaProvider) EditRepo(owner, name string, opts EditRepoOptions) (*Repository, error) {
+	repo, resp, err := r.client.EditRepo(owner, name, gitea.EditRepoOption{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Private:     opts.Private,
+		Archived:    opts.Archived,
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaRepository(repo), nil
+}
+
+func (r *giteaProvider) DeleteRepo(owner, name string) error {
+	resp, err := r.client.DeleteRepo(owner, name)
+	return giteaError(resp, err)
+}
+
+func (r *giteaProvider) CreateRepoFromTemplate(templateOwner, templateName string, opts CreateRepoFromTemplateOptions) (*Repository, error) {
+	repo, resp, err := r.client.CreateRepoFromTemplate(templateOwner, templateName, gitea.CreateRepoFromTemplateOption{
+		Owner:       opts.Owner,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Private:     opts.Private,
+		GitContent:  true,
+		Labels:      true,
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaRepository(repo), nil
+}
+
+func (r *giteaProvider) CommitFiles(owner, repo string, opts CommitFilesOptions) error {
+	paths := make([]string, 0, len(opts.Files))
+	for path := range opts.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	change := &giteaChangeFiles{Branch: opts.Branch, Message: opts.Message}
+	for _, path := range paths {
+		op := giteaChangeFileOperation{
+			Operation: "create",
+			Path:      path,
+			Content:   base64.StdEncoding.EncodeToString(opts.Files[path]),
+		}
+		// existing files are updated with the sha of the current content
+		contents, resp, err := r.client.GetContents(owner, repo, opts.Branch, path)
+		if err == nil {
+			op.Operation = "update"
+			op.SHA = contents.SHA
+		} else if err := giteaError(resp, err); !IsNotFound(err) {
+			return err
+		}
+		change.Files = append(change.Files, op)
+	}
+	return r.rest.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/contents", url.PathEscape(owner), url.PathEscape(repo)), change, nil)
+}
+
+// GetLatestCommit lists the commits of the default branch, gitea returns a
+// conflict for an empty repository
+func (r *giteaProvider) GetLatestCommit(owner, repo string) (*Commit, error) {
+	commits, resp, err := r.client.ListRepoCommits(owner, repo, gitea.ListCommitOptions{
+		ListOptions: gitea.ListOptions{Page: 1, PageSize: 1},
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusConflict {
+			return nil, nil
+		}
+		return nil, giteaError(resp, err)
+	}
+	if len(commits) == 0 || commits[0].CommitMeta == nil {
+		return nil, nil
+	}
+	c := &Commit{SHA: commits[0].SHA, Created: commits[0].Created}
+	if commits[0].RepoCommit != nil {
+		c.Message = commits[0].RepoCommit.Message
+	}
+	return c, nil
+}
+
+func (r *giteaProvider) ListAccessTokens() ([]*AccessToken, error) {
+	tokens, err := giteaList(func(opts gitea.ListOptions) ([]*gitea.AccessToken, *gitea.Response, error) {
+		return r.client.ListAccessTokens(gitea.ListAccessTokensOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessTokens := make([]*AccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		accessTokens = append(accessTokens, toGiteaAccessToken(t))
+	}
+	return accessTokens, nil
+}
+
+func (r *giteaProvider) CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error) {
+	scopes := make([]gitea.AccessTokenScope, 0, len(opts.Scopes))
+	for _, s := range opts.Scopes {
+		switch s {
+		case AccessTokenScopeRepoRead:
+			scopes = append(scopes, giteaScopeReadRepository)
+		default:
+			// the neutral repo scope matches the gitea repo scope
+			scopes = append(scopes, gitea.AccessTokenScope(s))
+		}
+	}
+	t, resp, err := r.client.CreateAccessToken(gitea.CreateAccessTokenOption{
+		Name:   opts.Name,
+		Scopes: scopes,
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaAccessToken(t), nil
+}
+
+func (r *giteaProvider) DeleteAccessToken(name string) error {
+	resp, err := r.client.DeleteAccessToken(name)
+	return giteaError(resp, err)
+}
+
+func (r *giteaProvider) ListDeployKeys(owner, repo string) ([]*DeployKey, error) {
+	keys, err := giteaList(func(opts gitea.ListOptions) ([]*gitea.DeployKey, *gitea.Response, error) {
+		return r.client.ListDeployKeys(owner, repo, gitea.ListDeployKeysOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	deployKeys := make([]*DeployKey, 0, len(keys))
+	for _, k := range keys {
+		deployKeys = append(deployKeys, &DeployKey{ID: k.ID, Title: k.Title, Key: k.Key, ReadOnly: k.ReadOnly})
+	}
+	return deployKeys, nil
+}
+
+func (r *giteaProvider) CreateDeployKey(owner, repo string, opts CreateDeployKeyOptions) (*DeployKey, error) {
+	k, resp, err := r.client.CreateDeployKey(owner, repo, gitea.CreateKeyOption{
+		Title:    opts.Title,
+		Key:      opts.Key,
+		ReadOnly: opts.ReadOnly,
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return &DeployKey{ID: k.ID, Title: k.Title, Key: k.Key, ReadOnly: k.ReadOnly}, nil
+}
+
+func (r *giteaProvider) DeleteDeployKey(owner, repo string, id int64) error {
+	resp, err := r.client.DeleteDeployKey(owner, repo, id)
+	return giteaError(resp, err)
+}
+
+func (r *giteaProvider) GetUser(name string) (*User, error) {
+	u, resp, err := r.client.GetUserInfo(name)
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return &User{ID: u.ID, UserName: u.UserName, Email: u.Email}, nil
+}
+
+func (r *giteaProvider) CreateUser(opts CreateUserOptions) (*User, error) {
+	u, resp, err := r.client.AdminCreateUser(gitea.CreateUserOption{
+		Username:           opts.UserName,
+		Email:              opts.Email,
+		Password:           opts.Password,
+		MustChangePassword: ptr.To(false),
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return &User{ID: u.ID, UserName: u.UserName, Email: u.Email}, nil
+}
+
+func (r *giteaProvider) DeleteUser(name string) error {
+	resp, err := r.client.AdminDeleteUser(name)
+	return giteaError(resp, err)
+}
+
+func (r *giteaProvider) AddCollaborator(owner, repo, user, permission string) error {
+	accessMode := gitea.AccessModeRead
+	if permission == PermissionWrite {
+		accessMode = gitea.AccessModeWrite
+	}
+	resp, err := r.client.AddCollaborator(owner, repo, user, gitea.AddCollaboratorOption{Permission: &accessMode})
+	return giteaError(resp, err)
+}
+
+// AddTeamRepo adds the repository to the team, gitea sets the permission on
+// the team and not per repository
+func (r *giteaProvider) AddTeamRepo(org, team, repo, permission string) error {
+	for page := 1; ; page++ {
+		teams, resp, err := r.client.ListOrgTeams(org, gitea.ListTeamsOptions{ListOptions: gitea.ListOptions{Page: page, PageSize: 50}})
+		if err != nil {
+			return giteaError(resp, err)
+		}
+		for _, t := range teams {
+			if t.Name == team {
+				resp, err := r.client.AddTeamRepository(t.ID, org, repo)
+				return giteaError(resp, err)
+			}
+		}
+		if len(teams) < 50 {
+			return fmt.Errorf("%w: team %s/%s", ErrNotFound, org, team)
+		}
+	}
+}
+
+// ProtectBranch keeps direct pushes enabled so porch can push to the branch,
+// gitea never allows force pushes to a protected branch
+func (r *giteaProvider) ProtectBranch(owner, repo string, opts BranchProtectionOptions) error {
+	approvals := int64(opts.RequiredApprovals)
+	_, resp, err := r.client.GetBranchProtection(owner, repo, opts.Branch)
+	if err != nil {
+		if err := giteaError(resp, err); !IsNotFound(err) {
+			return err
+		}
+		_, resp, err = r.client.CreateBranchProtection(owner, repo, gitea.CreateBranchProtectionOption{
+			BranchName:        opts.Branch,
+			RuleName:          opts.Branch,
+			EnablePush:        true,
+			RequiredApprovals: approvals,
+		})
+		return giteaError(resp, err)
+	}
+	_, resp, err = r.client.EditBranchProtection(owner, repo, opts.Branch, gitea.EditBranchProtectionOption{
+		EnablePush:        ptr.To(true),
+		RequiredApprovals: &approvals,
+	})
+	return giteaError(resp, err)
+}
+
+func (r *giteaProvider) ListWebhooks(owner, repo string) ([]*Webhook, error) {
+	hooks, err := giteaList(func(opts gitea.ListOptions) ([]*gitea.Hook, *gitea.Response, error) {
+		return r.client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, 0, len(hooks))
+	for _, h := range hooks {
+		webhooks = append(webhooks, toGiteaWebhook(h))
+	}
+	return webhooks, nil
+}
+
+func (r *giteaProvider) CreateWebhook(owner, repo string, opts WebhookOptions) (*Webhook, error) {
+	hook, resp, err := r.client.CreateRepoHook(owner, repo, gitea.CreateHookOption{
+		Type:   gitea.HookTypeGitea,
+		Config: giteaWebhookConfig(opts),
+		Events: opts.Events,
+		Active: opts.Active,
+	})
+	if err != nil {
+		return nil, giteaError(resp, err)
+	}
+	return toGiteaWebhook(hook), nil
+}
+
+func (r *giteaProvider) EditWebhook(owner, repo string, id int64, opts WebhookOptions) error {
+	resp, err := r.client.EditRepoHook(owner, repo, id, gitea.EditHookOption{
+		Config: giteaWebhookConfig(opts),
+		Events: opts.Events,
+		Active: &opts.Active,
+	})
+	return giteaError(resp, err)
+}
+
+func giteaWebhookConfig(opts WebhookOptions) map[string]string {
+	config := map[string]string{"url": opts.URL, "content_type": opts.ContentType}
+	if opts.Secret != "" {
+		config["secret"] = opts.Secret
+	}
+	return config
+}
+
+func toGiteaWebhook(h *gitea.Hook) *Webhook {
+	return &Webhook{
+		ID:          h.ID,
+		URL:         h.Config["url"],
+		ContentType: h.Config["content_type"],
+		Events:      h.Events,
+		Active:      h.Active,
+	}
+}
+
+func toGiteaRepository(repo *gitea.Repository) *Repository {
+	r := &Repository{
+		ID:            repo.ID,
+		Name:          repo.Name,
+		Description:   repo.Description,
+		Private:       repo.Private,
+		DefaultBranch: repo.DefaultBranch,
+		CloneURL:      repo.CloneURL,
+		Archived:      repo.Archived,
+	}
+	if repo.Owner != nil {
+		r.Owner = repo.Owner.UserName
+	}
+	return r
+}
+
+func toGiteaAccessToken(t *gitea.AccessToken) *AccessToken {
+	scopes := make([]string, 0, len(t.Scopes))
+	for _, s := range t.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	return &AccessToken{ID: t.ID, Name: t.Name, Token: t.Token, Scopes: scopes}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeGiteaPage writes the page of the items requested with the page and
+// limit query, with a link to the next page like gitea
+func writeGiteaPage(w http.ResponseWriter, r *http.Request, items []map[string]any) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 || limit < 1 {
+		page, limit = 1, len(items)
+	}
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+	if end < len(items) {
+		next := *r.URL
+		q := next.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.String()))
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+func newFakeGitea(t *testing.T) *httptest.Server {
+	return newFakeGiteaWithMux(t, http.NewServeMux())
+}
+
+func newFakeGiteaWithMux(t *testing.T, mux *http.ServeMux) *httptest.Server {
+	mux.HandleFunc("GET /api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"version": "1.21.0"})
+	})
+	mux.HandleFunc("GET /api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "nephio" || p != "secret" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "unauthorized"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": 1, "login": "nephio", "email": "nephio@example.com"})
+	})
+	mux.HandleFunc("GET /api/v1/repos/nephio/mgmt", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 2, "name": "mgmt", "private": true, "default_branch": "main",
+			"clone_url": "http://gitea/nephio/mgmt.git",
+			"owner":     map[string]any{"login": "nephio"},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/repos/nephio/missing", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	})
+	mux.HandleFunc("GET /api/v1/users/nephio/tokens", func(w http.ResponseWriter, r *http.Request) {
+		tokens := []map[string]any{}
+		for i := range giteaPageSize + 2 {
+			tokens = append(tokens, map[string]any{"id": i + 1, "name": fmt.Sprintf("t%d", i+1)})
+		}
+		writeGiteaPage(w, r, tokens)
+	})
+	mux.HandleFunc("GET /api/v1/repos/nephio/mgmt/keys", func(w http.ResponseWriter, r *http.Request) {
+		keys := []map[string]any{}
+		for i := range giteaPageSize + 1 {
+			keys = append(keys, map[string]any{"id": i + 1, "title": fmt.Sprintf("k%d", i+1), "read_only": true})
+		}
+		writeGiteaPage(w, r, keys)
+	})
+	mux.HandleFunc("POST /api/v1/users/nephio/tokens", func(w http.ResponseWriter, r *http.Request) {
+		opts := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			t.Errorf("cannot decode token request: %v", err)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 3, "name": opts["name"], "sha1": "abc", "scopes": opts["scopes"]})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGiteaProvider(t *testing.T) {
+	srv := newFakeGitea(t)
+	defer srv.Close()
+
+	p, err := NewGiteaProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{
+		"username": []byte("nephio"),
+		"password": []byte("secret"),
+	}})
+	if err != nil {
+		t.Fatalf("NewGiteaProvider() error = %v", err)
+	}
+
+	u, err := p.GetMyUserInfo()
+	if err != nil {
+		t.Fatalf("GetMyUserInfo() error = %v", err)
+	}
+	if u.UserName != "nephio" {
+		t.Errorf("GetMyUserInfo() user = %s, want nephio", u.UserName)
+	}
+
+	repo, err := p.GetRepo("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if repo.Owner != "nephio" || repo.CloneURL != "http://gitea/nephio/mgmt.git" || !repo.Private {
+		t.Errorf("GetRepo() got = %+v", repo)
+	}
+
+	if _, err := p.GetRepo("nephio", "missing"); !IsNotFound(err) {
+		t.Errorf("GetRepo() error = %v, want not found", err)
+	}
+
+	tokens, err := p.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() error = %v", err)
+	}
+	if len(tokens) != giteaPageSize+2 || tokens[giteaPageSize+1].Name != fmt.Sprintf("t%d", giteaPageSize+2) {
+		t.Errorf("ListAccessTokens() got %d tokens, want the %d tokens of all pages", len(tokens), giteaPageSize+2)
+	}
+
+	keys, err := p.ListDeployKeys("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("ListDeployKeys() error = %v", err)
+	}
+	if len(keys) != giteaPageSize+1 || keys[giteaPageSize].Title != fmt.Sprintf("k%d", giteaPageSize+1) {
+		t.Errorf("ListDeployKeys() got %d keys, want the %d keys of all pages", len(keys), giteaPageSize+1)
+	}
+
+	token, err := p.CreateAccessToken(CreateAccessTokenOptions{Name: "t1", Scopes: []string{AccessTokenScopeRepo}})
+	if err != nil {
+		t.Fatalf("CreateAccessToken() error = %v", err)
+	}
+	if token.Token != "abc" || len(token.Scopes) != 1 || token.Scopes[0] != AccessTokenScopeRepo {
+		t.Errorf("CreateAccessToken() got = %+v", token)
+	}
+}
+
+func TestGiteaCommitFiles(t *testing.T) {
+	changes := &giteaChangeFiles{}
+	mux := http.NewServeMux()
+	// the README exists in the initialized repo
+	mux.HandleFunc("GET /api/v1/repos/nephio/edge01/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "README.md" || r.URL.Query().Get("ref") != "main" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"name": "README.md", "path": "README.md", "sha": "abc", "type": "file"})
+	})
+	mux.HandleFunc("POST /api/v1/repos/nephio/edge01/contents", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(changes); err != nil {
+			t.Errorf("cannot decode change files request: %v", err)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{})
+	})
+	srv := newFakeGiteaWithMux(t, mux)
+	defer srv.Close()
+
+	p, err := NewGiteaProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{
+		"username": []byte("nephio"),
+		"password": []byte("secret"),
+	}})
+	if err != nil {
+		t.Fatalf("NewGiteaProvider() error = %v", err)
+	}
+	if err := p.CommitFiles("nephio", "edge01", CommitFilesOptions{
+		Branch:  "main",
+		Message: "seed",
+		Files: map[string][]byte{
+			"README.md":          []byte("# edge01"),
+			"rootsync/Kptfile":   []byte("apiVersion: kpt.dev/v1"),
+			"rootsync/sync.yaml": []byte("kind: RootSync"),
+		},
+	}); err != nil {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+
+	want := &giteaChangeFiles{Branch: "main", Message: "seed", Files: []giteaChangeFileOperation{
+		{Operation: "update", Path: "README.md", Content: "IyBlZGdlMDE=", SHA: "abc"},
+		{Operation: "create", Path: "rootsync/Kptfile", Content: "YXBpVmVyc2lvbjoga3B0LmRldi92MQ=="},
+		{Operation: "create", Path: "rootsync/sync.yaml", Content: "a2luZDogUm9vdFN5bmM="},
+	}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("CommitFiles() request = %+v, want %+v", changes, want)
+	}
+}
+
+func TestGiteaGetLatestCommit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/nephio/{name}/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "edge01" {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "Git Repository is empty."})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"sha": "c1", "created": "2025-01-02T03:04:05Z", "commit": map[string]any{"message": "update"}},
+		})
+	})
+	srv := newFakeGiteaWithMux(t, mux)
+	defer srv.Close()
+
+	p, err := NewGiteaProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{
+		"username": []byte("nephio"),
+		"password": []byte("secret"),
+	}})
+	if err != nil {
+		t.Fatalf("NewGiteaProvider() error = %v", err)
+	}
+
+	commit, err := p.GetLatestCommit("nephio", "edge01")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if want := (&Commit{SHA: "c1", Message: "update", Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}); !reflect.DeepEqual(commit, want) {
+		t.Errorf("GetLatestCommit() got = %+v, want %+v", commit, want)
+	}
+	if commit, err := p.GetLatestCommit("nephio", "empty"); err != nil || commit != nil {
+		t.Errorf("GetLatestCommit() got = %+v, %v, want no commit", commit, err)
+	}
+}
+
+func TestGiteaRepositorySettings(t *testing.T) {
+	got := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/org/nephio-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, "create org repo")
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 4, "name": "edge01", "owner": map[string]any{"login": "nephio-org"}})
+	})
+	mux.HandleFunc("GET /api/v1/orgs/nephio-org/teams", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{{"id": 8, "name": "platform"}})
+	})
+	mux.HandleFunc("PUT /api/v1/teams/8/repos/nephio-org/edge01", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, "add team repo")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v1/repos/nephio-org/edge01/branch_protections/main", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	})
+	mux.HandleFunc("POST /api/v1/repos/nephio-org/edge01/branch_protections", func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode branch protection request: %v", err)
+		}
+		if req["rule_name"] != "main" || req["required_approvals"] != float64(2) || req["enable_push"] != true {
+			t.Errorf("unexpected branch protection request: %v", req)
+		}
+		got = append(got, "create branch protection")
+		writeJSON(w, http.StatusCreated, map[string]any{"rule_name": "main"})
+	})
+	mux.HandleFunc("GET /api/v1/repos/nephio-org/edge01/hooks", func(w http.ResponseWriter, r *http.Request) {
+		// the hook of the controller is on the second page
+		hooks := []map[string]any{}
+		for i := range giteaPageSize {
+			hooks = append(hooks, map[string]any{"id": 100 + i, "type": "slack", "active": true, "config": map[string]string{"url": fmt.Sprintf("http://other/%d", i)}})
+		}
+		hooks = append(hooks, map[string]any{"id": 9, "type": "gitea", "active": true, "events": []string{"push"}, "config": map[string]string{"url": "http://hook", "content_type": "json"}})
+		writeGiteaPage(w, r, hooks)
+	})
+	mux.HandleFunc("PATCH /api/v1/repos/nephio-org/edge01/hooks/9", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, "edit hook")
+		writeJSON(w, http.StatusOK, map[string]any{"id": 9})
+	})
+	srv := newFakeGiteaWithMux(t, mux)
+	defer srv.Close()
+
+	p, err := NewGiteaProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{
+		"username": []byte("nephio"),
+		"password": []byte("secret"),
+	}})
+	if err != nil {
+		t.Fatalf("NewGiteaProvider() error = %v", err)
+	}
+
+	if _, err := p.CreateRepo(CreateRepoOptions{Organization: "nephio-org", Name: "edge01"}); err != nil {
+		t.Fatalf("CreateRepo() error = %v", err)
+	}
+	if err := p.AddTeamRepo("nephio-org", "platform", "edge01", PermissionWrite); err != nil {
+		t.Errorf("AddTeamRepo() error = %v", err)
+	}
+	if err := p.AddTeamRepo("nephio-org", "unknown", "edge01", PermissionWrite); !IsNotFound(err) {
+		t.Errorf("AddTeamRepo() error = %v, want not found", err)
+	}
+	if err := p.ProtectBranch("nephio-org", "edge01", BranchProtectionOptions{Branch: "main", RequiredApprovals: 2}); err != nil {
+		t.Errorf("ProtectBranch() error = %v", err)
+	}
+	hooks, err := p.ListWebhooks("nephio-org", "edge01")
+	if err != nil {
+		t.Fatalf("ListWebhooks() error = %v", err)
+	}
+	if len(hooks) != giteaPageSize+1 {
+		t.Fatalf("ListWebhooks() got %d hooks, want the %d hooks of all pages", len(hooks), giteaPageSize+1)
+	}
+	if want := (&Webhook{ID: 9, URL: "http://hook", ContentType: "json", Events: []string{"push"}, Active: true}); !reflect.DeepEqual(hooks[giteaPageSize], want) {
+		t.Errorf("ListWebhooks() got = %+v, want %+v", hooks[giteaPageSize], want)
+	}
+	if err := p.EditWebhook("nephio-org", "edge01", 9, WebhookOptions{URL: "http://hook", Events: []string{"push", "pull_request"}, Active: true}); err != nil {
+		t.Errorf("EditWebhook() error = %v", err)
+	}
+
+	if want := []string{"create org repo", "add team repo", "create branch protection", "edit hook"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests got = %v, want %v", got, want)
+	}
+}
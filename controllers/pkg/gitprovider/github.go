@@ -0,0 +1,477 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+type githubProvider struct {
+	client *restClient
+}
+
+// NewGitHubProvider returns a github provider using the rest api, the secret
+// needs a personal access token with the repo scope in token (or password).
+// For github enterprise the api is served under /api/v3 of the server url.
+func NewGitHubProvider(gitURL string, secret *corev1.Secret) (Provider, error) {
+	token, err := getSecretToken(secret)
+	if err != nil {
+		return nil, err
+	}
+	p := &githubProvider{
+		client: newRESTClient(githubBaseURL(gitURL), func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+		}),
+	}
+	// validate the credentials like the gitea client does when it is created
+	if _, err := p.GetMyUserInfo(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func githubBaseURL(gitURL string) string {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.Host == "" || u.Host == "github.com" || u.Host == "api.github.com" {
+		return githubAPIURL
+	}
+	return strings.TrimSuffix(gitURL, "/") + "/api/v3"
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+type githubRepository struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+	CloneURL      string `json:"clone_url"`
+	Archived      bool   `json:"archived"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type githubCreateRepository struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	Private           bool   `json:"private"`
+	AutoInit          bool   `json:"auto_init"`
+	GitignoreTemplate string `json:"gitignore_template,omitempty"`
+	LicenseTemplate   string `json:"license_template,omitempty"`
+}
+
+type githubEditRepository struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Private     *bool   `json:"private,omitempty"`
+	Archived    *bool   `json:"archived,omitempty"`
+}
+
+type githubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message   string `json:"message"`
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+type githubDeployKey struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+type githubAddCollaborator struct {
+	Permission string `json:"permission"`
+}
+
+// githubBranchProtection is replaced as a whole, github requires the fields
+// to be present also when they are null
+type githubBranchProtection struct {
+	RequiredStatusChecks       *struct{}              `json:"required_status_checks"`
+	EnforceAdmins              bool                   `json:"enforce_admins"`
+	RequiredPullRequestReviews *githubRequiredReviews `json:"required_pull_request_reviews"`
+	Restrictions               *struct{}              `json:"restrictions"`
+	AllowForcePushes           bool                   `json:"allow_force_pushes"`
+}
+
+type githubRequiredReviews struct {
+	RequiredApprovingReviewCount int `json:"required_approving_review_count"`
+}
+
+type githubHook struct {
+	ID     int64            `json:"id,omitempty"`
+	Name   string           `json:"name,omitempty"`
+	Active bool             `json:"active"`
+	Events []string         `json:"events"`
+	Config githubHookConfig `json:"config"`
+}
+
+type githubHookConfig struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Secret      string `json:"secret,omitempty"`
+}
+
+type githubGenerateRepository struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Private     bool   `json:"private"`
+}
+
+type githubRef struct {
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+type githubGitCommit struct {
+	SHA  string `json:"sha"`
+	Tree struct {
+		SHA string `json:"sha"`
+	} `json:"tree"`
+}
+
+type githubBlob struct {
+	SHA      string `json:"sha,omitempty"`
+	Content  string `json:"content,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type githubTreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+type githubCreateTree struct {
+	BaseTree string            `json:"base_tree"`
+	Tree     []githubTreeEntry `json:"tree"`
+}
+
+type githubCreateCommit struct {
+	Message string   `json:"message"`
+	Tree    string   `json:"tree"`
+	Parents []string `json:"parents"`
+}
+
+type githubUpdateRef struct {
+	SHA string `json:"sha"`
+}
+
+func githubRepoPath(owner, name string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(name))
+}
+
+func (r *githubProvider) GetMyUserInfo() (*User, error) {
+	u := &githubUser{}
+	if err := r.client.do(http.MethodGet, "/user", nil, u); err != nil {
+		return nil, err
+	}
+	return &User{ID: u.ID, UserName: u.Login, Email: u.Email}, nil
+}
+
+func (r *githubProvider) GetRepo(owner, name string) (*Repository, error) {
+	repo := &githubRepository{}
+	if err := r.client.do(http.MethodGet, githubRepoPath(owner, name), nil, repo); err != nil {
+		return nil, err
+	}
+	return toGitHubRepository(repo), nil
+}
+
+func (r *githubProvider) CreateRepo(opts CreateRepoOptions) (*Repository, error) {
+	path := "/user/repos"
+	if opts.Organization != "" {
+		path = fmt.Sprintf("/orgs/%s/repos", url.PathEscape(opts.Organization))
+	}
+	repo := &githubRepository{}
+	if err := r.client.do(http.MethodPost, path, &githubCreateRepository{
+		Name:              opts.Name,
+		Description:       opts.Description,
+		Private:           opts.Private,
+		AutoInit:          opts.AutoInit,
+		GitignoreTemplate: opts.Gitignores,
+		LicenseTemplate:   opts.License,
+	}, repo); err != nil {
+		return nil, err
+	}
+	return toGitHubRepository(repo), nil
+}
+
+func (r *githubProvider) EditRepo(owner, name string, opts EditRepoOptions) (*Repository, error) {
+	repo := &githubRepository{}
+	if err := r.client.do(http.MethodPatch, githubRepoPath(owner, name), &githubEditRepository{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Private:     opts.Private,
+		Archived:    opts.Archived,
+	}, repo); err != nil {
+		return nil, err
+	}
+	return toGitHubRepository(repo), nil
+}
+
+func (r *githubProvider) DeleteRepo(owner, name string) error {
+	return r.client.do(http.MethodDelete, githubRepoPath(owner, name), nil, nil)
+}
+
+func (r *githubProvider) CreateRepoFromTemplate(templateOwner, templateName string, opts CreateRepoFromTemplateOptions) (*Repository, error) {
+	repo := &githubRepository{}
+	if err := r.client.do(http.MethodPost, githubRepoPath(templateOwner, templateName)+"/generate", &githubGenerateRepository{
+		Owner:       opts.Owner,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Private:     opts.Private,
+	}, repo); err != nil {
+		return nil, err
+	}
+	return toGitHubRepository(repo), nil
+}
+
+// CommitFiles uses the git data api, the content api creates a commit per
+// file. The branch needs a commit, so the repository must be initialized.
+func (r *githubProvider) CommitFiles(owner, repo string, opts CommitFilesOptions) error {
+	repoPath := githubRepoPath(owner, repo)
+	ref := &githubRef{}
+	if err := r.client.do(http.MethodGet, repoPath+"/git/ref/heads/"+opts.Branch, nil, ref); err != nil {
+		return err
+	}
+	parent := &githubGitCommit{}
+	if err := r.client.do(http.MethodGet, repoPath+"/git/commits/"+ref.Object.SHA, nil, parent); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(opts.Files))
+	for path := range opts.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	tree := &githubCreateTree{BaseTree: parent.Tree.SHA}
+	for _, path := range paths {
+		blob := &githubBlob{}
+		if err := r.client.do(http.MethodPost, repoPath+"/git/blobs", &githubBlob{
+			Content:  base64.StdEncoding.EncodeToString(opts.Files[path]),
+			Encoding: "base64",
+		}, blob); err != nil {
+			return err
+		}
+		tree.Tree = append(tree.Tree, githubTreeEntry{Path: path, Mode: "100644", Type: "blob", SHA: blob.SHA})
+	}
+	newTree := &githubGitCommit{}
+	if err := r.client.do(http.MethodPost, repoPath+"/git/trees", tree, &newTree.Tree); err != nil {
+		return err
+	}
+	commit := &githubGitCommit{}
+	if err := r.client.do(http.MethodPost, repoPath+"/git/commits", &githubCreateCommit{
+		Message: opts.Message,
+		Tree:    newTree.Tree.SHA,
+		Parents: []string{ref.Object.SHA},
+	}, commit); err != nil {
+		return err
+	}
+	return r.client.do(http.MethodPatch, repoPath+"/git/refs/heads/"+opts.Branch, &githubUpdateRef{SHA: commit.SHA}, nil)
+}
+
+// GetLatestCommit lists the commits of the default branch, github returns a
+// conflict for an empty repository
+func (r *githubProvider) GetLatestCommit(owner, repo string) (*Commit, error) {
+	commits := []githubCommit{}
+	if err := r.client.do(http.MethodGet, githubRepoPath(owner, repo)+"/commits?per_page=1", nil, &commits); err != nil {
+		if hasStatus(err, http.StatusConflict) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return &Commit{
+		SHA:     commits[0].SHA,
+		Message: commits[0].Commit.Message,
+		Created: commits[0].Commit.Committer.Date,
+	}, nil
+}
+
+// github has no api to manage personal access tokens
+func (r *githubProvider) ListAccessTokens() ([]*AccessToken, error) {
+	return nil, fmt.Errorf("%w: list access tokens", ErrNotSupported)
+}
+
+func (r *githubProvider) CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error) {
+	return nil, fmt.Errorf("%w: create access token", ErrNotSupported)
+}
+
+func (r *githubProvider) DeleteAccessToken(name string) error {
+	return fmt.Errorf("%w: delete access token", ErrNotSupported)
+}
+
+func (r *githubProvider) ListDeployKeys(owner, repo string) ([]*DeployKey, error) {
+	keys := []*githubDeployKey{}
+	if err := r.client.do(http.MethodGet, githubRepoPath(owner, repo)+"/keys", nil, &keys); err != nil {
+		return nil, err
+	}
+	deployKeys := make([]*DeployKey, 0, len(keys))
+	for _, k := range keys {
+		deployKeys = append(deployKeys, &DeployKey{ID: k.ID, Title: k.Title, Key: k.Key, ReadOnly: k.ReadOnly})
+	}
+	return deployKeys, nil
+}
+
+func (r *githubProvider) CreateDeployKey(owner, repo string, opts CreateDeployKeyOptions) (*DeployKey, error) {
+	k := &githubDeployKey{}
+	if err := r.client.do(http.MethodPost, githubRepoPath(owner, repo)+"/keys", &githubDeployKey{
+		Title:    opts.Title,
+		Key:      opts.Key,
+		ReadOnly: opts.ReadOnly,
+	}, k); err != nil {
+		return nil, err
+	}
+	return &DeployKey{ID: k.ID, Title: k.Title, Key: k.Key, ReadOnly: k.ReadOnly}, nil
+}
+
+func (r *githubProvider) DeleteDeployKey(owner, repo string, id int64) error {
+	return r.client.do(http.MethodDelete, fmt.Sprintf("%s/keys/%d", githubRepoPath(owner, repo), id), nil, nil)
+}
+
+func (r *githubProvider) GetUser(name string) (*User, error) {
+	u := &githubUser{}
+	if err := r.client.do(http.MethodGet, "/users/"+url.PathEscape(name), nil, u); err != nil {
+		return nil, err
+	}
+	return &User{ID: u.ID, UserName: u.Login, Email: u.Email}, nil
+}
+
+// github users sign up themselves, use deploy keys or invite existing users
+func (r *githubProvider) CreateUser(opts CreateUserOptions) (*User, error) {
+	return nil, fmt.Errorf("%w: create user", ErrNotSupported)
+}
+
+func (r *githubProvider) DeleteUser(name string) error {
+	return fmt.Errorf("%w: delete user", ErrNotSupported)
+}
+
+func (r *githubProvider) AddCollaborator(owner, repo, user, permission string) error {
+	return r.client.do(http.MethodPut, fmt.Sprintf("%s/collaborators/%s", githubRepoPath(owner, repo), url.PathEscape(user)), &githubAddCollaborator{
+		Permission: githubPermission(permission),
+	}, nil)
+}
+
+// AddTeamRepo uses the slug of the team, the permission is set per repository
+func (r *githubProvider) AddTeamRepo(org, team, repo, permission string) error {
+	return r.client.do(http.MethodPut, fmt.Sprintf("/orgs/%s/teams/%s/repos/%s/%s", url.PathEscape(org), url.PathEscape(team), url.PathEscape(org), url.PathEscape(repo)), &githubAddCollaborator{
+		Permission: githubPermission(permission),
+	}, nil)
+}
+
+// ProtectBranch does not enforce the protection for admins, the user of the
+// git client can still push to the branch
+func (r *githubProvider) ProtectBranch(owner, repo string, opts BranchProtectionOptions) error {
+	protection := &githubBranchProtection{AllowForcePushes: opts.AllowForcePush}
+	if opts.RequiredApprovals > 0 {
+		protection.RequiredPullRequestReviews = &githubRequiredReviews{RequiredApprovingReviewCount: opts.RequiredApprovals}
+	}
+	return r.client.do(http.MethodPut, fmt.Sprintf("%s/branches/%s/protection", githubRepoPath(owner, repo), url.PathEscape(opts.Branch)), protection, nil)
+}
+
+func (r *githubProvider) ListWebhooks(owner, repo string) ([]*Webhook, error) {
+	hooks := []githubHook{}
+	if err := r.client.do(http.MethodGet, githubRepoPath(owner, repo)+"/hooks?per_page=100", nil, &hooks); err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, 0, len(hooks))
+	for i := range hooks {
+		webhooks = append(webhooks, toGitHubWebhook(&hooks[i]))
+	}
+	return webhooks, nil
+}
+
+func (r *githubProvider) CreateWebhook(owner, repo string, opts WebhookOptions) (*Webhook, error) {
+	hook := &githubHook{}
+	if err := r.client.do(http.MethodPost, githubRepoPath(owner, repo)+"/hooks", toGitHubHook(opts), hook); err != nil {
+		return nil, err
+	}
+	return toGitHubWebhook(hook), nil
+}
+
+func (r *githubProvider) EditWebhook(owner, repo string, id int64, opts WebhookOptions) error {
+	return r.client.do(http.MethodPatch, fmt.Sprintf("%s/hooks/%d", githubRepoPath(owner, repo), id), toGitHubHook(opts), nil)
+}
+
+func toGitHubHook(opts WebhookOptions) *githubHook {
+	return &githubHook{
+		Name:   "web",
+		Active: opts.Active,
+		Events: opts.Events,
+		Config: githubHookConfig{URL: opts.URL, ContentType: opts.ContentType, Secret: opts.Secret},
+	}
+}
+
+func toGitHubWebhook(h *githubHook) *Webhook {
+	return &Webhook{
+		ID:          h.ID,
+		URL:         h.Config.URL,
+		ContentType: h.Config.ContentType,
+		Events:      h.Events,
+		Active:      h.Active,
+	}
+}
+
+func githubPermission(permission string) string {
+	switch permission {
+	case PermissionWrite:
+		return "push"
+	case PermissionAdmin:
+		return "admin"
+	default:
+		return "pull"
+	}
+}
+
+func toGitHubRepository(repo *githubRepository) *Repository {
+	return &Repository{
+		ID:            repo.ID,
+		Owner:         repo.Owner.Login,
+		Name:          repo.Name,
+		Description:   repo.Description,
+		Private:       repo.Private,
+		DefaultBranch: repo.DefaultBranch,
+		CloneURL:      repo.CloneURL,
+		Archived:      repo.Archived,
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newFakeGitHub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 5, "login": "nephio"})
+	})
+	mux.HandleFunc("GET /api/v3/repos/nephio/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "mgmt" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 1, "name": "mgmt", "private": false, "default_branch": "main",
+			"clone_url": "https://github.example.com/nephio/mgmt.git",
+			"owner":     map[string]any{"login": "nephio"},
+		})
+	})
+	mux.HandleFunc("PATCH /api/v3/repos/nephio/mgmt", func(w http.ResponseWriter, r *http.Request) {
+		req := githubEditRepository{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode repo request: %v", err)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 1, "name": "mgmt", "private": ptr.Deref(req.Private, false),
+			"description": ptr.Deref(req.Description, ""),
+			"archived":    ptr.Deref(req.Archived, false),
+			"owner":       map[string]any{"login": "nephio"},
+		})
+	})
+	mux.HandleFunc("GET /api/v3/repos/nephio/{name}/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "mgmt" {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "Git Repository is empty."})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"sha": "c1", "commit": map[string]any{"message": "update", "committer": map[string]any{"date": "2025-01-02T03:04:05Z"}}},
+		})
+	})
+	mux.HandleFunc("POST /api/v3/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		req := githubCreateRepository{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode repo request: %v", err)
+		}
+		if !req.AutoInit {
+			t.Errorf("unexpected repo request: %+v", req)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 2, "name": req.Name, "owner": map[string]any{"login": "nephio"}})
+	})
+	mux.HandleFunc("DELETE /api/v3/repos/nephio/mgmt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v3/orgs/nephio-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 3, "name": "edge01", "owner": map[string]any{"login": "nephio-org"}})
+	})
+	mux.HandleFunc("PUT /api/v3/orgs/nephio-org/teams/platform/repos/nephio-org/edge01", func(w http.ResponseWriter, r *http.Request) {
+		req := githubAddCollaborator{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Permission != "admin" {
+			t.Errorf("unexpected team request: %+v, %v", req, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /api/v3/repos/nephio-org/edge01/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode protection request: %v", err)
+		}
+		// github rejects the request when the fields are missing
+		for _, k := range []string{"required_status_checks", "enforce_admins", "required_pull_request_reviews", "restrictions"} {
+			if _, ok := req[k]; !ok {
+				t.Errorf("protection request misses %s: %v", k, req)
+			}
+		}
+		if req["allow_force_pushes"] != false {
+			t.Errorf("unexpected protection request: %v", req)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{})
+	})
+	mux.HandleFunc("GET /api/v3/repos/nephio-org/edge01/hooks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{})
+	})
+	mux.HandleFunc("POST /api/v3/repos/nephio-org/edge01/hooks", func(w http.ResponseWriter, r *http.Request) {
+		req := githubHook{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name != "web" || req.Config.Secret != "s3cr3t" {
+			t.Errorf("unexpected hook request: %+v, %v", req, err)
+		}
+		req.ID = 11
+		writeJSON(w, http.StatusCreated, req)
+	})
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestGitHubProvider(t *testing.T) {
+	srv := newFakeGitHub(t)
+	defer srv.Close()
+
+	// a non github.com url is handled as github enterprise
+	p, err := NewGitHubProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{"password": []byte("secret")}})
+	if err != nil {
+		t.Fatalf("NewGitHubProvider() error = %v", err)
+	}
+
+	repo, err := p.GetRepo("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if repo.Owner != "nephio" || repo.CloneURL != "https://github.example.com/nephio/mgmt.git" {
+		t.Errorf("GetRepo() got = %+v", repo)
+	}
+	if _, err := p.GetRepo("nephio", "missing"); !IsNotFound(err) {
+		t.Errorf("GetRepo() error = %v, want not found", err)
+	}
+
+	repo, err = p.EditRepo("nephio", "mgmt", EditRepoOptions{Description: ptr.To("mgmt repo"), Private: ptr.To(true)})
+	if err != nil {
+		t.Fatalf("EditRepo() error = %v", err)
+	}
+	if repo.Description != "mgmt repo" || !repo.Private {
+		t.Errorf("EditRepo() got = %+v", repo)
+	}
+	repo, err = p.EditRepo("nephio", "mgmt", EditRepoOptions{Archived: ptr.To(true)})
+	if err != nil {
+		t.Fatalf("EditRepo() error = %v", err)
+	}
+	if !repo.Archived {
+		t.Errorf("EditRepo() got = %+v, want archived", repo)
+	}
+
+	commit, err := p.GetLatestCommit("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if want := (&Commit{SHA: "c1", Message: "update", Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}); !reflect.DeepEqual(commit, want) {
+		t.Errorf("GetLatestCommit() got = %+v, want %+v", commit, want)
+	}
+	if commit, err := p.GetLatestCommit("nephio", "empty"); err != nil || commit != nil {
+		t.Errorf("GetLatestCommit() got = %+v, %v, want no commit", commit, err)
+	}
+
+	if _, err := p.CreateRepo(CreateRepoOptions{Name: "edge01", AutoInit: true}); err != nil {
+		t.Errorf("CreateRepo() error = %v", err)
+	}
+	if err := p.DeleteRepo("nephio", "mgmt"); err != nil {
+		t.Errorf("DeleteRepo() error = %v", err)
+	}
+
+	if _, err := p.CreateAccessToken(CreateAccessTokenOptions{Name: "t1"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("CreateAccessToken() error = %v, want not supported", err)
+	}
+
+	repo, err = p.CreateRepo(CreateRepoOptions{Organization: "nephio-org", Name: "edge01", AutoInit: true})
+	if err != nil {
+		t.Fatalf("CreateRepo() error = %v", err)
+	}
+	if repo.Owner != "nephio-org" {
+		t.Errorf("CreateRepo() got = %+v, want owner nephio-org", repo)
+	}
+	if err := p.AddTeamRepo("nephio-org", "platform", "edge01", PermissionAdmin); err != nil {
+		t.Errorf("AddTeamRepo() error = %v", err)
+	}
+	if err := p.ProtectBranch("nephio-org", "edge01", BranchProtectionOptions{Branch: "main", RequiredApprovals: 1}); err != nil {
+		t.Errorf("ProtectBranch() error = %v", err)
+	}
+	hooks, err := p.ListWebhooks("nephio-org", "edge01")
+	if err != nil || len(hooks) != 0 {
+		t.Errorf("ListWebhooks() got = %v, %v, want no hooks", hooks, err)
+	}
+	hook, err := p.CreateWebhook("nephio-org", "edge01", WebhookOptions{URL: "http://hook", ContentType: "json", Secret: "s3cr3t", Events: []string{WebhookEventPush}, Active: true})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	if want := (&Webhook{ID: 11, URL: "http://hook", ContentType: "json", Events: []string{"push"}, Active: true}); !reflect.DeepEqual(hook, want) {
+		t.Errorf("CreateWebhook() got = %+v, want %+v", hook, want)
+	}
+}
+
+func TestGitHubBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com":        githubAPIURL,
+		"https://api.github.com/":   githubAPIURL,
+		"https://git.example.com/":  "https://git.example.com/api/v3",
+		"http://10.0.0.1:3000/ghe/": "http://10.0.0.1:3000/ghe/api/v3",
+	}
+	for in, want := range tests {
+		if got := githubBaseURL(in); got != want {
+			t.Errorf("githubBaseURL(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestGitHubCommitFiles(t *testing.T) {
+	calls := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 1, "login": "nephio"})
+	})
+	mux.HandleFunc("GET /api/v3/repos/nephio/edge01/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"object": map[string]any{"sha": "c1"}})
+	})
+	mux.HandleFunc("GET /api/v3/repos/nephio/edge01/git/commits/c1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"sha": "c1", "tree": map[string]any{"sha": "t1"}})
+	})
+	mux.HandleFunc("POST /api/v3/repos/nephio/edge01/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		req := githubBlob{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode blob request: %v", err)
+		}
+		calls = append(calls, "blob "+req.Content)
+		writeJSON(w, http.StatusCreated, map[string]any{"sha": "b" + strconv.Itoa(len(calls))})
+	})
+	mux.HandleFunc("POST /api/v3/repos/nephio/edge01/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		req := githubCreateTree{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode tree request: %v", err)
+		}
+		want := githubCreateTree{BaseTree: "t1", Tree: []githubTreeEntry{
+			{Path: "Kptfile", Mode: "100644", Type: "blob", SHA: "b1"},
+			{Path: "README.md", Mode: "100644", Type: "blob", SHA: "b2"},
+		}}
+		if !reflect.DeepEqual(req, want) {
+			t.Errorf("unexpected tree request: %+v, want %+v", req, want)
+		}
+		calls = append(calls, "tree")
+		writeJSON(w, http.StatusCreated, map[string]any{"sha": "t2"})
+	})
+	mux.HandleFunc("POST /api/v3/repos/nephio/edge01/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		req := githubCreateCommit{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode commit request: %v", err)
+		}
+		if req.Tree != "t2" || !reflect.DeepEqual(req.Parents, []string{"c1"}) {
+			t.Errorf("unexpected commit request: %+v", req)
+		}
+		calls = append(calls, "commit")
+		writeJSON(w, http.StatusCreated, map[string]any{"sha": "c2"})
+	})
+	mux.HandleFunc("PATCH /api/v3/repos/nephio/edge01/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		req := githubUpdateRef{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode ref request: %v", err)
+		}
+		calls = append(calls, "ref "+req.SHA)
+		writeJSON(w, http.StatusOK, map[string]any{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p, err := NewGitHubProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{"token": []byte("secret")}})
+	if err != nil {
+		t.Fatalf("NewGitHubProvider() error = %v", err)
+	}
+	if err := p.CommitFiles("nephio", "edge01", CommitFilesOptions{
+		Branch:  "main",
+		Message: "seed",
+		Files:   map[string][]byte{"README.md": []byte("# edge01"), "Kptfile": []byte("kind: Kptfile")},
+	}); err != nil {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+	want := []string{"blob a2luZDogS3B0ZmlsZQ==", "blob IyBlZGdlMDE=", "tree", "commit", "ref c2"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("CommitFiles() calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,636 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	gitlabVisibilityPrivate = "private"
+	gitlabVisibilityPublic  = "public"
+
+	// access levels of project members
+	gitlabAccessLevelReporter   = 20
+	gitlabAccessLevelDeveloper  = 30
+	gitlabAccessLevelMaintainer = 40
+
+	// gitlabPageSize is the maximum number of items per page of a list
+	gitlabPageSize = 100
+)
+
+// gitlabRepoScopes are the gitlab scopes of the neutral repo scope, api is
+// needed to manage the repositories of the user
+var gitlabRepoScopes = []string{"api", "read_repository", "write_repository"}
+
+type gitlabProvider struct {
+	client *restClient
+}
+
+// NewGitLabProvider returns a gitlab provider using the v4 rest api, the secret
+// needs a personal access token with the api scope in token (or password)
+func NewGitLabProvider(gitURL string, secret *corev1.Secret) (Provider, error) {
+	token, err := getSecretToken(secret)
+	if err != nil {
+		return nil, err
+	}
+	p := &gitlabProvider{
+		client: newRESTClient(strings.TrimSuffix(gitURL, "/")+"/api/v4", func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}),
+	}
+	// validate the credentials like the gitea client does when it is created
+	if _, err := p.GetMyUserInfo(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type gitlabUser struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type gitlabProject struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Description   string `json:"description"`
+	Visibility    string `json:"visibility"`
+	DefaultBranch string `json:"default_branch"`
+	HTTPURLToRepo string `json:"http_url_to_repo"`
+	Archived      bool   `json:"archived"`
+	Namespace     struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	SharedWithGroups []struct {
+		GroupID          int64 `json:"group_id"`
+		GroupAccessLevel int   `json:"group_access_level"`
+	} `json:"shared_with_groups"`
+}
+
+type gitlabCreateProject struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	Visibility           string `json:"visibility"`
+	InitializeWithReadme bool   `json:"initialize_with_readme"`
+	DefaultBranch        string `json:"default_branch,omitempty"`
+	NamespaceID          int64  `json:"namespace_id,omitempty"`
+}
+
+type gitlabGroup struct {
+	ID       int64  `json:"id"`
+	FullPath string `json:"full_path"`
+}
+
+type gitlabShareProject struct {
+	GroupID     int64 `json:"group_id"`
+	GroupAccess int   `json:"group_access"`
+}
+
+type gitlabProtectedBranch struct {
+	Name             string `json:"name"`
+	PushAccessLevel  int    `json:"push_access_level,omitempty"`
+	MergeAccessLevel int    `json:"merge_access_level,omitempty"`
+	AllowForcePush   bool   `json:"allow_force_push"`
+}
+
+type gitlabHook struct {
+	ID                  int64  `json:"id,omitempty"`
+	URL                 string `json:"url"`
+	Token               string `json:"token,omitempty"`
+	PushEvents          bool   `json:"push_events"`
+	MergeRequestsEvents bool   `json:"merge_requests_events"`
+	TagPushEvents       bool   `json:"tag_push_events"`
+}
+
+type gitlabEditProject struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Visibility  *string `json:"visibility,omitempty"`
+}
+
+type gitlabCommitInfo struct {
+	ID            string    `json:"id"`
+	Message       string    `json:"message"`
+	CommittedDate time.Time `json:"committed_date"`
+}
+
+type gitlabAccessToken struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Token   string   `json:"token"`
+	Scopes  []string `json:"scopes"`
+	Revoked bool     `json:"revoked"`
+	Active  bool     `json:"active"`
+}
+
+type gitlabCreateAccessToken struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+type gitlabDeployKey struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Key     string `json:"key"`
+	CanPush bool   `json:"can_push"`
+}
+
+type gitlabCreateUser struct {
+	UserName         string `json:"username"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	Password         string `json:"password"`
+	SkipConfirmation bool   `json:"skip_confirmation"`
+}
+
+type gitlabAddMember struct {
+	UserID      int64 `json:"user_id"`
+	AccessLevel int   `json:"access_level"`
+}
+
+// gitlabCommit creates a commit with multiple file actions on a branch
+type gitlabCommit struct {
+	Branch        string               `json:"branch"`
+	CommitMessage string               `json:"commit_message"`
+	Actions       []gitlabCommitAction `json:"actions"`
+}
+
+type gitlabCommitAction struct {
+	Action   string `json:"action"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// gitlabProjectPath returns the url encoded project path used as project id
+func gitlabProjectPath(owner, name string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+name)
+}
+
+// gitlabList gets all pages of a list, gitlab returns 20 items per page by
+// default and the next page in the X-Next-Page header
+func gitlabList[T any](c *restClient, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	items := []T{}
+	for page := "1"; page != ""; {
+		pageItems := []T{}
+		header, err := c.doWithHeader(http.MethodGet, fmt.Sprintf("%s%sper_page=%d&page=%s", path, sep, gitlabPageSize, page), nil, &pageItems)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, pageItems...)
+		page = header.Get("X-Next-Page")
+	}
+	return items, nil
+}
+
+func gitlabVisibility(private bool) string {
+	if private {
+		return gitlabVisibilityPrivate
+	}
+	return gitlabVisibilityPublic
+}
+
+func (r *gitlabProvider) GetMyUserInfo() (*User, error) {
+	u := &gitlabUser{}
+	if err := r.client.do(http.MethodGet, "/user", nil, u); err != nil {
+		return nil, err
+	}
+	return &User{ID: u.ID, UserName: u.UserName, Email: u.Email}, nil
+}
+
+func (r *gitlabProvider) GetRepo(owner, name string) (*Repository, error) {
+	p := &gitlabProject{}
+	if err := r.client.do(http.MethodGet, gitlabProjectPath(owner, name), nil, p); err != nil {
+		return nil, err
+	}
+	return toGitLabRepository(p), nil
+}
+
+// CreateRepo creates the project in the group of the organization, or in the
+// namespace of the user
+func (r *gitlabProvider) CreateRepo(opts CreateRepoOptions) (*Repository, error) {
+	create := &gitlabCreateProject{
+		Name:                 opts.Name,
+		Description:          opts.Description,
+		Visibility:           gitlabVisibility(opts.Private),
+		InitializeWithReadme: opts.AutoInit,
+		DefaultBranch:        opts.DefaultBranch,
+	}
+	if opts.Organization != "" {
+		g, err := r.getGroup(opts.Organization)
+		if err != nil {
+			return nil, err
+		}
+		create.NamespaceID = g.ID
+	}
+	p := &gitlabProject{}
+	if err := r.client.do(http.MethodPost, "/projects", create, p); err != nil {
+		return nil, err
+	}
+	return toGitLabRepository(p), nil
+}
+
+func (r *gitlabProvider) getGroup(path string) (*gitlabGroup, error) {
+	g := &gitlabGroup{}
+	if err := r.client.do(http.MethodGet, "/groups/"+url.PathEscape(path), nil, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *gitlabProvider) EditRepo(owner, name string, opts EditRepoOptions) (*Repository, error) {
+	edit := &gitlabEditProject{
+		Name:        opts.Name,
+		Description: opts.Description,
+	}
+	if opts.Private != nil {
+		visibility := gitlabVisibility(*opts.Private)
+		edit.Visibility = &visibility
+	}
+	p := &gitlabProject{}
+	if err := r.client.do(http.MethodPut, gitlabProjectPath(owner, name), edit, p); err != nil {
+		return nil, err
+	}
+	// gitlab archives projects with a separate api
+	if opts.Archived != nil && *opts.Archived != p.Archived {
+		action := "/unarchive"
+		if *opts.Archived {
+			action = "/archive"
+		}
+		if err := r.client.do(http.MethodPost, gitlabProjectPath(owner, name)+action, nil, p); err != nil {
+			return nil, err
+		}
+	}
+	return toGitLabRepository(p), nil
+}
+
+func (r *gitlabProvider) DeleteRepo(owner, name string) error {
+	return r.client.do(http.MethodDelete, gitlabProjectPath(owner, name), nil, nil)
+}
+
+// custom project templates are a gitlab premium feature, seed the repository
+// with CommitFiles instead
+func (r *gitlabProvider) CreateRepoFromTemplate(templateOwner, templateName string, opts CreateRepoFromTemplateOptions) (*Repository, error) {
+	return nil, fmt.Errorf("%w: create repo from template", ErrNotSupported)
+}
+
+func (r *gitlabProvider) CommitFiles(owner, repo string, opts CommitFilesOptions) error {
+	paths := make([]string, 0, len(opts.Files))
+	for path := range opts.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	commit := &gitlabCommit{Branch: opts.Branch, CommitMessage: opts.Message}
+	for _, path := range paths {
+		action := "create"
+		// existing files are updated
+		err := r.client.do(http.MethodHead, fmt.Sprintf("%s/repository/files/%s?ref=%s", gitlabProjectPath(owner, repo), url.PathEscape(path), url.QueryEscape(opts.Branch)), nil, nil)
+		if err == nil {
+			action = "update"
+		} else if !IsNotFound(err) {
+			return err
+		}
+		commit.Actions = append(commit.Actions, gitlabCommitAction{
+			Action:   action,
+			FilePath: path,
+			Content:  base64.StdEncoding.EncodeToString(opts.Files[path]),
+			Encoding: "base64",
+		})
+	}
+	return r.client.do(http.MethodPost, gitlabProjectPath(owner, repo)+"/repository/commits", commit, nil)
+}
+
+func (r *gitlabProvider) GetLatestCommit(owner, repo string) (*Commit, error) {
+	commits := []gitlabCommitInfo{}
+	if err := r.client.do(http.MethodGet, gitlabProjectPath(owner, repo)+"/repository/commits?per_page=1", nil, &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return &Commit{
+		SHA:     commits[0].ID,
+		Message: commits[0].Message,
+		Created: commits[0].CommittedDate,
+	}, nil
+}
+
+// ListAccessTokens returns the active personal access tokens of the user,
+// gitlab keeps revoked tokens in the list
+func (r *gitlabProvider) ListAccessTokens() ([]*AccessToken, error) {
+	u, err := r.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := gitlabList[*gitlabAccessToken](r.client, fmt.Sprintf("/personal_access_tokens?user_id=%d&state=active", u.ID))
+	if err != nil {
+		return nil, err
+	}
+	accessTokens := make([]*AccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Revoked {
+			continue
+		}
+		accessTokens = append(accessTokens, toGitLabAccessToken(t))
+	}
+	return accessTokens, nil
+}
+
+// CreateAccessToken creates a personal access token for the user, this needs
+// an administrator token on gitlab
+func (r *gitlabProvider) CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error) {
+	u, err := r.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+	scopes := []string{}
+	for _, s := range opts.Scopes {
+		switch s {
+		case AccessTokenScopeRepo:
+			scopes = append(scopes, gitlabRepoScopes...)
+		case AccessTokenScopeRepoRead:
+			scopes = append(scopes, "read_repository")
+		default:
+			scopes = append(scopes, s)
+		}
+	}
+	req := &gitlabCreateAccessToken{
+		Name:   opts.Name,
+		Scopes: scopes,
+	}
+	if !opts.ExpiresAt.IsZero() {
+		// gitlab expires tokens at the start of the given day, round up so the
+		// token does not expire before the requested time
+		req.ExpiresAt = opts.ExpiresAt.UTC().Add(24 * time.Hour).Format(time.DateOnly)
+	}
+	t := &gitlabAccessToken{}
+	if err := r.client.do(http.MethodPost, fmt.Sprintf("/users/%d/personal_access_tokens", u.ID), req, t); err != nil {
+		return nil, err
+	}
+	return toGitLabAccessToken(t), nil
+}
+
+// DeleteAccessToken revokes the tokens with the given name, gitlab identifies
+// the tokens by id
+func (r *gitlabProvider) DeleteAccessToken(name string) error {
+	tokens, err := r.ListAccessTokens()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, t := range tokens {
+		if t.Name != name {
+			continue
+		}
+		found = true
+		if err := r.client.do(http.MethodDelete, fmt.Sprintf("/personal_access_tokens/%d", t.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: access token %s", ErrNotFound, name)
+	}
+	return nil
+}
+
+func (r *gitlabProvider) ListDeployKeys(owner, repo string) ([]*DeployKey, error) {
+	keys, err := gitlabList[*gitlabDeployKey](r.client, gitlabProjectPath(owner, repo)+"/deploy_keys")
+	if err != nil {
+		return nil, err
+	}
+	deployKeys := make([]*DeployKey, 0, len(keys))
+	for _, k := range keys {
+		deployKeys = append(deployKeys, toGitLabDeployKey(k))
+	}
+	return deployKeys, nil
+}
+
+func (r *gitlabProvider) CreateDeployKey(owner, repo string, opts CreateDeployKeyOptions) (*DeployKey, error) {
+	k := &gitlabDeployKey{}
+	if err := r.client.do(http.MethodPost, gitlabProjectPath(owner, repo)+"/deploy_keys", &gitlabDeployKey{
+		Title:   opts.Title,
+		Key:     opts.Key,
+		CanPush: !opts.ReadOnly,
+	}, k); err != nil {
+		return nil, err
+	}
+	return toGitLabDeployKey(k), nil
+}
+
+func (r *gitlabProvider) DeleteDeployKey(owner, repo string, id int64) error {
+	return r.client.do(http.MethodDelete, fmt.Sprintf("%s/deploy_keys/%d", gitlabProjectPath(owner, repo), id), nil, nil)
+}
+
+// GetUser looks up the user by username, gitlab identifies the users by id
+func (r *gitlabProvider) GetUser(name string) (*User, error) {
+	users := []*gitlabUser{}
+	if err := r.client.do(http.MethodGet, "/users?username="+url.QueryEscape(name), nil, &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: user %s", ErrNotFound, name)
+	}
+	return &User{ID: users[0].ID, UserName: users[0].UserName, Email: users[0].Email}, nil
+}
+
+func (r *gitlabProvider) CreateUser(opts CreateUserOptions) (*User, error) {
+	u := &gitlabUser{}
+	if err := r.client.do(http.MethodPost, "/users", &gitlabCreateUser{
+		UserName:         opts.UserName,
+		Name:             opts.UserName,
+		Email:            opts.Email,
+		Password:         opts.Password,
+		SkipConfirmation: true,
+	}, u); err != nil {
+		return nil, err
+	}
+	return &User{ID: u.ID, UserName: u.UserName, Email: u.Email}, nil
+}
+
+func (r *gitlabProvider) DeleteUser(name string) error {
+	u, err := r.GetUser(name)
+	if err != nil {
+		return err
+	}
+	return r.client.do(http.MethodDelete, fmt.Sprintf("/users/%d", u.ID), nil, nil)
+}
+
+func (r *gitlabProvider) AddCollaborator(owner, repo, user, permission string) error {
+	u, err := r.GetUser(user)
+	if err != nil {
+		return err
+	}
+	return r.client.do(http.MethodPost, gitlabProjectPath(owner, repo)+"/members", &gitlabAddMember{
+		UserID:      u.ID,
+		AccessLevel: gitlabAccessLevel(permission),
+	}, nil)
+}
+
+// AddTeamRepo shares the project with the subgroup <org>/<team>, a share with
+// another access level is replaced
+func (r *gitlabProvider) AddTeamRepo(org, team, repo, permission string) error {
+	g, err := r.getGroup(org + "/" + team)
+	if err != nil {
+		return err
+	}
+	accessLevel := gitlabAccessLevel(permission)
+	p := &gitlabProject{}
+	if err := r.client.do(http.MethodGet, gitlabProjectPath(org, repo), nil, p); err != nil {
+		return err
+	}
+	for _, share := range p.SharedWithGroups {
+		if share.GroupID != g.ID {
+			continue
+		}
+		if share.GroupAccessLevel == accessLevel {
+			return nil
+		}
+		if err := r.client.do(http.MethodDelete, fmt.Sprintf("%s/share/%d", gitlabProjectPath(org, repo), g.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+	return r.client.do(http.MethodPost, gitlabProjectPath(org, repo)+"/share", &gitlabShareProject{
+		GroupID:     g.ID,
+		GroupAccess: accessLevel,
+	}, nil)
+}
+
+// ProtectBranch allows developers to push and merge so porch can push to the
+// branch. Required approvals are a gitlab premium feature and are not set.
+func (r *gitlabProvider) ProtectBranch(owner, repo string, opts BranchProtectionOptions) error {
+	path := fmt.Sprintf("%s/protected_branches/%s", gitlabProjectPath(owner, repo), url.PathEscape(opts.Branch))
+	if err := r.client.do(http.MethodGet, path, nil, &gitlabProtectedBranch{}); err != nil {
+		if !IsNotFound(err) {
+			return err
+		}
+		return r.client.do(http.MethodPost, gitlabProjectPath(owner, repo)+"/protected_branches", &gitlabProtectedBranch{
+			Name:             opts.Branch,
+			PushAccessLevel:  gitlabAccessLevelDeveloper,
+			MergeAccessLevel: gitlabAccessLevelDeveloper,
+			AllowForcePush:   opts.AllowForcePush,
+		}, nil)
+	}
+	return r.client.do(http.MethodPatch, path, &gitlabProtectedBranch{AllowForcePush: opts.AllowForcePush}, nil)
+}
+
+func (r *gitlabProvider) ListWebhooks(owner, repo string) ([]*Webhook, error) {
+	hooks := []gitlabHook{}
+	if err := r.client.do(http.MethodGet, gitlabProjectPath(owner, repo)+"/hooks?per_page=100", nil, &hooks); err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, 0, len(hooks))
+	for i := range hooks {
+		webhooks = append(webhooks, toGitLabWebhook(&hooks[i]))
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook ignores the content type and active flag, gitlab hooks always
+// send json and are always active
+func (r *gitlabProvider) CreateWebhook(owner, repo string, opts WebhookOptions) (*Webhook, error) {
+	hook := &gitlabHook{}
+	if err := r.client.do(http.MethodPost, gitlabProjectPath(owner, repo)+"/hooks", toGitLabHook(opts), hook); err != nil {
+		return nil, err
+	}
+	return toGitLabWebhook(hook), nil
+}
+
+func (r *gitlabProvider) EditWebhook(owner, repo string, id int64, opts WebhookOptions) error {
+	return r.client.do(http.MethodPut, fmt.Sprintf("%s/hooks/%d", gitlabProjectPath(owner, repo), id), toGitLabHook(opts), nil)
+}
+
+func toGitLabHook(opts WebhookOptions) *gitlabHook {
+	hook := &gitlabHook{URL: opts.URL, Token: opts.Secret}
+	for _, e := range opts.Events {
+		switch e {
+		case WebhookEventPush:
+			hook.PushEvents = true
+		case WebhookEventPullRequest:
+			hook.MergeRequestsEvents = true
+		case "tag_push":
+			hook.TagPushEvents = true
+		}
+	}
+	return hook
+}
+
+func toGitLabWebhook(h *gitlabHook) *Webhook {
+	w := &Webhook{ID: h.ID, URL: h.URL, ContentType: "json", Active: true, Events: []string{}}
+	if h.PushEvents {
+		w.Events = append(w.Events, WebhookEventPush)
+	}
+	if h.MergeRequestsEvents {
+		w.Events = append(w.Events, WebhookEventPullRequest)
+	}
+	if h.TagPushEvents {
+		w.Events = append(w.Events, "tag_push")
+	}
+	return w
+}
+
+func gitlabAccessLevel(permission string) int {
+	switch permission {
+	case PermissionWrite:
+		return gitlabAccessLevelDeveloper
+	case PermissionAdmin:
+		return gitlabAccessLevelMaintainer
+	default:
+		return gitlabAccessLevelReporter
+	}
+}
+
+func toGitLabRepository(p *gitlabProject) *Repository {
+	name := p.Path
+	if name == "" {
+		name = p.Name
+	}
+	return &Repository{
+		ID:            p.ID,
+		Owner:         p.Namespace.FullPath,
+		Name:          name,
+		Description:   p.Description,
+		Private:       p.Visibility == gitlabVisibilityPrivate,
+		DefaultBranch: p.DefaultBranch,
+		CloneURL:      p.HTTPURLToRepo,
+		Archived:      p.Archived,
+	}
+}
+
+func toGitLabAccessToken(t *gitlabAccessToken) *AccessToken {
+	return &AccessToken{ID: t.ID, Name: t.Name, Token: t.Token, Scopes: t.Scopes}
+}
+
+func toGitLabDeployKey(k *gitlabDeployKey) *DeployKey {
+	return &DeployKey{ID: k.ID, Title: k.Title, Key: k.Key, ReadOnly: !k.CanPush}
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newFakeGitLab(t *testing.T) (*httptest.Server, *[]string) {
+	deleted := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 7, "username": "nephio"})
+	})
+	// the project id is the url encoded path of the project
+	mux.HandleFunc("GET /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "nephio/mgmt" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Project Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 1, "name": "mgmt", "path": "mgmt", "visibility": "private",
+			"default_branch":   "main",
+			"http_url_to_repo": "http://gitlab/nephio/mgmt.git",
+			"namespace":        map[string]any{"full_path": "nephio"},
+		})
+	})
+	mux.HandleFunc("POST /api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabCreateProject{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode project request: %v", err)
+		}
+		if !req.InitializeWithReadme || req.Visibility != gitlabVisibilityPublic {
+			t.Errorf("unexpected project request: %+v", req)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{
+			"id": 2, "name": req.Name, "path": req.Name, "visibility": req.Visibility,
+			"namespace": map[string]any{"full_path": "nephio"},
+		})
+	})
+	mux.HandleFunc("PUT /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 1, "name": "mgmt", "path": "mgmt", "visibility": "private",
+			"namespace": map[string]any{"full_path": "nephio"},
+		})
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusCreated, map[string]any{
+			"id": 1, "name": "mgmt", "path": "mgmt", "visibility": "private", "archived": true,
+			"namespace": map[string]any{"full_path": "nephio"},
+		})
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "nephio/mgmt" {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"id": "c1", "message": "update", "committed_date": "2025-01-02T03:04:05Z"},
+		})
+	})
+	mux.HandleFunc("GET /api/v4/personal_access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id") != "7" {
+			t.Errorf("unexpected user_id %s", r.URL.Query().Get("user_id"))
+		}
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected per_page %s", r.URL.Query().Get("per_page"))
+		}
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, http.StatusOK, []map[string]any{{"id": 13, "name": "t1", "active": true}})
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"id": 10, "name": "t1", "active": true},
+			{"id": 11, "name": "t1", "revoked": true},
+		})
+	})
+	mux.HandleFunc("POST /api/v4/users/7/personal_access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabCreateAccessToken{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode token request: %v", err)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 12, "name": req.Name, "token": "glpat-abc", "scopes": req.Scopes})
+	})
+	mux.HandleFunc("DELETE /api/v4/personal_access_tokens/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}/deploy_keys", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected per_page %s", r.URL.Query().Get("per_page"))
+		}
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, http.StatusOK, []map[string]any{{"id": 22, "title": "edge01-configsync"}})
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		writeJSON(w, http.StatusOK, []map[string]any{{"id": 21, "title": "mgmt-configsync"}})
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/deploy_keys", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabDeployKey{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode deploy key request: %v", err)
+		}
+		if r.PathValue("id") != "nephio/mgmt" || req.CanPush {
+			t.Errorf("unexpected deploy key request for %s: %+v", r.PathValue("id"), req)
+		}
+		req.ID = 20
+		writeJSON(w, http.StatusCreated, req)
+	})
+	mux.HandleFunc("GET /api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("username") != "mgmt-porch" {
+			writeJSON(w, http.StatusOK, []map[string]any{})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{{"id": 8, "username": "mgmt-porch"}})
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/members", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabAddMember{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode member request: %v", err)
+		}
+		if req.UserID != 8 || req.AccessLevel != gitlabAccessLevelReporter {
+			t.Errorf("unexpected member request: %+v", req)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 8})
+	})
+	// the README exists in the initialized project
+	mux.HandleFunc("HEAD /api/v4/projects/{id}/repository/files/{path}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "README.md" || r.URL.Query().Get("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabCommit{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode commit request: %v", err)
+		}
+		want := gitlabCommit{Branch: "main", CommitMessage: "seed", Actions: []gitlabCommitAction{
+			{Action: "update", FilePath: "README.md", Content: "IyBlZGdlMDE=", Encoding: "base64"},
+			{Action: "create", FilePath: "rootsync/Kptfile", Content: "a2luZDogS3B0ZmlsZQ==", Encoding: "base64"},
+		}}
+		if r.PathValue("id") != "nephio/mgmt" || !reflect.DeepEqual(req, want) {
+			t.Errorf("unexpected commit request for %s: %+v, want %+v", r.PathValue("id"), req, want)
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"id": "c1"})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return srv, &deleted
+}
+
+func TestGitLabProvider(t *testing.T) {
+	srv, deleted := newFakeGitLab(t)
+	defer srv.Close()
+
+	if _, err := NewGitLabProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{"token": []byte("wrong")}}); err == nil {
+		t.Errorf("NewGitLabProvider() expected error for invalid token")
+	}
+
+	p, err := NewGitLabProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{"token": []byte("secret")}})
+	if err != nil {
+		t.Fatalf("NewGitLabProvider() error = %v", err)
+	}
+
+	repo, err := p.GetRepo("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	want := &Repository{ID: 1, Owner: "nephio", Name: "mgmt", Private: true, DefaultBranch: "main", CloneURL: "http://gitlab/nephio/mgmt.git"}
+	if !reflect.DeepEqual(repo, want) {
+		t.Errorf("GetRepo() got = %+v, want %+v", repo, want)
+	}
+	if _, err := p.GetRepo("nephio", "missing"); !IsNotFound(err) {
+		t.Errorf("GetRepo() error = %v, want not found", err)
+	}
+
+	repo, err = p.CreateRepo(CreateRepoOptions{Name: "edge01", AutoInit: true})
+	if err != nil {
+		t.Fatalf("CreateRepo() error = %v", err)
+	}
+	if repo.Name != "edge01" || repo.Private {
+		t.Errorf("CreateRepo() got = %+v", repo)
+	}
+
+	tokens, err := p.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() error = %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != 10 || tokens[1].ID != 13 {
+		t.Errorf("ListAccessTokens() got = %+v, want the active tokens of all pages", tokens)
+	}
+
+	token, err := p.CreateAccessToken(CreateAccessTokenOptions{Name: "t2", Scopes: []string{AccessTokenScopeRepo}})
+	if err != nil {
+		t.Fatalf("CreateAccessToken() error = %v", err)
+	}
+	if token.Token != "glpat-abc" || !reflect.DeepEqual(token.Scopes, gitlabRepoScopes) {
+		t.Errorf("CreateAccessToken() got = %+v", token)
+	}
+
+	if err := p.DeleteAccessToken("t1"); err != nil {
+		t.Fatalf("DeleteAccessToken() error = %v", err)
+	}
+	if !reflect.DeepEqual(*deleted, []string{"10", "13"}) {
+		t.Errorf("DeleteAccessToken() deleted = %v, want [10 13]", *deleted)
+	}
+	if err := p.DeleteAccessToken("unknown"); !IsNotFound(err) {
+		t.Errorf("DeleteAccessToken() error = %v, want not found", err)
+	}
+
+	key, err := p.CreateDeployKey("nephio", "mgmt", CreateDeployKeyOptions{Title: "mgmt-configsync", Key: "ssh-ed25519 AAAA", ReadOnly: true})
+	if err != nil {
+		t.Fatalf("CreateDeployKey() error = %v", err)
+	}
+	if want := (&DeployKey{ID: 20, Title: "mgmt-configsync", Key: "ssh-ed25519 AAAA", ReadOnly: true}); !reflect.DeepEqual(key, want) {
+		t.Errorf("CreateDeployKey() got = %+v, want %+v", key, want)
+	}
+	keys, err := p.ListDeployKeys("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("ListDeployKeys() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != 21 || keys[1].ID != 22 {
+		t.Errorf("ListDeployKeys() got = %+v, want the keys of all pages", keys)
+	}
+
+	if _, err := p.GetUser("unknown"); !IsNotFound(err) {
+		t.Errorf("GetUser() error = %v, want not found", err)
+	}
+	if err := p.AddCollaborator("nephio", "mgmt", "mgmt-porch", PermissionRead); err != nil {
+		t.Errorf("AddCollaborator() error = %v", err)
+	}
+
+	if err := p.CommitFiles("nephio", "mgmt", CommitFilesOptions{
+		Branch:  "main",
+		Message: "seed",
+		Files:   map[string][]byte{"README.md": []byte("# edge01"), "rootsync/Kptfile": []byte("kind: Kptfile")},
+	}); err != nil {
+		t.Errorf("CommitFiles() error = %v", err)
+	}
+	if _, err := p.CreateRepoFromTemplate("nephio", "template", CreateRepoFromTemplateOptions{Name: "edge01"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("CreateRepoFromTemplate() error = %v, want not supported", err)
+	}
+
+	repo, err = p.EditRepo("nephio", "mgmt", EditRepoOptions{Archived: ptr.To(true)})
+	if err != nil {
+		t.Fatalf("EditRepo() error = %v", err)
+	}
+	if !repo.Archived {
+		t.Errorf("EditRepo() got = %+v, want archived", repo)
+	}
+	commit, err := p.GetLatestCommit("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if want := (&Commit{SHA: "c1", Message: "update", Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}); !reflect.DeepEqual(commit, want) {
+		t.Errorf("GetLatestCommit() got = %+v, want %+v", commit, want)
+	}
+	if commit, err := p.GetLatestCommit("nephio", "empty"); err != nil || commit != nil {
+		t.Errorf("GetLatestCommit() got = %+v, %v, want no commit", commit, err)
+	}
+}
+
+func TestGitLabRepositorySettings(t *testing.T) {
+	got := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 7, "username": "nephio"})
+	})
+	mux.HandleFunc("GET /api/v4/groups/{path}", func(w http.ResponseWriter, r *http.Request) {
+		ids := map[string]int{"nephio-org": 11, "nephio-org/platform": 12}
+		id, ok := ids[r.PathValue("path")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Group Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": id, "full_path": r.PathValue("path")})
+	})
+	mux.HandleFunc("POST /api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabCreateProject{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode project request: %v", err)
+		}
+		got = append(got, fmt.Sprintf("create project in %d", req.NamespaceID))
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 2, "name": req.Name, "path": req.Name, "namespace": map[string]any{"full_path": "nephio-org"}})
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 2, "name": "edge01", "path": "edge01",
+			"shared_with_groups": []map[string]any{{"group_id": 12, "group_access_level": gitlabAccessLevelReporter}},
+		})
+	})
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/share/{group}", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, "unshare "+r.PathValue("group"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/share", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabShareProject{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode share request: %v", err)
+		}
+		got = append(got, fmt.Sprintf("share %d with %d", req.GroupID, req.GroupAccess))
+		writeJSON(w, http.StatusCreated, map[string]any{})
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}/protected_branches/{name}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+	})
+	mux.HandleFunc("POST /api/v4/projects/{id}/protected_branches", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabProtectedBranch{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode protected branch request: %v", err)
+		}
+		got = append(got, fmt.Sprintf("protect %s force push %t", req.Name, req.AllowForcePush))
+		writeJSON(w, http.StatusCreated, req)
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}/hooks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{{"id": 13, "url": "http://hook", "push_events": true}})
+	})
+	mux.HandleFunc("PUT /api/v4/projects/{id}/hooks/{hook}", func(w http.ResponseWriter, r *http.Request) {
+		req := gitlabHook{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode hook request: %v", err)
+		}
+		got = append(got, fmt.Sprintf("edit hook %s push %t merge requests %t", r.PathValue("hook"), req.PushEvents, req.MergeRequestsEvents))
+		writeJSON(w, http.StatusOK, req)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p, err := NewGitLabProvider(srv.URL, &corev1.Secret{Data: map[string][]byte{"token": []byte("secret")}})
+	if err != nil {
+		t.Fatalf("NewGitLabProvider() error = %v", err)
+	}
+
+	if _, err := p.CreateRepo(CreateRepoOptions{Organization: "nephio-org", Name: "edge01"}); err != nil {
+		t.Fatalf("CreateRepo() error = %v", err)
+	}
+	if err := p.AddTeamRepo("nephio-org", "platform", "edge01", PermissionWrite); err != nil {
+		t.Errorf("AddTeamRepo() error = %v", err)
+	}
+	if err := p.AddTeamRepo("nephio-org", "unknown", "edge01", PermissionWrite); !IsNotFound(err) {
+		t.Errorf("AddTeamRepo() error = %v, want not found", err)
+	}
+	if err := p.ProtectBranch("nephio-org", "edge01", BranchProtectionOptions{Branch: "main", RequiredApprovals: 1}); err != nil {
+		t.Errorf("ProtectBranch() error = %v", err)
+	}
+	hooks, err := p.ListWebhooks("nephio-org", "edge01")
+	if err != nil {
+		t.Fatalf("ListWebhooks() error = %v", err)
+	}
+	if want := []*Webhook{{ID: 13, URL: "http://hook", ContentType: "json", Events: []string{"push"}, Active: true}}; !reflect.DeepEqual(hooks, want) {
+		t.Errorf("ListWebhooks() got = %+v, want %+v", hooks, want)
+	}
+	if err := p.EditWebhook("nephio-org", "edge01", 13, WebhookOptions{URL: "http://hook", Events: []string{WebhookEventPush, WebhookEventPullRequest}}); err != nil {
+		t.Errorf("EditWebhook() error = %v", err)
+	}
+
+	want := []string{
+		"create project in 11",
+		"unshare 12",
+		"share 12 with 30",
+		"protect main force push false",
+		"edit hook 13 push true merge requests true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requests got = %v, want %v", got, want)
+	}
+}
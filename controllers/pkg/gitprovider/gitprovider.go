@@ -0,0 +1,252 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	ProviderGitea  = "gitea"
+	ProviderGitLab = "gitlab"
+	ProviderGitHub = "github"
+
+	// AccessTokenScopeRepo is the provider neutral scope that gives read/write
+	// access to the repositories of the user, each provider maps it to its own
+	// scopes. Other scopes are passed as is to the provider.
+	AccessTokenScopeRepo = "repo"
+	// AccessTokenScopeRepoRead is the provider neutral scope that gives read
+	// access to the repositories of the user
+	AccessTokenScopeRepoRead = "repo:read"
+
+	// permissions of a collaborator or team on a repository
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+
+	// provider neutral webhook events, each provider maps them to its own
+	// events. Other events are passed as is to the provider.
+	WebhookEventPush        = "push"
+	WebhookEventPullRequest = "pull_request"
+)
+
+var (
+	// ErrNotFound is returned when the requested object does not exist on the
+	// git server
+	ErrNotFound = errors.New("not found")
+	// ErrNotSupported is returned when the git server has no api for the
+	// requested operation
+	ErrNotSupported = errors.New("not supported by the git provider")
+)
+
+// IsNotFound returns true if the error indicates the object does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// Provider is the provider neutral api of a git server used by the repository
+// and token reconcilers
+type Provider interface {
+	GetMyUserInfo() (*User, error)
+	GetRepo(owner, name string) (*Repository, error)
+	CreateRepo(opts CreateRepoOptions) (*Repository, error)
+	EditRepo(owner, name string, opts EditRepoOptions) (*Repository, error)
+	DeleteRepo(owner, name string) error
+	// CreateRepoFromTemplate creates a repository of the user with the content
+	// of the template repository
+	CreateRepoFromTemplate(templateOwner, templateName string, opts CreateRepoFromTemplateOptions) (*Repository, error)
+	// CommitFiles creates or updates the files in a single commit
+	CommitFiles(owner, repo string, opts CommitFilesOptions) error
+	// GetLatestCommit returns the latest commit of the default branch, nil
+	// when the repository has no commits
+	GetLatestCommit(owner, repo string) (*Commit, error)
+	ListAccessTokens() ([]*AccessToken, error)
+	CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error)
+	DeleteAccessToken(name string) error
+	// deploy keys give ssh access to a single repository
+	ListDeployKeys(owner, repo string) ([]*DeployKey, error)
+	CreateDeployKey(owner, repo string, opts CreateDeployKeyOptions) (*DeployKey, error)
+	DeleteDeployKey(owner, repo string, id int64) error
+	// users are managed with the admin api of the git server
+	GetUser(name string) (*User, error)
+	CreateUser(opts CreateUserOptions) (*User, error)
+	DeleteUser(name string) error
+	AddCollaborator(owner, repo, user, permission string) error
+	// AddTeamRepo gives the team of the organization access to the repository
+	// of the organization
+	AddTeamRepo(org, team, repo, permission string) error
+	// ProtectBranch creates or updates the protection rule of the branch
+	ProtectBranch(owner, repo string, opts BranchProtectionOptions) error
+	ListWebhooks(owner, repo string) ([]*Webhook, error)
+	CreateWebhook(owner, repo string, opts WebhookOptions) (*Webhook, error)
+	EditWebhook(owner, repo string, id int64, opts WebhookOptions) error
+}
+
+type User struct {
+	ID       int64
+	UserName string
+	Email    string
+}
+
+type Repository struct {
+	ID            int64
+	Owner         string
+	Name          string
+	Description   string
+	Private       bool
+	DefaultBranch string
+	CloneURL      string
+	// Archived repositories are read-only
+	Archived bool
+}
+
+type CreateRepoOptions struct {
+	// Organization that owns the repository, the authenticated user when empty
+	Organization  string
+	Name          string
+	Description   string
+	Private       bool
+	IssueLabels   string
+	Gitignores    string
+	License       string
+	Readme        string
+	DefaultBranch string
+	TrustModel    string
+	// AutoInit initializes the repository with a first commit
+	AutoInit bool
+}
+
+type CreateRepoFromTemplateOptions struct {
+	// Owner of the new repository
+	Owner       string
+	Name        string
+	Description string
+	Private     bool
+}
+
+type CommitFilesOptions struct {
+	// Branch to commit to, it must exist
+	Branch  string
+	Message string
+	// Files maps the path in the repository to the content of the file
+	Files map[string][]byte
+}
+
+// EditRepoOptions holds the repository fields to update, nil fields are not
+// changed
+type EditRepoOptions struct {
+	Name        *string
+	Description *string
+	Private     *bool
+	Archived    *bool
+}
+
+type Commit struct {
+	SHA     string
+	Message string
+	// Created is the commit date
+	Created time.Time
+}
+
+type AccessToken struct {
+	ID     int64
+	Name   string
+	Token  string
+	Scopes []string
+}
+
+type CreateAccessTokenOptions struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is the expiry of the token, the zero time means no expiry.
+	// Providers without token expiry ignore it.
+	ExpiresAt time.Time
+}
+
+type DeployKey struct {
+	ID       int64
+	Title    string
+	Key      string
+	ReadOnly bool
+}
+
+type CreateDeployKeyOptions struct {
+	Title string
+	// Key is the public key in authorized_keys format
+	Key      string
+	ReadOnly bool
+}
+
+type BranchProtectionOptions struct {
+	Branch string
+	// RequiredApprovals is the number of approving reviews to merge a pull
+	// request, 0 requires no reviews
+	RequiredApprovals int
+	AllowForcePush    bool
+}
+
+type Webhook struct {
+	ID          int64
+	URL         string
+	ContentType string
+	Events      []string
+	Active      bool
+}
+
+type WebhookOptions struct {
+	URL string
+	// ContentType of the payload, json or form
+	ContentType string
+	// Secret signs the payload, it cannot be read back from the git server
+	Secret string
+	Events []string
+	Active bool
+}
+
+type CreateUserOptions struct {
+	UserName string
+	Email    string
+	Password string
+}
+
+// providers holds the constructors of the supported git providers
+var providers = map[string]func(url string, secret *corev1.Secret) (Provider, error){
+	ProviderGitea:  NewGiteaProvider,
+	ProviderGitLab: NewGitLabProvider,
+	ProviderGitHub: NewGitHubProvider,
+}
+
+// New returns a provider of the given kind that connects to the git server at
+// the url with the credentials of the secret
+func New(kind, url string, secret *corev1.Secret) (Provider, error) {
+	newProvider, ok := providers[strings.ToLower(kind)]
+	if !ok {
+		kinds := make([]string, 0, len(providers))
+		for k := range providers {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return nil, fmt.Errorf("unsupported git provider %q, supported: %s", kind, strings.Join(kinds, ", "))
+	}
+	return newProvider(url, secret)
+}
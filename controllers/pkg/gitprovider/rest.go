@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultRESTTimeout = 30 * time.Second
+
+// restClient is a minimal json rest client used by the providers that have
+// no sdk in this module
+type restClient struct {
+	baseURL string
+	client  *http.Client
+	// auth sets the authentication headers of the request
+	auth func(req *http.Request)
+}
+
+func newRESTClient(baseURL string, auth func(req *http.Request)) *restClient {
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: defaultRESTTimeout},
+		auth:    auth,
+	}
+}
+
+// do sends the request with the json encoded body and decodes the json
+// response in out, a 404 response returns ErrNotFound
+func (r *restClient) do(method, path string, body, out any) error {
+	_, err := r.doWithHeader(method, path, body, out)
+	return err
+}
+
+// doWithHeader is do returning the response headers, e.g. for pagination
+func (r *restClient) doWithHeader(method, path string, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, r.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	r.auth(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotFound, method, path)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, &statusError{method: method, path: path, statusCode: resp.StatusCode, body: strings.TrimSpace(string(data))}
+	}
+	if out == nil || len(data) == 0 {
+		return resp.Header, nil
+	}
+	return resp.Header, json.Unmarshal(data, out)
+}
+
+// statusError is returned for the non 2xx responses other than 404
+type statusError struct {
+	method     string
+	path       string
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.path, e.statusCode, e.body)
+}
+
+// hasStatus returns true if the request failed with the status code
+func hasStatus(err error, statusCode int) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.statusCode == statusCode
+}
+
+// getSecretToken returns the api token of the secret, the password is used
+// when the secret has no token
+func getSecretToken(secret *corev1.Secret) (string, error) {
+	if token, ok := secret.Data["token"]; ok && len(token) != 0 {
+		return string(token), nil
+	}
+	if password, ok := secret.Data["password"]; ok && len(password) != 0 {
+		return string(password), nil
+	}
+	return "", fmt.Errorf("secret %s/%s has no token or password", secret.GetNamespace(), secret.GetName())
+}
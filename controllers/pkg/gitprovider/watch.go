@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// SecretEventHandler refreshes the git client when the secret to connect to
+// the git server changes, the handler does not enqueue requests as the
+// reconcilers are triggered by the ReconnectSource once the client
+// reconnected.
+func SecretEventHandler(gitClient GitClient) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		key := GetSecretKey()
+		if o.GetNamespace() == key.Namespace && o.GetName() == key.Name {
+			log.FromContext(ctx).Info("git secret changed, refresh git client", "secret", key.String())
+			gitClient.Refresh()
+		}
+		return nil
+	})
+}
+
+// ReconnectSource returns a source that triggers when the git client
+// (re)connects to the git server, fn maps the event to the requests of all the
+// objects of the reconciler that need to be requeued.
+func ReconnectSource(ctx context.Context, gitClient GitClient, fn handler.MapFunc) source.Source {
+	generations := gitClient.Subscribe()
+	events := make(chan event.GenericEvent)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case generation := <-generations:
+				log.FromContext(ctx).Info("git client reconnected, requeue objects", "generation", generation)
+				// the object is a placeholder, fn lists the objects to requeue
+				select {
+				case events <- event.GenericEvent{Object: &corev1.Secret{}}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return source.Channel(events, handler.EnqueueRequestsFromMapFunc(fn))
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// errUnknownProvider is returned for requests without the headers of a
+	// supported git server
+	errUnknownProvider = errors.New("unknown git provider")
+	// errInvalidSignature is returned when the signature of the request does
+	// not match the secret
+	errInvalidSignature = errors.New("invalid signature")
+)
+
+// pushEvent is the provider neutral push event
+type pushEvent struct {
+	// Provider that sent the event
+	Provider string
+	// URLs of the repository, e.g. the http and ssh clone urls
+	URLs []string
+	// Branch that was pushed, empty for tags
+	Branch string
+	// After is the sha of the new head of the branch
+	After string
+}
+
+// provider holds the headers and payload of the push events of a git server
+type provider struct {
+	name        string
+	eventHeader string
+	pushEvent   string
+	// verify checks the signature of the body with the secret
+	verify func(r *http.Request, body, secret []byte) error
+	// urls returns the urls of the repository in the payload
+	urls func(body []byte) ([]string, error)
+}
+
+// providers are checked in order, gitea also sends the github headers for
+// compatibility and is checked first
+var providers = []provider{
+	{
+		name:        "gitea",
+		eventHeader: "X-Gitea-Event",
+		pushEvent:   "push",
+		verify: func(r *http.Request, body, secret []byte) error {
+			return verifyHMAC(r.Header.Get("X-Gitea-Signature"), body, secret)
+		},
+		urls: repositoryURLs,
+	},
+	{
+		name:        "github",
+		eventHeader: "X-GitHub-Event",
+		pushEvent:   "push",
+		verify: func(r *http.Request, body, secret []byte) error {
+			signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+			if !ok {
+				return errInvalidSignature
+			}
+			return verifyHMAC(signature, body, secret)
+		},
+		urls: repositoryURLs,
+	},
+	{
+		// gitlab does not sign the payload, it sends the secret token as is
+		name:        "gitlab",
+		eventHeader: "X-Gitlab-Event",
+		pushEvent:   "Push Hook",
+		verify: func(r *http.Request, _, secret []byte) error {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), secret) != 1 {
+				return errInvalidSignature
+			}
+			return nil
+		},
+		urls: projectURLs,
+	},
+}
+
+// getProvider returns the provider of the request headers
+func getProvider(r *http.Request) (*provider, error) {
+	for i := range providers {
+		if r.Header.Get(providers[i].eventHeader) != "" {
+			return &providers[i], nil
+		}
+	}
+	return nil, errUnknownProvider
+}
+
+// verifyHMAC compares the hex encoded hmac-sha256 signature with the one of
+// the body
+func verifyHMAC(signature string, body, secret []byte) error {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return errInvalidSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// pushPayload holds the fields of the push payload of gitea, github and gitlab
+type pushPayload struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		HTMLURL  string `json:"html_url"`
+		SSHURL   string `json:"ssh_url"`
+	} `json:"repository"`
+	Project struct {
+		HTTPURL string `json:"git_http_url"`
+		SSHURL  string `json:"git_ssh_url"`
+		WebURL  string `json:"web_url"`
+	} `json:"project"`
+}
+
+func repositoryURLs(body []byte) ([]string, error) {
+	p := &pushPayload{}
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil, err
+	}
+	return nonEmpty(p.Repository.CloneURL, p.Repository.HTMLURL, p.Repository.SSHURL), nil
+}
+
+func projectURLs(body []byte) ([]string, error) {
+	p := &pushPayload{}
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil, err
+	}
+	return nonEmpty(p.Project.HTTPURL, p.Project.WebURL, p.Project.SSHURL), nil
+}
+
+func nonEmpty(values ...string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// parsePushEvent verifies the request and returns its push event, nil when the
+// request is another event, e.g. a ping
+func parsePushEvent(r *http.Request, body, secret []byte) (*pushEvent, error) {
+	p, err := getProvider(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verify(r, body, secret); err != nil {
+		return nil, err
+	}
+	if r.Header.Get(p.eventHeader) != p.pushEvent {
+		return nil, nil
+	}
+
+	urls, err := p.urls(body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s push event: %w", p.name, err)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%s push event without repository url", p.name)
+	}
+	payload := &pushPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("cannot decode %s push event: %w", p.name, err)
+	}
+	branch, _ := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if branch == payload.Ref {
+		branch = ""
+	}
+	return &pushEvent{Provider: p.name, URLs: urls, Branch: branch, After: payload.After}, nil
+}
+
+// normalizeURL returns the host and path of a http or scp-like ssh git url in
+// lower case without the .git suffix, so the urls of the event and the porch
+// Repository can be compared
+func normalizeURL(repoURL string) string {
+	s := strings.TrimSpace(repoURL)
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		s = u.Host + u.Path
+	} else if user, rest, ok := strings.Cut(s, "@"); ok && !strings.Contains(user, "/") {
+		// git@host:owner/repo.git
+		s = strings.Replace(rest, ":", "/", 1)
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	return strings.ToLower(s)
+}
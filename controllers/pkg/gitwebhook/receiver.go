@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitwebhook receives the push events of gitea, github and gitlab and
+// asks porch to refresh the repositories that were pushed to, so changes made
+// outside of porch show up without waiting for the next sync.
+package gitwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch;patch
+
+const (
+	// Path the receiver serves the webhooks on
+	Path = "/git-webhook"
+	// RefreshKey is set on the porch Repository to the time of the push event
+	RefreshKey = "nephio.org/git-refresh"
+
+	defaultSecretName = "git-webhook-secret"
+	defaultBranch     = "main"
+	// secretKey is the key of the shared webhook secret in the secret
+	secretKey = "secret"
+	// maxBodySize limits the payload of a push event
+	maxBodySize = 5 << 20
+)
+
+// GetSecretKey returns the secret with the shared webhook secret, it lives in
+// the namespace of the git secret and is named by GIT_WEBHOOK_SECRET_NAME
+func GetSecretKey() types.NamespacedName {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if gitNamespace, ok := os.LookupEnv("GIT_NAMESPACE"); ok {
+		namespace = gitNamespace
+	}
+	secretName := defaultSecretName
+	if name, ok := os.LookupEnv("GIT_WEBHOOK_SECRET_NAME"); ok {
+		secretName = name
+	}
+	return types.NamespacedName{Namespace: namespace, Name: secretName}
+}
+
+// Receiver serves the git webhooks, it is added to the manager as runnable
+type Receiver struct {
+	// Address the http server listens on
+	Address string
+	// Client reads the secret and patches the porch Repositories, it is
+	// expected to be uncached
+	Client client.Client
+	// SecretKey is the secret with the shared webhook secret
+	SecretKey types.NamespacedName
+
+	// now is overwritten in tests
+	now func() time.Time
+}
+
+// New returns a receiver listening on address
+func New(address string, c client.Client) *Receiver {
+	return &Receiver{
+		Address:   address,
+		Client:    c,
+		SecretKey: GetSecretKey(),
+		now:       time.Now,
+	}
+}
+
+// NeedLeaderElection returns false, every replica accepts webhooks
+func (r *Receiver) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the webhooks until the context is done
+func (r *Receiver) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("git-webhook")
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+	srv := &http.Server{
+		Addr:              r.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "cannot shutdown git webhook server")
+		}
+	}()
+	log.Info("serving git webhooks", "address", r.Address, "path", Path)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP verifies the push event and refreshes the matching porch
+// Repositories
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := log.FromContext(ctx).WithName("git-webhook")
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, r.SecretKey, secret); err != nil {
+		log.Error(err, "cannot get webhook secret", "secret", r.SecretKey)
+		http.Error(w, "webhook secret not available", http.StatusServiceUnavailable)
+		return
+	}
+	if len(secret.Data[secretKey]) == 0 {
+		log.Info("webhook secret is empty, events are refused", "secret", r.SecretKey)
+		http.Error(w, "webhook secret not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	event, err := parsePushEvent(req, body, secret.Data[secretKey])
+	switch {
+	case errors.Is(err, errInvalidSignature):
+		log.Info("git webhook with invalid signature refused", "remote", req.RemoteAddr)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case event == nil || event.Branch == "":
+		// pings and tag pushes do not change the packages of porch
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	refreshed, err := r.refresh(ctx, event)
+	if err != nil {
+		log.Error(err, "cannot refresh porch repositories", "urls", event.URLs)
+		http.Error(w, "cannot refresh porch repositories", http.StatusInternalServerError)
+		return
+	}
+	log.Info("git push received", "provider", event.Provider, "branch", event.Branch, "after", event.After, "refreshed", refreshed)
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "refreshed %d repositories\n", len(refreshed))
+}
+
+// refresh sets the refresh annotation on the porch Repositories of the url and
+// branch of the event and returns their names
+func (r *Receiver) refresh(ctx context.Context, event *pushEvent) ([]string, error) {
+	urls := map[string]bool{}
+	for _, u := range event.URLs {
+		urls[normalizeURL(u)] = true
+	}
+
+	repos := &porchconfigv1alpha1.RepositoryList{}
+	if err := r.Client.List(ctx, repos); err != nil {
+		return nil, err
+	}
+	refreshed := []string{}
+	for i := range repos.Items {
+		repo := &repos.Items[i]
+		if repo.Spec.Git == nil || !urls[normalizeURL(repo.Spec.Git.Repo)] {
+			continue
+		}
+		// porch uses main when the branch is not set
+		branch := repo.Spec.Git.Branch
+		if branch == "" {
+			branch = defaultBranch
+		}
+		if branch != event.Branch {
+			continue
+		}
+		patch := client.MergeFrom(repo.DeepCopy())
+		annotations := repo.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[RefreshKey] = r.now().UTC().Format(time.RFC3339Nano)
+		repo.SetAnnotations(annotations)
+		if err := r.Client.Patch(ctx, repo, patch); err != nil {
+			return refreshed, err
+		}
+		refreshed = append(refreshed, client.ObjectKeyFromObject(repo).String())
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitwebhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestPorchRepository(name, repo, branch string) porchconfigv1alpha1.Repository {
+	return porchconfigv1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: porchconfigv1alpha1.RepositorySpec{
+			Type: porchconfigv1alpha1.RepositoryTypeGit,
+			Git:  &porchconfigv1alpha1.GitRepository{Repo: repo, Branch: branch},
+		},
+	}
+}
+
+func TestReceiverServeHTTP(t *testing.T) {
+	tests := map[string]struct {
+		method      string
+		secret      string
+		body        string
+		headers     map[string]string
+		wantStatus  int
+		wantPatched []string
+	}{
+		"github push": {
+			body:        testGitHubPush,
+			headers:     map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + sign(testGitHubPush)},
+			wantStatus:  http.StatusAccepted,
+			wantPatched: []string{"edge01", "edge01-ssh"},
+		},
+		"gitlab push": {
+			body:        testGitLabPush,
+			headers:     map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": testSecret},
+			wantStatus:  http.StatusAccepted,
+			wantPatched: []string{"gitlab"},
+		},
+		"invalid signature": {
+			body:       testGitHubPush,
+			headers:    map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + sign("other")},
+			wantStatus: http.StatusUnauthorized,
+		},
+		"ping": {
+			body:       `{}`,
+			headers:    map[string]string{"X-Gitea-Event": "ping", "X-Gitea-Signature": sign(`{}`)},
+			wantStatus: http.StatusOK,
+		},
+		"invalid payload": {
+			body:       `{`,
+			headers:    map[string]string{"X-Gitea-Event": "push", "X-Gitea-Signature": sign(`{`)},
+			wantStatus: http.StatusBadRequest,
+		},
+		"empty secret": {
+			secret:     "-",
+			body:       testGitHubPush,
+			headers:    map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + sign(testGitHubPush)},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		"get": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := testSecret
+			if tt.secret == "-" {
+				secret = ""
+			}
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", mock.Anything, types.NamespacedName{Namespace: "nephio-system", Name: "git-webhook-secret"}, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*corev1.Secret).Data = map[string][]byte{secretKey: []byte(secret)}
+			})
+			clientMock.On("List", mock.Anything, mock.AnythingOfType("*v1alpha1.RepositoryList")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(1).(*porchconfigv1alpha1.RepositoryList).Items = []porchconfigv1alpha1.Repository{
+					newTestPorchRepository("edge01", "https://github.com/nephio/edge01", ""),
+					newTestPorchRepository("edge01-ssh", "git@github.com:nephio/edge01.git", "main"),
+					newTestPorchRepository("edge01-dev", "https://github.com/nephio/edge01.git", "dev"),
+					newTestPorchRepository("edge02", "https://github.com/nephio/edge02.git", "main"),
+					newTestPorchRepository("gitlab", "https://gitlab.com/nephio/edge01.git", "main"),
+				}
+			})
+			patched := []string{}
+			clientMock.On("Patch", mock.Anything, mock.AnythingOfType("*v1alpha1.Repository"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				repo := args.Get(1).(*porchconfigv1alpha1.Repository)
+				if repo.GetAnnotations()[RefreshKey] != "2025-01-02T03:04:05Z" {
+					t.Errorf("Patch() annotations = %v, want %s", repo.GetAnnotations(), RefreshKey)
+				}
+				patched = append(patched, repo.GetName())
+			})
+
+			r := &Receiver{
+				Client:    clientMock,
+				SecretKey: types.NamespacedName{Namespace: "nephio-system", Name: "git-webhook-secret"},
+				now:       func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) },
+			}
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := newTestRequest(tt.body, tt.headers)
+			req.Method = method
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if len(patched) != len(tt.wantPatched) {
+				t.Fatalf("ServeHTTP() patched %v, want %v", patched, tt.wantPatched)
+			}
+			for i := range patched {
+				if patched[i] != tt.wantPatched[i] {
+					t.Errorf("ServeHTTP() patched %v, want %v", patched, tt.wantPatched)
+				}
+			}
+		})
+	}
+}
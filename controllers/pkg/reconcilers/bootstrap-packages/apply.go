@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// fieldManager is the field manager of the server-side apply of the
+	// bootstrap packages
+	fieldManager = "nephio-bootstrap-packages"
+	// dependsOnKey is the kpt annotation listing the objects an object
+	// depends on, e.g. apps/namespaces/default/Deployment/foo
+	dependsOnKey = "config.kubernetes.io/depends-on"
+)
+
+// apply phases, the objects of a phase are applied and ready before the next
+// phase starts
+const (
+	phaseCRD = iota
+	phaseNamespace
+	phaseRBAC
+	phaseWorkload
+)
+
+// getPhase returns the apply phase of the kind
+func getPhase(gk schema.GroupKind) int {
+	switch gk {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return phaseCRD
+	case schema.GroupKind{Kind: "Namespace"}:
+		return phaseNamespace
+	case schema.GroupKind{Kind: "ServiceAccount"},
+		schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "Role"},
+		schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+		schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+		schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:
+		return phaseRBAC
+	}
+	return phaseWorkload
+}
+
+// objectID is the kpt id of an object, <group>/namespaces/<namespace>/<kind>/<name>
+// for namespaced and <group>/<kind>/<name> for cluster scoped objects
+func objectID(gk schema.GroupKind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s/%s", gk.Group, gk.Kind, name)
+	}
+	return fmt.Sprintf("%s/namespaces/%s/%s/%s", gk.Group, namespace, gk.Kind, name)
+}
+
+// getDependencies returns the ids of the depends-on annotation
+func getDependencies(u *unstructured.Unstructured) ([]string, error) {
+	v := u.GetAnnotations()[dependsOnKey]
+	deps := []string{}
+	for _, dep := range strings.Split(v, ",") {
+		if dep = strings.TrimSpace(dep); dep == "" {
+			continue
+		}
+		parts := strings.Split(dep, "/")
+		switch {
+		case len(parts) == 3:
+		case len(parts) == 5 && parts[1] == "namespaces":
+		default:
+			return nil, fmt.Errorf("invalid %s annotation of %s: %q", dependsOnKey, u.GetName(), dep)
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// getWaves groups the resources in the order they are applied. The wave of a
+// resource is its phase, or the wave after its latest dependency in the
+// package when that is later. Dependencies outside of the package are
+// expected to exist already.
+func getWaves(resources []unstructured.Unstructured) ([][]unstructured.Unstructured, error) {
+	ids := map[string]int{}
+	for i := range resources {
+		gk := resources[i].GroupVersionKind().GroupKind()
+		ids[objectID(gk, resources[i].GetNamespace(), resources[i].GetName())] = i
+	}
+
+	levels := make([]int, len(resources))
+	// 0: not visited, 1: visiting, 2: done
+	state := make([]int, len(resources))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			return fmt.Errorf("dependency cycle at %s", resources[i].GetName())
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		level := getPhase(resources[i].GroupVersionKind().GroupKind())
+		deps, err := getDependencies(&resources[i])
+		if err != nil {
+			return err
+		}
+		for _, dep := range deps {
+			j, ok := ids[dep]
+			if !ok {
+				continue
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+			level = max(level, levels[j]+1)
+		}
+		levels[i] = level
+		state[i] = 2
+		return nil
+	}
+	for i := range resources {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	byLevel := map[int][]unstructured.Unstructured{}
+	for i, level := range levels {
+		byLevel[level] = append(byLevel[level], resources[i])
+	}
+	keys := make([]int, 0, len(byLevel))
+	for level := range byLevel {
+		keys = append(keys, level)
+	}
+	slices.Sort(keys)
+	waves := make([][]unstructured.Unstructured, 0, len(keys))
+	for _, level := range keys {
+		waves = append(waves, byLevel[level])
+	}
+	return waves, nil
+}
+
+// applyResources server-side applies the resources wave by wave, the next wave
+// is applied once the resources of a wave are current. It returns false when a
+// wave is not current yet, the package is requeued and the next reconcile
+// applies again and continues from there. The last wave has no successor and
+// is not waited for, e.g. a crash looping workload does not block the package.
+func (r *reconciler) applyResources(ctx context.Context, clusterClient client.Client, resources []unstructured.Unstructured) (bool, error) {
+	log := log.FromContext(ctx)
+	waves, err := getWaves(resources)
+	if err != nil {
+		return false, err
+	}
+	for i, wave := range waves {
+		for _, u := range wave {
+			u := u.DeepCopy()
+			log.Info("install manifest", "wave", i, "resource", fmt.Sprintf("%s.%s.%s", u.GetAPIVersion(), u.GetKind(), u.GetName()))
+			if err := clusterClient.Patch(ctx, u, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+				return false, fmt.Errorf("cannot apply resource to cluster: resourceName: %s: %w", u.GetName(), err)
+			}
+		}
+		if i == len(waves)-1 {
+			break
+		}
+		ready, err := isReady(ctx, clusterClient, wave)
+		if err != nil || !ready {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isReady returns true when the kstatus of all resources is current
+func isReady(ctx context.Context, clusterClient client.Client, resources []unstructured.Unstructured) (bool, error) {
+	log := log.FromContext(ctx)
+	ready := true
+	for _, u := range resources {
+		current := resource.GetUnstructuredFromGVK(ptr.To(u.GroupVersionKind()))
+		if err := clusterClient.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, current); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return false, err
+			}
+			log.Info("resource not ready", "resource", fmt.Sprintf("%s.%s.%s", u.GetAPIVersion(), u.GetKind(), u.GetName()), "status", "not found")
+			ready = false
+			continue
+		}
+		result, err := status.Compute(current)
+		if err != nil {
+			return false, err
+		}
+		if result.Status != status.CurrentStatus {
+			log.Info("resource not ready", "resource", fmt.Sprintf("%s.%s.%s", u.GetAPIVersion(), u.GetKind(), u.GetName()), "status", result.Status, "message", result.Message)
+			ready = false
+		}
+	}
+	return ready, nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func withDependsOn(u unstructured.Unstructured, deps string) unstructured.Unstructured {
+	u.SetAnnotations(map[string]string{dependsOnKey: deps})
+	return u
+}
+
+func waveNames(waves [][]unstructured.Unstructured) [][]string {
+	names := [][]string{}
+	for _, wave := range waves {
+		n := []string{}
+		for _, u := range wave {
+			n = append(n, u.GetName())
+		}
+		names = append(names, n)
+	}
+	return names
+}
+
+func TestGetWaves(t *testing.T) {
+	cases := map[string]struct {
+		resources []unstructured.Unstructured
+		want      [][]string
+		wantErr   bool
+	}{
+		"Phases": {
+			resources: []unstructured.Unstructured{
+				newTestResource("apps/v1", "Deployment", "ns", "deploy"),
+				newTestResource("example.com/v1", "Foo", "ns", "foo"),
+				newTestResource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "role"),
+				newTestResource("v1", "Namespace", "", "ns"),
+				newTestResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "foos.example.com"),
+			},
+			want: [][]string{{"foos.example.com"}, {"ns"}, {"role"}, {"deploy", "foo"}},
+		},
+		"DependsOn": {
+			resources: []unstructured.Unstructured{
+				withDependsOn(newTestResource("apps/v1", "Deployment", "ns", "app"), "apps/namespaces/ns/Deployment/db"),
+				newTestResource("apps/v1", "Deployment", "ns", "db"),
+				withDependsOn(newTestResource("v1", "ConfigMap", "ns", "cm"), "/namespaces/other/ConfigMap/external"),
+			},
+			want: [][]string{{"db", "cm"}, {"app"}},
+		},
+		"DependsOnClusterScoped": {
+			resources: []unstructured.Unstructured{
+				withDependsOn(newTestResource("v1", "Namespace", "", "ns"), "rbac.authorization.k8s.io/ClusterRole/role"),
+				newTestResource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "role"),
+			},
+			want: [][]string{{"role"}, {"ns"}},
+		},
+		"Cycle": {
+			resources: []unstructured.Unstructured{
+				withDependsOn(newTestResource("v1", "ConfigMap", "ns", "a"), "/namespaces/ns/ConfigMap/b"),
+				withDependsOn(newTestResource("v1", "ConfigMap", "ns", "b"), "/namespaces/ns/ConfigMap/a"),
+			},
+			wantErr: true,
+		},
+		"InvalidAnnotation": {
+			resources: []unstructured.Unstructured{
+				withDependsOn(newTestResource("v1", "ConfigMap", "ns", "a"), "ConfigMap/b"),
+			},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			waves, err := getWaves(tc.resources)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, waveNames(waves))
+		})
+	}
+}
+
+func TestApplyResources(t *testing.T) {
+	cases := map[string]struct {
+		resources   []unstructured.Unstructured
+		current     map[string]any
+		wantReady   bool
+		wantApplied []string
+		// the readiness is checked once per wave with a successor, without
+		// polling
+		wantGets int
+	}{
+		"Ready": {
+			resources: []unstructured.Unstructured{
+				newTestResource("v1", "ConfigMap", "ns", "cm"),
+				newTestResource("v1", "Namespace", "", "ns"),
+			},
+			wantReady:   true,
+			wantApplied: []string{"ns", "cm"},
+			wantGets:    1,
+		},
+		// the last wave is not waited for
+		"LastWaveNotReady": {
+			resources: []unstructured.Unstructured{
+				newTestResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "foos.example.com"),
+			},
+			current: map[string]any{
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Established", "status": "False"},
+					},
+				},
+			},
+			wantReady:   true,
+			wantApplied: []string{"foos.example.com"},
+			wantGets:    0,
+		},
+		"NotReady": {
+			resources: []unstructured.Unstructured{
+				newTestResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "foos.example.com"),
+				newTestResource("example.com/v1", "Foo", "ns", "foo"),
+			},
+			// a crd that is not established yet
+			current: map[string]any{
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Established", "status": "False"},
+					},
+				},
+			},
+			wantReady:   false,
+			wantApplied: []string{"foos.example.com"},
+			wantGets:    1,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			applied := []string{}
+			clusterMock := new(mocks.MockClient)
+			clusterMock.On("Patch", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured"), client.Apply, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				applied = append(applied, args.Get(1).(*unstructured.Unstructured).GetName())
+			})
+			clusterMock.On("Get", mock.Anything, mock.Anything, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+				u := args.Get(2).(*unstructured.Unstructured)
+				for k, v := range tc.current {
+					u.Object[k] = v
+				}
+			})
+
+			r := &reconciler{}
+			ready, err := r.applyResources(context.TODO(), clusterMock, tc.resources)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantReady, ready)
+			assert.Equal(t, tc.wantApplied, applied)
+			clusterMock.AssertNumberOfCalls(t, "Get", tc.wantGets)
+		})
+	}
+}
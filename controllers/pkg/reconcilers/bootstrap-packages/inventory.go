@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	porchv1alpha1 "github.com/nephio-project/porch/api/porch/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// inventoryKey is the key of the applied objects in the inventory
+	// configmap
+	inventoryKey = "inventory"
+	// inventoryPrefix is the name prefix of the inventory configmaps
+	inventoryPrefix = "bootstrap-inventory-"
+
+	clusterNameKey         = "nephio.org/cluster-name"
+	inventoryRepositoryKey = "bootstrap.nephio.org/repository"
+	inventoryPackageKey    = "bootstrap.nephio.org/package"
+)
+
+// objectRef identifies an object applied to the workload cluster
+type objectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (o objectRef) String() string {
+	return fmt.Sprintf("%s.%s.%s/%s", o.APIVersion, o.Kind, o.Namespace, o.Name)
+}
+
+// sameObject returns true when both reference the same object, the version is
+// ignored as a new revision can change the api version of an object
+func (o objectRef) sameObject(other objectRef) bool {
+	gv, _ := schema.ParseGroupVersion(o.APIVersion)
+	otherGV, _ := schema.ParseGroupVersion(other.APIVersion)
+	return gv.Group == otherGV.Group && o.Kind == other.Kind && o.Namespace == other.Namespace && o.Name == other.Name
+}
+
+func (o objectRef) phase() int {
+	gv, _ := schema.ParseGroupVersion(o.APIVersion)
+	return getPhase(schema.GroupKind{Group: gv.Group, Kind: o.Kind})
+}
+
+// getObjectRefs returns the sorted references of the resources
+func getObjectRefs(resources []unstructured.Unstructured) []objectRef {
+	refs := make([]objectRef, 0, len(resources))
+	for _, u := range resources {
+		refs = append(refs, objectRef{APIVersion: u.GetAPIVersion(), Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()})
+	}
+	slices.SortFunc(refs, func(a, b objectRef) int { return strings.Compare(a.String(), b.String()) })
+	return slices.Compact(refs)
+}
+
+// getInventoryName returns the name of the inventory of the package on the
+// cluster, the names of the repository, package and cluster are hashed as
+// they can exceed the length of a name together
+func getInventoryName(cr *porchv1alpha1.PackageRevision, clusterName string) string {
+	h := sha256.Sum256([]byte(strings.Join([]string{cr.Spec.RepositoryName, cr.Spec.PackageName, clusterName}, "/")))
+	return inventoryPrefix + hex.EncodeToString(h[:])[:16]
+}
+
+// getInventory returns the objects of the inventory, an inventory that does
+// not exist is empty
+func (r *reconciler) getInventory(ctx context.Context, key types.NamespacedName) ([]objectRef, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		return nil, resource.IgnoreNotFound(err)
+	}
+	refs := []objectRef{}
+	if err := json.Unmarshal([]byte(cm.Data[inventoryKey]), &refs); err != nil {
+		return nil, fmt.Errorf("cannot decode inventory %s: %w", key, err)
+	}
+	return refs, nil
+}
+
+// getInventoryClusterNames returns the clusters with an inventory of the
+// package, the cluster name is recorded in the annotations of the inventory
+func (r *reconciler) getInventoryClusterNames(ctx context.Context, cr *porchv1alpha1.PackageRevision) ([]string, error) {
+	cms := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cms, client.InNamespace(cr.GetNamespace())); err != nil {
+		return nil, err
+	}
+	clusterNames := []string{}
+	for _, cm := range cms.Items {
+		annotations := cm.GetAnnotations()
+		if !strings.HasPrefix(cm.GetName(), inventoryPrefix) ||
+			annotations[inventoryRepositoryKey] != cr.Spec.RepositoryName ||
+			annotations[inventoryPackageKey] != cr.Spec.PackageName ||
+			annotations[clusterNameKey] == "" {
+			continue
+		}
+		// the inventory name is derived from the cluster name
+		if cm.GetName() != getInventoryName(cr, annotations[clusterNameKey]) {
+			continue
+		}
+		clusterNames = append(clusterNames, annotations[clusterNameKey])
+	}
+	slices.Sort(clusterNames)
+	return clusterNames, nil
+}
+
+// applyInventory stores the objects of the package revision applied to the
+// cluster in the inventory configmap in the namespace of the package revision
+func (r *reconciler) applyInventory(ctx context.Context, key types.NamespacedName, cr *porchv1alpha1.PackageRevision, clusterName string, refs []objectRef) error {
+	b, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Annotations: map[string]string{
+				clusterNameKey:         clusterName,
+				inventoryRepositoryKey: cr.Spec.RepositoryName,
+				inventoryPackageKey:    cr.Spec.PackageName,
+			},
+		},
+		Data: map[string]string{inventoryKey: string(b)},
+	}
+	applicator := resource.NewAPIPatchingApplicator(r.Client)
+	return applicator.Apply(ctx, cm)
+}
+
+// prune deletes the objects of the inventory that are not in the applied
+// resources from the cluster and records the applied resources as the new
+// inventory. Objects that are already gone, or whose kind no longer exists on
+// the cluster, are ignored.
+func (r *reconciler) prune(ctx context.Context, clusterClient client.Client, cr *porchv1alpha1.PackageRevision, clusterName string, resources []unstructured.Unstructured) error {
+	log := log.FromContext(ctx)
+	key := types.NamespacedName{Namespace: cr.GetNamespace(), Name: getInventoryName(cr, clusterName)}
+	inventory, err := r.getInventory(ctx, key)
+	if err != nil {
+		return err
+	}
+	refs := getObjectRefs(resources)
+	// delete in the reverse order of the apply phases, e.g. the CRDs last
+	slices.SortStableFunc(inventory, func(a, b objectRef) int { return b.phase() - a.phase() })
+	for _, ref := range inventory {
+		if slices.ContainsFunc(refs, ref.sameObject) {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			log.Error(err, "invalid inventory entry, skipped", "object", ref.String())
+			continue
+		}
+		u := resource.GetUnstructuredFromGVK(&schema.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: ref.Kind})
+		u.SetNamespace(ref.Namespace)
+		u.SetName(ref.Name)
+		log.Info("prune manifest", "resource", ref.String())
+		if err := clusterClient.Delete(ctx, u); resource.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("cannot prune %s: %w", ref.String(), err)
+		}
+	}
+	return r.applyInventory(ctx, key, cr, clusterName, refs)
+}
+
+// isLatestRevision returns true when no newer revision of the package is
+// published in the repository, older revisions are neither applied nor pruned
+// so they cannot revert a newer one
+func (r *reconciler) isLatestRevision(ctx context.Context, cr *porchv1alpha1.PackageRevision) (bool, error) {
+	prs := &porchv1alpha1.PackageRevisionList{}
+	if err := r.List(ctx, prs, client.InNamespace(cr.GetNamespace())); err != nil {
+		return false, err
+	}
+	for _, pr := range prs.Items {
+		if pr.Spec.RepositoryName == cr.Spec.RepositoryName &&
+			pr.Spec.PackageName == cr.Spec.PackageName &&
+			porchv1alpha1.LifecycleIsPublished(pr.Spec.Lifecycle) &&
+			pr.Spec.Revision > cr.Spec.Revision {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	porchv1alpha1 "github.com/nephio-project/porch/api/porch/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestPackageRevision(revision int, lifecycle porchv1alpha1.PackageRevisionLifecycle) porchv1alpha1.PackageRevision {
+	return porchv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "mgmt-staging-edge01"},
+		Spec: porchv1alpha1.PackageRevisionSpec{
+			RepositoryName: "mgmt-staging",
+			PackageName:    "edge01",
+			Revision:       revision,
+			Lifecycle:      lifecycle,
+		},
+	}
+}
+
+func newTestResource(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestPrune(t *testing.T) {
+	inventory := []objectRef{
+		{APIVersion: "v1", Kind: "Namespace", Name: "config-management-system"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "old"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "gone"},
+	}
+	b, err := json.Marshal(inventory)
+	assert.NoError(t, err)
+
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), mock.Anything, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*corev1.ConfigMap).Data = map[string]string{inventoryKey: string(b)}
+	})
+	var applied []objectRef
+	clientMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.ConfigMap"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		// the patch holds the desired configmap, the object the current one
+		data, err := args.Get(2).(client.Patch).Data(nil)
+		assert.NoError(t, err)
+		cm := &corev1.ConfigMap{}
+		assert.NoError(t, json.Unmarshal(data, cm))
+		assert.NoError(t, json.Unmarshal([]byte(cm.Data[inventoryKey]), &applied))
+		assert.Equal(t, "edge01", cm.GetAnnotations()[clusterNameKey])
+	})
+
+	deleted := []string{}
+	clusterMock := new(mocks.MockClient)
+	clusterMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+	}).Once()
+	clusterMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(kerrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "gone")).Run(func(args mock.Arguments) {
+		deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+	}).Once()
+
+	cr := newTestPackageRevision(2, porchv1alpha1.PackageRevisionLifecyclePublished)
+	resources := []unstructured.Unstructured{
+		newTestResource("v1", "Namespace", "", "config-management-system"),
+		newTestResource("v1", "ConfigMap", "kube-system", "new"),
+	}
+	r := &reconciler{Client: clientMock}
+	assert.NoError(t, r.prune(context.TODO(), clusterMock, &cr, "edge01", resources))
+
+	assert.ElementsMatch(t, []string{"old", "gone"}, deleted)
+	assert.Equal(t, getObjectRefs(resources), applied)
+}
+
+func TestPruneChangedVersion(t *testing.T) {
+	inventory := []objectRef{
+		{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", Namespace: "kube-system", Name: "agent"},
+		{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", Namespace: "kube-system", Name: "old"},
+	}
+	b, err := json.Marshal(inventory)
+	assert.NoError(t, err)
+
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), mock.Anything, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*corev1.ConfigMap).Data = map[string]string{inventoryKey: string(b)}
+	})
+	clientMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.ConfigMap"), mock.Anything).Return(nil)
+	deleted := []string{}
+	clusterMock := new(mocks.MockClient)
+	clusterMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+	})
+
+	cr := newTestPackageRevision(2, porchv1alpha1.PackageRevisionLifecyclePublished)
+	resources := []unstructured.Unstructured{
+		newTestResource("autoscaling/v2", "HorizontalPodAutoscaler", "kube-system", "agent"),
+	}
+	r := &reconciler{Client: clientMock}
+	assert.NoError(t, r.prune(context.TODO(), clusterMock, &cr, "edge01", resources))
+
+	// the object applied with the new version is kept
+	assert.Equal(t, []string{"old"}, deleted)
+}
+
+func TestPruneWithoutInventory(t *testing.T) {
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), mock.Anything, mock.AnythingOfType("*v1.ConfigMap")).Return(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "inventory"))
+	clientMock.On("Create", context.TODO(), mock.AnythingOfType("*v1.ConfigMap")).Return(nil)
+	clusterMock := new(mocks.MockClient)
+
+	cr := newTestPackageRevision(1, porchv1alpha1.PackageRevisionLifecyclePublished)
+	r := &reconciler{Client: clientMock}
+	assert.NoError(t, r.prune(context.TODO(), clusterMock, &cr, "edge01", []unstructured.Unstructured{newTestResource("v1", "Namespace", "", "ns")}))
+
+	clusterMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	clientMock.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestIsLatestRevision(t *testing.T) {
+	cases := map[string]struct {
+		revisions []porchv1alpha1.PackageRevision
+		want      bool
+	}{
+		"Latest": {
+			revisions: []porchv1alpha1.PackageRevision{
+				newTestPackageRevision(1, porchv1alpha1.PackageRevisionLifecyclePublished),
+				newTestPackageRevision(2, porchv1alpha1.PackageRevisionLifecyclePublished),
+			},
+			want: true,
+		},
+		"NewerPublished": {
+			revisions: []porchv1alpha1.PackageRevision{
+				newTestPackageRevision(3, porchv1alpha1.PackageRevisionLifecyclePublished),
+			},
+			want: false,
+		},
+		"NewerDraft": {
+			revisions: []porchv1alpha1.PackageRevision{
+				newTestPackageRevision(0, porchv1alpha1.PackageRevisionLifecycleDraft),
+				newTestPackageRevision(3, porchv1alpha1.PackageRevisionLifecycleProposed),
+			},
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clientMock := new(mocks.MockClient)
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1alpha1.PackageRevisionList"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(1).(*porchv1alpha1.PackageRevisionList).Items = tc.revisions
+			})
+			cr := newTestPackageRevision(2, porchv1alpha1.PackageRevisionLifecyclePublished)
+			r := &reconciler{Client: clientMock}
+			got, err := r.isLatestRevision(context.TODO(), &cr)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetInventoryName(t *testing.T) {
+	cr := newTestPackageRevision(1, porchv1alpha1.PackageRevisionLifecyclePublished)
+	name := getInventoryName(&cr, "edge01")
+	assert.Equal(t, name, getInventoryName(&cr, "edge01"))
+	assert.NotEqual(t, name, getInventoryName(&cr, "edge02"))
+	assert.LessOrEqual(t, len(name), 63)
+}
+
+func TestGetInventoryClusterNames(t *testing.T) {
+	cr := newTestPackageRevision(3, porchv1alpha1.PackageRevisionLifecyclePublished)
+	inventory := func(name, repo, pkg, clusterName string) corev1.ConfigMap {
+		return corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				clusterNameKey:         clusterName,
+				inventoryRepositoryKey: repo,
+				inventoryPackageKey:    pkg,
+			},
+		}}
+	}
+	clientMock := new(mocks.MockClient)
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.ConfigMapList"), client.InNamespace("default")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*corev1.ConfigMapList).Items = []corev1.ConfigMap{
+			inventory(getInventoryName(&cr, "edge02"), "mgmt-staging", "edge01", "edge02"),
+			inventory(getInventoryName(&cr, "edge01"), "mgmt-staging", "edge01", "edge01"),
+			// inventories of other packages
+			inventory(getInventoryName(&cr, "edge03"), "mgmt-staging", "edge03", "edge03"),
+			inventory("kube-root-ca.crt", "mgmt-staging", "edge01", "edge04"),
+		}
+	})
+
+	r := &reconciler{Client: clientMock}
+	clusterNames, err := r.getInventoryClusterNames(context.TODO(), &cr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"edge01", "edge02"}, clusterNames)
+}
+
+// a revision without resources prunes all objects of the inventory
+func TestPruneEmptyRevision(t *testing.T) {
+	inventory := []objectRef{
+		{APIVersion: "v1", Kind: "Namespace", Name: "config-management-system"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "old"},
+	}
+	b, err := json.Marshal(inventory)
+	assert.NoError(t, err)
+
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), mock.Anything, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*corev1.ConfigMap).Data = map[string]string{inventoryKey: string(b)}
+	})
+	applied := []objectRef{{}}
+	clientMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.ConfigMap"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		data, err := args.Get(2).(client.Patch).Data(nil)
+		assert.NoError(t, err)
+		cm := &corev1.ConfigMap{}
+		assert.NoError(t, json.Unmarshal(data, cm))
+		assert.NoError(t, json.Unmarshal([]byte(cm.Data[inventoryKey]), &applied))
+	})
+	deleted := []string{}
+	clusterMock := new(mocks.MockClient)
+	clusterMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+	})
+
+	cr := newTestPackageRevision(3, porchv1alpha1.PackageRevisionLifecyclePublished)
+	r := &reconciler{Client: clientMock}
+	assert.NoError(t, r.prune(context.TODO(), clusterMock, &cr, "edge01", nil))
+
+	// the namespace is deleted last
+	assert.Equal(t, []string{"old", "config-management-system"}, deleted)
+	assert.Empty(t, applied)
+}
@@ -31,7 +31,6 @@ import (
 	porchv1alpha1 "github.com/nephio-project/porch/api/porch/v1alpha1"
 	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -54,6 +53,7 @@ func init() {
 //+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch
 //+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get
 //+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
@@ -69,6 +69,10 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 		return nil, err
 	}
 
+	if err := cluster.SetupIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+
 	r.Client = mgr.GetClient()
 	r.porchClient = cfg.PorchClient
 
@@ -106,6 +110,16 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, errors.Wrap(err, msg)
 	}
 	if stagingPR && porchv1alpha1.LifecycleIsPublished(cr.Spec.Lifecycle) {
+		latest, err := r.isLatestRevision(ctx, cr)
+		if err != nil {
+			msg := "cannot list package revisions"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		if !latest {
+			log.Info("newer package revision published, skip", "revision", cr.Spec.Revision)
+			return ctrl.Result{}, nil
+		}
 		log.Info("reconcile package revision")
 		// get the relevant package revision resources
 		resources, err := r.getPrResources(ctx, req)
@@ -114,75 +128,87 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			log.Error(err, msg)
 			return ctrl.Result{}, errors.Wrap(err, msg)
 		}
+		clusterNames := []string{}
 		if len(resources) > 0 {
 			// we expect the clusterName to be applied to all resources in the
 			// package revision resources, so we find the cluster name by looking at the
 			// first resource in the resource list
-			clusterName, ok := resources[0].GetAnnotations()["nephio.org/cluster-name"]
+			clusterName, ok := resources[0].GetAnnotations()[clusterNameKey]
 			if !ok {
 				log.Info("clusterName not found",
 					"resource", fmt.Sprintf("%s.%s.%s", resources[0].GetAPIVersion(), resources[0].GetKind(), resources[0].GetName()),
 					"annotations", resources[0].GetAnnotations())
 				return ctrl.Result{}, nil
 			}
-			clusterClient, ok, err := r.GetClusterClient(ctx, clusterName)
+			clusterNames = append(clusterNames, clusterName)
+		} else {
+			// a revision without resources has no cluster name, the objects of
+			// older revisions are pruned from the clusters of their inventories
+			clusterNames, err = r.getInventoryClusterNames(ctx, cr)
 			if err != nil {
-				msg := fmt.Sprintf("failed to get cluster Secret for: %s", clusterName)
+				msg := "cannot list inventories"
 				log.Error(err, msg)
 				return ctrl.Result{}, errors.Wrap(err, msg)
 			}
-			if ok {
-				clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
-				if err != nil {
-					msg := "cannot get clusterClient"
-					log.Error(err, msg)
-					return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-				}
-				if !ready {
-					log.Info("cluster not ready")
-					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-				}
-				// install the resources to the cluster
-				for _, resource := range resources {
-					resource := resource // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-					log.Info("install manifest", "resource", fmt.Sprintf("%s.%s.%s", resource.GetAPIVersion(), resource.GetKind(), resource.GetName()))
-					if err := clusterClient.Apply(ctx, &resource); err != nil {
-						msg := fmt.Sprintf("cannot apply resource to cluster: resourceName: %s", resource.GetName())
-						log.Error(err, msg)
-						return ctrl.Result{}, errors.Wrap(err, msg)
-					}
-				}
-			} else {
-				// the clusterClient was not found, we retry
-				log.Info("cluster client not found, retry...")
-				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		for _, clusterName := range clusterNames {
+			if result, err := r.reconcileCluster(ctx, cr, clusterName, resources); err != nil || !result.IsZero() {
+				return result, err
 			}
 		}
 	}
 	return ctrl.Result{}, nil
 }
 
-func (r *reconciler) GetClusterClient(ctx context.Context, clusterName string) (cluster.ClusterClient, bool, error) {
-	// we need to find the cluster client secret
-	secrets := &corev1.SecretList{}
-	if err := r.List(ctx, secrets); err != nil {
-		return nil, false, err
+// reconcileCluster applies the resources of the package revision to the
+// cluster and prunes the objects of older revisions that are not in them
+func (r *reconciler) reconcileCluster(ctx context.Context, cr *porchv1alpha1.PackageRevision, clusterName string, resources []unstructured.Unstructured) (ctrl.Result, error) {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	clusterClient, ok, err := r.GetClusterClient(ctx, clusterName)
+	if err != nil {
+		msg := fmt.Sprintf("failed to get cluster Secret for: %s", clusterName)
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
 	}
-	clusterSecret := corev1.Secret{}
-	for _, secret := range secrets.Items {
-		if strings.Contains(secret.GetName(), clusterName) {
-			clusterSecret = secret
-
-			clusterClient, ok := cluster.Cluster{
-				Client: r.Client,
-			}.GetClusterClient(&clusterSecret)
-
-			if ok {
-				return clusterClient, ok, nil
-			}
-		}
+	if !ok {
+		// the clusterClient was not found, we retry
+		log.Info("cluster client not found, retry...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	cl, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		msg := "cannot get clusterClient"
+		log.Error(err, msg)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+	}
+	if !ready {
+		log.Info("cluster not ready")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	// install the resources to the cluster, ordered by their dependencies
+	ready, err = r.applyResources(ctx, cl, resources)
+	if err != nil {
+		msg := "cannot apply resources to cluster"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
 	}
-	return nil, false, nil
+	if !ready {
+		// requeued with the backoff of the rate limiter, a wave that stays
+		// not current is checked less and less often
+		log.Info("resources not ready, retry...")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	// delete the objects of older revisions that are not in this one
+	if err := r.prune(ctx, cl, cr, clusterName, resources); err != nil {
+		msg := "cannot prune resources"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *reconciler) GetClusterClient(ctx context.Context, clusterName string) (cluster.ClusterClient, bool, error) {
+	return cluster.Registry{Client: r.Client}.GetClusterClient(ctx, clusterName)
 }
 
 func (r *reconciler) IsStagingPackageRevision(ctx context.Context, repositoryName string) (bool, error) {
@@ -19,20 +19,25 @@ package bootstrapsecret
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -45,26 +50,66 @@ const (
 	clusterNameKey     = "nephio.org/cluster-name"
 	nephioAppKey       = "nephio.org/app"
 	remoteNamespaceKey = "nephio.org/remote-namespace"
-	syncApp            = "tobeinstalledonremotecluster"
-	bootstrapApp       = "bootstrap"
+	// clusterSelectorKey selects the clusters by their labels, e.g.
+	// region=west
+	clusterSelectorKey = "nephio.org/cluster-selector"
+	// syncStatusKey holds the sync status of the secret per cluster
+	syncStatusKey = "nephio.org/sync-status"
+	syncApp       = "tobeinstalledonremotecluster"
+	bootstrapApp  = "bootstrap"
+	mgmtCluster   = "mgmt"
+	// finalizer deletes the copies of the secret from the remote clusters
+	finalizer = "nephio.org/bootstrap-secret"
+	// resyncPeriod picks up label changes of the clusters of a selector
+	resyncPeriod = 5 * time.Minute
 )
 
-//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	if err := cluster.SetupIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
 	r.Client = mgr.GetClient()
+	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.replicator = replication.NewReplicator("bootstrapsecrets")
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapSecretController").
 		For(&corev1.Secret{}).
+		// new or changed cluster credentials requeue the secrets to sync, a
+		// new cluster might match their selector
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.listSyncSecrets)).
 		Complete(r)
 }
 
 type reconciler struct {
 	client.Client
+	finalizer  *resource.APIFinalizer
+	replicator *replication.Replicator
+}
+
+// listSyncSecrets returns the requests of the secrets to sync when the object
+// is the credentials secret of a cluster
+func (r *reconciler) listSyncSecrets(ctx context.Context, o client.Object) []reconcile.Request {
+	if len(cluster.IndexClusterName(o)) == 0 {
+		return nil
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list secrets")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, secret := range secrets.Items {
+		if secret.GetAnnotations()[nephioAppKey] == syncApp {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}})
+		}
+	}
+	return requests
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -81,110 +126,249 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return reconcile.Result{}, nil
 	}
 
-	// if the secret is being deleted don't do anything for now
-	if resource.WasDeleted(cr) {
+	// the secret is relevant to be installed in the workload clusters if:
+	// annotation key "nephio.org/app" == tobeinstalledonremotecluster
+	// annotation key "nephio.org/cluster-name" or "nephio.org/cluster-selector"
+	// selects clusters different then management
+	// a secret that is deleted or no longer relevant has no clusters, its
+	// copies are deleted from the clusters in its status
+	clusterNames, err := r.getClusterNames(ctx, cr)
+	if err != nil {
+		msg := "cannot get cluster names"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	status := getSyncStatus(cr)
+	if len(clusterNames) == 0 && len(status) == 0 {
+		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
+			msg := "cannot remove finalizer"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
 		return reconcile.Result{}, nil
 	}
+	if len(clusterNames) > 0 {
+		log.Info("reconcile secret", "clusters", clusterNames)
+		// add finalizer to avoid deleting the secret w/o deleting its copies
+		if err := r.finalizer.AddFinalizer(ctx, cr); err != nil {
+			msg := "cannot add finalizer"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+	}
 
-	// this branch handles installing the secrets to the remote cluster
-	// the secret is relevant to be installed in the workload cluster if:
-	// annotation key "nephio.org/app" == configsync
-	// annotation key "nephio.org/cluster-name" different then "" and different then management
-	if cr.GetAnnotations()[nephioAppKey] == syncApp &&
-		cr.GetAnnotations()[clusterNameKey] != "" &&
-		cr.GetAnnotations()[clusterNameKey] != "mgmt" {
-		log.Info("reconcile secret")
-		// a clusterName can be modelled as multiple clusters to allow
-		// the secret to be deployed on multiple clusters
-		// syntax: nephio.org/cluster-name = cluster01,cluster02,cluster03
-		clusterName := cr.GetAnnotations()[clusterNameKey]
-		clusterNames := strings.Split(clusterName, ",")
-
-		// for each cluster specified in the annotation, we need to find the
-		// corresponding cluster credentials to access th remote cluster
-		// we walk through the secrets and check if the cluster api client accepts them
-		// if so we check is the assigned namespace is available and if so we apply the
-		// secret to the remote cluster.
-		for _, clusterName := range clusterNames {
-			secrets := &corev1.SecretList{}
-			if err := r.List(ctx, secrets); err != nil {
-				msg := "cannot list secrets"
-				log.Error(err, msg)
-				return ctrl.Result{}, errors.Wrap(err, msg)
-			}
-			found := false
-			for _, secret := range secrets.Items {
-				if strings.Contains(secret.GetName(), clusterName) {
-					secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-					clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
-					if ok {
-						found = true
-						clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
-						if err != nil {
-							msg := "cannot get clusterClient"
-							log.Error(err, msg)
-							return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-						}
-						if !ready {
-							log.Info("cluster not ready")
-							return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-						}
-
-						// remoteNamespace holds the namespace of the remote cluster
-						// on which this secret is to be installed.
-						// e.g. configsync requires it to be installed in configsync-management
-						// but the mgmt cluster already has configsync-management in use, so
-						// we need the ability to change the namespace of the remote cluster
-						// for this secret
-						// the controller uses the namespace of the cr by default and if the
-						// remoteNamespace annotation `"nephio.org/remote-namespace"` is set
-						// it will use the value of the  annotation as the remote namespace
-						remoteNamespace := cr.Namespace
-						if rns, ok := cr.GetAnnotations()[remoteNamespaceKey]; ok {
-							remoteNamespace = rns
-						}
-						// check if the remote namespace exists, if not retry
-						ns := &corev1.Namespace{}
-						if err = clusterClient.Get(ctx, types.NamespacedName{Name: remoteNamespace}, ns); err != nil {
-							if resource.IgnoreNotFound(err) != nil {
-								msg := fmt.Sprintf("cannot get namespace: %s", remoteNamespace)
-								log.Error(err, msg)
-								return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-							}
-							msg := fmt.Sprintf("namespace: %s, does not exist, retry...", remoteNamespace)
-							log.Info(msg)
-							return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-						}
-
-						newcr := cr.DeepCopy()
-						// we overwrite 2 annotations that have specific information
-						newcr.Annotations[nephioAppKey] = bootstrapApp
-						newcr.Annotations[clusterNameKey] = clusterName
-
-						newcr.ResourceVersion = ""
-						newcr.UID = ""
-						newcr.Namespace = remoteNamespace
-						log.Info("secret info", "secret", newcr.Annotations)
-						if err := clusterClient.Apply(ctx, newcr); err != nil {
-							msg := fmt.Sprintf("cannot apply secret to cluster %s", clusterName)
-							log.Error(err, msg)
-							return ctrl.Result{}, errors.Wrap(err, msg)
-						}
-					}
-				}
-				if found {
-					// speeds up the loop
-					break
-				}
-			}
-			// we need to find a cluster for each clusterName, so
-			// even in case of multiple
-			if !found {
-				// the cluster client was not found, we retry
-				log.Info("cluster client not found, retry...")
-				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	newStatus := syncStatus{}
+	errs := []string{}
+	// delete the copies from the clusters that are no longer selected
+	for clusterName, cs := range status {
+		if slices.Contains(clusterNames, clusterName) {
+			continue
+		}
+		deleted, err := r.deleteFromCluster(ctx, cr, clusterName, cs.Namespace)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Sprintf("cluster %s: %s", clusterName, err.Error()))
+			newStatus[clusterName] = clusterStatus{Namespace: cs.Namespace, State: stateDeleting, Message: err.Error()}
+		case !deleted:
+			newStatus[clusterName] = clusterStatus{Namespace: cs.Namespace, State: stateDeleting, Message: "cluster not ready"}
+		}
+	}
+	// each cluster is synced independently, a cluster that is not ready does
+	// not block the other clusters
+	for _, clusterName := range clusterNames {
+		// the copy moves when the remote namespace changed, the old copy is
+		// deleted first so it is not lost from the status
+		if old := status[clusterName]; old.Namespace != "" && old.Namespace != getRemoteNamespace(cr) {
+			deleted, err := r.deleteFromCluster(ctx, cr, clusterName, old.Namespace)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Sprintf("cluster %s: %s", clusterName, err.Error()))
+				newStatus[clusterName] = clusterStatus{Namespace: old.Namespace, State: stateDeleting, Message: err.Error()}
+				continue
+			case !deleted:
+				newStatus[clusterName] = clusterStatus{Namespace: old.Namespace, State: stateDeleting, Message: "cluster not ready"}
+				continue
 			}
 		}
+		cs := r.syncToCluster(ctx, cr, clusterName)
+		if cs.State == stateFailed {
+			errs = append(errs, fmt.Sprintf("cluster %s: %s", clusterName, cs.Message))
+		}
+		newStatus[clusterName] = cs
+	}
+
+	if err := r.patchSyncStatus(ctx, cr, newStatus); err != nil {
+		msg := "cannot update sync status"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	if len(clusterNames) == 0 && len(newStatus) == 0 {
+		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
+			msg := "cannot remove finalizer"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		return reconcile.Result{}, nil
+	}
+	if len(errs) > 0 {
+		msg := "cannot sync secret"
+		err := errors.New(strings.Join(errs, "; "))
+		log.Error(err, msg)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+	}
+	for _, cs := range newStatus {
+		if cs.State != stateSynced {
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+	// the copies are checked periodically, a copy that was deleted or changed
+	// on the remote cluster is applied again
+	requeueAfter := replication.CheckInterval(ctx)
+	if cr.GetAnnotations()[clusterSelectorKey] != "" {
+		requeueAfter = min(requeueAfter, resyncPeriod)
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// getClusterNames returns the names of the clusters the secret is installed
+// on, none when the secret is deleted or not to be installed on remote clusters
+func (r *reconciler) getClusterNames(ctx context.Context, cr *corev1.Secret) ([]string, error) {
+	if resource.WasDeleted(cr) || cr.GetAnnotations()[nephioAppKey] != syncApp {
+		return nil, nil
+	}
+	// a clusterName can be modelled as multiple clusters to allow
+	// the secret to be deployed on multiple clusters
+	// syntax: nephio.org/cluster-name = cluster01,cluster02,cluster03
+	names := sets.New[string]()
+	for _, name := range strings.Split(cr.GetAnnotations()[clusterNameKey], ",") {
+		names.Insert(strings.TrimSpace(name))
+	}
+	if s := cr.GetAnnotations()[clusterSelectorKey]; s != "" {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cluster selector %q", s)
+		}
+		selected, err := cluster.Registry{Client: r.Client}.ListClusterNames(ctx, selector)
+		if err != nil {
+			return nil, err
+		}
+		names.Insert(selected...)
+	}
+	names.Delete("", mgmtCluster)
+	return sets.List(names), nil
+}
+
+// getRemoteNamespace returns the namespace of the remote cluster on which the
+// secret is to be installed.
+// e.g. configsync requires it to be installed in configsync-management
+// but the mgmt cluster already has configsync-management in use, so
+// we need the ability to change the namespace of the remote cluster
+// for this secret
+// the controller uses the namespace of the cr by default and if the
+// remoteNamespace annotation `"nephio.org/remote-namespace"` is set
+// it will use the value of the  annotation as the remote namespace
+func getRemoteNamespace(cr *corev1.Secret) string {
+	if rns, ok := cr.GetAnnotations()[remoteNamespaceKey]; ok {
+		return rns
+	}
+	return cr.Namespace
+}
+
+// syncToCluster finds the credentials to access the remote cluster, if found
+// we check is the assigned namespace is available and if so we apply the
+// secret to the remote cluster. It returns the status of the cluster.
+func (r *reconciler) syncToCluster(ctx context.Context, cr *corev1.Secret, clusterName string) clusterStatus {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	remoteNamespace := getRemoteNamespace(cr)
+	pending := func(msg string) clusterStatus {
+		log.Info(msg)
+		return clusterStatus{Namespace: remoteNamespace, State: statePending, Message: msg}
+	}
+	failed := func(err error, msg string) clusterStatus {
+		log.Error(err, msg)
+		return clusterStatus{Namespace: remoteNamespace, State: stateFailed, Message: errors.Wrap(err, msg).Error()}
+	}
+
+	clusterClient, found, err := cluster.Registry{Client: r.Client}.GetClusterClient(ctx, clusterName)
+	if err != nil {
+		return failed(err, "cannot get cluster credentials")
+	}
+	if !found {
+		return pending("cluster client not found")
+	}
+	remoteClient, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		return failed(err, "cannot get clusterClient")
+	}
+	if !ready {
+		return pending("cluster not ready")
+	}
+
+	// check if the remote namespace exists, if not retry
+	exists, err := replication.NamespaceExists(ctx, remoteClient, remoteNamespace)
+	if err != nil {
+		return failed(err, fmt.Sprintf("cannot get namespace: %s", remoteNamespace))
+	}
+	if !exists {
+		return pending(fmt.Sprintf("namespace: %s, does not exist", remoteNamespace))
+	}
+
+	if _, err := r.replicator.Apply(ctx, remoteClient, clusterName, getRemoteSecret(cr, clusterName, remoteNamespace)); err != nil {
+		return failed(err, "cannot apply secret")
+	}
+	return clusterStatus{Namespace: remoteNamespace, State: stateSynced}
+}
+
+// getRemoteSecret returns the copy of the secret for the remote cluster
+func getRemoteSecret(cr *corev1.Secret, clusterName, remoteNamespace string) *corev1.Secret {
+	newcr := cr.DeepCopy()
+	// we overwrite 2 annotations that have specific information
+	newcr.Annotations[nephioAppKey] = bootstrapApp
+	newcr.Annotations[clusterNameKey] = clusterName
+	delete(newcr.Annotations, clusterSelectorKey)
+	delete(newcr.Annotations, syncStatusKey)
+	replication.ResetMetadata(newcr, remoteNamespace)
+	return newcr
+}
+
+// deleteFromCluster deletes the copy of the secret from the cluster, it
+// returns false when the cluster is not ready. A cluster without credentials
+// is considered gone together with the copy.
+func (r *reconciler) deleteFromCluster(ctx context.Context, cr *corev1.Secret, clusterName, remoteNamespace string) (bool, error) {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	clusterClient, found, err := cluster.Registry{Client: r.Client}.GetClusterClient(ctx, clusterName)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get cluster credentials")
+	}
+	if !found {
+		log.Info("cluster credentials not found, skip deleting secret")
+		return true, nil
+	}
+	remoteClient, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get clusterClient")
+	}
+	if !ready {
+		log.Info("cluster not ready")
+		return false, nil
+	}
+	if err := deleteRemoteSecret(ctx, remoteClient, cr.GetName(), remoteNamespace); err != nil {
+		return false, err
+	}
+	r.replicator.Forget(clusterName, "Secret", remoteNamespace, cr.GetName())
+	log.Info("secret deleted", "namespace", remoteNamespace)
+	return true, nil
+}
+
+// deleteRemoteSecret deletes the copy of the secret, a copy that was not
+// created by this controller is left alone
+func deleteRemoteSecret(ctx context.Context, c client.Client, name, namespace string) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), "cannot get secret")
+	}
+	if secret.GetAnnotations()[nephioAppKey] != bootstrapApp {
+		return nil
 	}
-	return ctrl.Result{}, nil
+	return errors.Wrap(resource.IgnoreNotFound(c.Delete(ctx, secret)), "cannot delete secret")
 }
@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapsecret
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestSecret(annotations map[string]string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "git-token", Annotations: annotations},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+}
+
+func TestGetClusterNames(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		deleted     bool
+		selected    []string
+		want        []string
+		wantErr     bool
+	}{
+		"NotSynced": {
+			annotations: map[string]string{clusterNameKey: "edge01"},
+		},
+		"ClusterNames": {
+			annotations: map[string]string{nephioAppKey: syncApp, clusterNameKey: "edge02, edge01,mgmt"},
+			want:        []string{"edge01", "edge02"},
+		},
+		"Mgmt": {
+			annotations: map[string]string{nephioAppKey: syncApp, clusterNameKey: "mgmt"},
+			want:        []string{},
+		},
+		"Selector": {
+			annotations: map[string]string{nephioAppKey: syncApp, clusterNameKey: "edge01", clusterSelectorKey: "region=west"},
+			selected:    []string{"edge01", "edge03"},
+			want:        []string{"edge01", "edge03"},
+		},
+		"InvalidSelector": {
+			annotations: map[string]string{nephioAppKey: syncApp, clusterSelectorKey: "region in west"},
+			wantErr:     true,
+		},
+		"Deleted": {
+			annotations: map[string]string{nephioAppKey: syncApp, clusterNameKey: "edge01"},
+			deleted:     true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := newTestSecret(tc.annotations)
+			if tc.deleted {
+				now := metav1.Now()
+				cr.SetDeletionTimestamp(&now)
+			}
+			selector, _ := labels.Parse("region=west")
+			clientMock := new(mocks.MockClient)
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList"), client.MatchingLabelsSelector{Selector: selector}).Return(&meta.NoKindMatchError{})
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.SecretList"), client.MatchingLabelsSelector{Selector: selector}).Return(nil).Run(func(args mock.Arguments) {
+				for _, name := range tc.selected {
+					args.Get(1).(*corev1.SecretList).Items = append(args.Get(1).(*corev1.SecretList).Items, corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: name + "-credentials", Labels: map[string]string{cluster.ClusterNameLabel: name}},
+					})
+				}
+			})
+
+			r := &reconciler{Client: clientMock}
+			got, err := r.getClusterNames(context.TODO(), cr)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetRemoteSecret(t *testing.T) {
+	cr := newTestSecret(map[string]string{
+		nephioAppKey:       syncApp,
+		clusterNameKey:     "edge01,edge02",
+		clusterSelectorKey: "region=west",
+		remoteNamespaceKey: "config-management-system",
+		syncStatusKey:      `{"edge01":{"namespace":"config-management-system","state":"Synced"}}`,
+	})
+	cr.SetFinalizers([]string{finalizer})
+	cr.SetResourceVersion("1")
+
+	got := getRemoteSecret(cr, "edge01", getRemoteNamespace(cr))
+	assert.Equal(t, "config-management-system", got.GetNamespace())
+	assert.Equal(t, map[string]string{
+		nephioAppKey:       bootstrapApp,
+		clusterNameKey:     "edge01",
+		remoteNamespaceKey: "config-management-system",
+	}, got.GetAnnotations())
+	assert.Empty(t, got.GetFinalizers())
+	assert.Empty(t, got.GetResourceVersion())
+	// the source secret is not modified
+	assert.Equal(t, syncApp, cr.GetAnnotations()[nephioAppKey])
+}
+
+func TestPatchSyncStatus(t *testing.T) {
+	cases := map[string]struct {
+		current   map[string]string
+		status    syncStatus
+		wantPatch bool
+	}{
+		"Unchanged": {
+			current: map[string]string{syncStatusKey: `{"edge01":{"namespace":"default","state":"Synced"}}`},
+			status:  syncStatus{"edge01": {Namespace: "default", State: stateSynced}},
+		},
+		"Changed": {
+			current:   map[string]string{syncStatusKey: `{"edge01":{"namespace":"default","state":"Pending"}}`},
+			status:    syncStatus{"edge01": {Namespace: "default", State: stateSynced}},
+			wantPatch: true,
+		},
+		"Removed": {
+			current:   map[string]string{syncStatusKey: `{"edge01":{"namespace":"default","state":"Synced"}}`},
+			status:    syncStatus{},
+			wantPatch: true,
+		},
+		"None": {
+			status: syncStatus{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			patched := false
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.Secret"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				patched = true
+			})
+
+			cr := newTestSecret(tc.current)
+			r := &reconciler{Client: clientMock}
+			assert.NoError(t, r.patchSyncStatus(context.TODO(), cr, tc.status))
+			assert.Equal(t, tc.wantPatch, patched)
+			assert.Equal(t, tc.status, getSyncStatus(cr))
+		})
+	}
+}
+
+func TestDeleteRemoteSecret(t *testing.T) {
+	cases := map[string]struct {
+		getErr     error
+		app        string
+		wantDelete bool
+	}{
+		"Copy": {
+			app:        bootstrapApp,
+			wantDelete: true,
+		},
+		"NotACopy": {
+			app: syncApp,
+		},
+		"NotFound": {
+			getErr: kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "git-token"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			remoteMock := new(mocks.MockClient)
+			remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "config-management-system", Name: "git-token"}, mock.AnythingOfType("*v1.Secret")).Return(tc.getErr).Run(func(args mock.Arguments) {
+				args.Get(2).(*corev1.Secret).SetAnnotations(map[string]string{nephioAppKey: tc.app})
+			})
+			remoteMock.On("Delete", context.TODO(), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				deleted = true
+			})
+
+			assert.NoError(t, deleteRemoteSecret(context.TODO(), remoteMock, "git-token", "config-management-system"))
+			assert.Equal(t, tc.wantDelete, deleted)
+		})
+	}
+}
+
+func TestReconcileDeleted(t *testing.T) {
+	status, _ := json.Marshal(syncStatus{"edge01": {Namespace: "default", State: stateSynced}})
+	cr := newTestSecret(map[string]string{nephioAppKey: syncApp, clusterNameKey: "edge01", syncStatusKey: string(status)})
+	now := metav1.Now()
+	cr.SetDeletionTimestamp(&now)
+	cr.SetFinalizers([]string{finalizer})
+
+	var updated *corev1.Secret
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "git-token"}, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		cr.DeepCopyInto(args.Get(2).(*corev1.Secret))
+	})
+	// the credentials of the cluster are gone, so is the copy of the secret
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*unstructured.UnstructuredList).Items = nil
+	})
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.SecretList"), client.MatchingFields{cluster.ClusterNameIndex: "edge01"}).Return(nil)
+	clientMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.Secret"), mock.Anything).Return(nil)
+	clientMock.On("Update", context.TODO(), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		updated = args.Get(1).(*corev1.Secret)
+	})
+
+	r := &reconciler{Client: clientMock, finalizer: resource.NewAPIFinalizer(clientMock, finalizer)}
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "git-token"}})
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	if assert.NotNil(t, updated) {
+		assert.Empty(t, updated.GetFinalizers())
+		assert.NotContains(t, updated.GetAnnotations(), syncStatusKey)
+	}
+}
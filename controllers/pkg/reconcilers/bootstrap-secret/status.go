@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapsecret
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	stateSynced   = "Synced"
+	statePending  = "Pending"
+	stateFailed   = "Failed"
+	stateDeleting = "Deleting"
+)
+
+// clusterStatus is the sync status of the secret on a cluster, the namespace
+// is recorded so the copy can be deleted when the secret no longer targets
+// the cluster or is deleted
+type clusterStatus struct {
+	Namespace string `json:"namespace"`
+	State     string `json:"state"`
+	Message   string `json:"message,omitempty"`
+}
+
+// syncStatus is the status of the secret per cluster name
+type syncStatus map[string]clusterStatus
+
+// getSyncStatus returns the sync status in the annotation of the secret, an
+// invalid annotation is treated as no status
+func getSyncStatus(cr *corev1.Secret) syncStatus {
+	status := syncStatus{}
+	if v, ok := cr.GetAnnotations()[syncStatusKey]; ok {
+		if err := json.Unmarshal([]byte(v), &status); err != nil {
+			return syncStatus{}
+		}
+	}
+	return status
+}
+
+// patchSyncStatus writes the sync status in the annotation of the secret when
+// it changed, the annotation is removed when there is no status
+func (r *reconciler) patchSyncStatus(ctx context.Context, cr *corev1.Secret, status syncStatus) error {
+	if maps.Equal(getSyncStatus(cr), status) {
+		return nil
+	}
+	patch := client.MergeFrom(cr.DeepCopy())
+	annotations := cr.GetAnnotations()
+	if len(status) == 0 {
+		delete(annotations, syncStatusKey)
+	} else {
+		b, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[syncStatusKey] = string(b)
+	}
+	cr.SetAnnotations(annotations)
+	return r.Patch(ctx, cr, patch)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSummaryEntries limits the amount of differences reported in the
+	// condition message, the total amount of differences is always reported
+	maxSummaryEntries = 5
+)
+
+// listKeys are the leaf names used to identify an entry in a RFC7951 list.
+// The intended config does not carry the yang schema, so list entries are
+// matched on the first key that is present, or on their position otherwise.
+var listKeys = []string{"name", "index", "id", "sequence-id"}
+
+// Difference describes a single path where the running config deviates from
+// the intended config.
+type Difference struct {
+	Path     string
+	Intended any
+	Running  any
+}
+
+func (r Difference) String() string {
+	if r.Running == nil {
+		return fmt.Sprintf("%s: missing, intended %s", r.Path, toString(r.Intended))
+	}
+	return fmt.Sprintf("%s: intended %s, running %s", r.Path, toString(r.Intended), toString(r.Running))
+}
+
+// Diff compares the intended RFC7951 json config with the running json config
+// and returns the paths of the intended config that are missing or different
+// in the running config. Paths that match one of the ignorePaths prefixes are
+// skipped. Config that only exists in the running config is not reported given
+// devices carry a lot of default config that is not part of the intent.
+func Diff(intended, running []byte, ignorePaths []string) ([]Difference, error) {
+	var i, r any
+	if err := json.Unmarshal(intended, &i); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal intended config: %w", err)
+	}
+	if len(running) == 0 {
+		running = []byte("{}")
+	}
+	if err := json.Unmarshal(running, &r); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal running config: %w", err)
+	}
+	d := &differ{ignorePaths: normalizePaths(ignorePaths)}
+	d.compare("", i, r)
+	return d.diffs, nil
+}
+
+// Summarize returns a human readable summary of the differences, suitable to
+// be used in a condition message.
+func Summarize(diffs []Difference) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+	entries := []string{}
+	for i, d := range diffs {
+		if i == maxSummaryEntries {
+			entries = append(entries, fmt.Sprintf("(+%d more)", len(diffs)-maxSummaryEntries))
+			break
+		}
+		entries = append(entries, d.String())
+	}
+	return fmt.Sprintf("%d difference(s): %s", len(diffs), strings.Join(entries, "; "))
+}
+
+type differ struct {
+	ignorePaths []string
+	diffs       []Difference
+}
+
+func (r *differ) compare(path string, intended, running any) {
+	if r.isIgnored(path) {
+		return
+	}
+	switch i := intended.(type) {
+	case map[string]any:
+		rm, ok := running.(map[string]any)
+		if !ok {
+			r.add(path, intended, running)
+			return
+		}
+		for _, k := range sortedKeys(i) {
+			r.compare(path+"/"+k, i[k], rm[k])
+		}
+	case []any:
+		ra, ok := running.([]any)
+		if !ok {
+			r.add(path, intended, running)
+			return
+		}
+		for idx, entry := range i {
+			key, ok := listEntryKey(entry)
+			if !ok {
+				// leaf-list or list without a known key -> match on position
+				var re any
+				if idx < len(ra) {
+					re = ra[idx]
+				}
+				if _, isMap := entry.(map[string]any); isMap {
+					r.compare(fmt.Sprintf("%s[%d]", path, idx), entry, re)
+					continue
+				}
+				if !containsValue(ra, entry) {
+					r.add(fmt.Sprintf("%s[%d]", path, idx), entry, nil)
+				}
+				continue
+			}
+			r.compare(path+key, entry, findListEntry(ra, key))
+		}
+	default:
+		if !equalLeaf(intended, running) {
+			r.add(path, intended, running)
+		}
+	}
+}
+
+func (r *differ) add(path string, intended, running any) {
+	if path == "" {
+		path = "/"
+	}
+	r.diffs = append(r.diffs, Difference{Path: path, Intended: intended, Running: running})
+}
+
+func (r *differ) isIgnored(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, p := range r.ignorePaths {
+		if path == p || strings.HasPrefix(path, p+"/") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// listEntryKey returns the key selector of a list entry, e.g. [name=ethernet-1/1]
+func listEntryKey(entry any) (string, bool) {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	for _, k := range listKeys {
+		if v, ok := m[k]; ok {
+			return fmt.Sprintf("[%s=%s]", k, toString(v)), true
+		}
+	}
+	return "", false
+}
+
+func findListEntry(entries []any, key string) any {
+	for _, e := range entries {
+		if k, ok := listEntryKey(e); ok && k == key {
+			return e
+		}
+	}
+	return nil
+}
+
+func containsValue(entries []any, v any) bool {
+	for _, e := range entries {
+		if equalLeaf(v, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalLeaf compares leaf values; RFC7951 encodes 64 bit numbers as strings
+// so numbers and strings are compared on their string representation.
+func equalLeaf(intended, running any) bool {
+	if reflect.DeepEqual(intended, running) {
+		return true
+	}
+	if intended == nil || running == nil {
+		return false
+	}
+	return toString(intended) == toString(running)
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return "<nil>"
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func normalizePaths(paths []string) []string {
+	np := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		np = append(np, strings.TrimSuffix(p, "/"))
+	}
+	return np
+}
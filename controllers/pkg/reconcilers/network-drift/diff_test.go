@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	intended := `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "enable", "mtu": 9232,
+     "subinterface": [{"index": 10, "vlan": {"encap": {"single-tagged": {"vlan-id": 10}}}}]}
+  ],
+  "srl_nokia-network-instance:network-instance": [
+    {"name": "vpc-1", "type": "srl_nokia-network-instance:mac-vrf"}
+  ]
+}`
+
+	cases := map[string]struct {
+		running     string
+		ignorePaths []string
+		want        []string
+		wantErr     bool
+	}{
+		"InSync": {
+			running: `{
+  "srl_nokia-system:system": {"name": {"host-name": "leaf1"}},
+  "srl_nokia-interfaces:interface": [
+    {"name": "mgmt0", "admin-state": "enable"},
+    {"name": "ethernet-1/1", "admin-state": "enable", "mtu": "9232",
+     "subinterface": [{"index": 10, "vlan": {"encap": {"single-tagged": {"vlan-id": 10}}}}]}
+  ],
+  "srl_nokia-network-instance:network-instance": [
+    {"name": "vpc-1", "type": "srl_nokia-network-instance:mac-vrf"}
+  ]
+}`,
+			want: []string{},
+		},
+		"ChangedLeaf": {
+			running: `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "disable", "mtu": 9232,
+     "subinterface": [{"index": 10, "vlan": {"encap": {"single-tagged": {"vlan-id": 10}}}}]}
+  ],
+  "srl_nokia-network-instance:network-instance": [
+    {"name": "vpc-1", "type": "srl_nokia-network-instance:mac-vrf"}
+  ]
+}`,
+			want: []string{"/srl_nokia-interfaces:interface[name=ethernet-1/1]/admin-state"},
+		},
+		"MissingListEntry": {
+			running: `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "enable", "mtu": 9232}
+  ]
+}`,
+			want: []string{
+				"/srl_nokia-interfaces:interface[name=ethernet-1/1]/subinterface",
+				"/srl_nokia-network-instance:network-instance",
+			},
+		},
+		"Ignored": {
+			running: `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "disable", "mtu": 1500,
+     "subinterface": [{"index": 10, "vlan": {"encap": {"single-tagged": {"vlan-id": 10}}}}]}
+  ]
+}`,
+			ignorePaths: []string{
+				"srl_nokia-interfaces:interface[name=ethernet-1/1]/admin-state",
+				" /srl_nokia-interfaces:interface[name=ethernet-1/1]/mtu ",
+				"/srl_nokia-network-instance:network-instance/",
+			},
+			want: []string{},
+		},
+		"EmptyRunning": {
+			running: ``,
+			want: []string{
+				"/srl_nokia-interfaces:interface",
+				"/srl_nokia-network-instance:network-instance",
+			},
+		},
+		"InvalidRunning": {
+			running: `{`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diffs, err := Diff([]byte(intended), []byte(tc.running), tc.ignorePaths)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Diff() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got := []string{}
+			for _, d := range diffs {
+				got = append(got, d.Path)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	diffs := []Difference{}
+	for i := 0; i < maxSummaryEntries+2; i++ {
+		diffs = append(diffs, Difference{Path: "/a", Intended: "x", Running: "y"})
+	}
+	got := Summarize(diffs)
+	if !strings.HasPrefix(got, "7 difference(s): /a: intended x, running y") {
+		t.Errorf("unexpected summary: %s", got)
+	}
+	if !strings.HasSuffix(got, "(+2 more)") {
+		t.Errorf("expected truncated summary, got: %s", got)
+	}
+	if Summarize(nil) != "" {
+		t.Errorf("expected empty summary for no differences")
+	}
+}
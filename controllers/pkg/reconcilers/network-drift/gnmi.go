@@ -0,0 +1,204 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	gnmiTimeout     = 30 * time.Second
+	defaultGnmiPort = "57400"
+)
+
+// gnmiReader reads and writes the config of a target using gnmi Get and Set
+// with JSON_IETF encoding on the root path. The intended config is partial, it
+// is merged with a Set update so config that only exists on the target, e.g.
+// system or management config, is kept.
+type gnmiReader struct{}
+
+func (r *gnmiReader) GetRunningConfig(ctx context.Context, target *invv1alpha1.Target, secret *corev1.Secret) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gnmiTimeout)
+	defer cancel()
+
+	conn, err := dial(target)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rsp, err := gnmipb.NewGNMIClient(conn).Get(withCredentials(ctx, secret), &gnmipb.GetRequest{
+		Path:     []*gnmipb.Path{{}},
+		Type:     gnmipb.GetRequest_CONFIG,
+		Encoding: gnmipb.Encoding_JSON_IETF,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gnmi get failed for target %s: %w", target.GetName(), err)
+	}
+	return mergeNotifications(rsp.GetNotification())
+}
+
+func (r *gnmiReader) SetConfig(ctx context.Context, target *invv1alpha1.Target, secret *corev1.Secret, config []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, gnmiTimeout)
+	defer cancel()
+
+	conn, err := dial(target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := gnmipb.NewGNMIClient(conn).Set(withCredentials(ctx, secret), &gnmipb.SetRequest{
+		Update: []*gnmipb.Update{{
+			Path: &gnmipb.Path{},
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: config}},
+		}},
+	}); err != nil {
+		return fmt.Errorf("gnmi set failed for target %s: %w", target.GetName(), err)
+	}
+	return nil
+}
+
+func dial(target *invv1alpha1.Target) (*grpc.ClientConn, error) {
+	if target.Spec.Address == nil || *target.Spec.Address == "" {
+		return nil, fmt.Errorf("target %s has no address", target.GetName())
+	}
+	address := *target.Spec.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = fmt.Sprintf("%s:%s", address, defaultGnmiPort)
+	}
+
+	creds := insecure.NewCredentials()
+	if !ptr.Deref(target.Spec.Insecure, false) {
+		creds = credentials.NewTLS(&tls.Config{
+			InsecureSkipVerify: ptr.Deref(target.Spec.SkipVerify, false), // #nosec G402 -- controlled by the target spec
+		})
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create gnmi client for target %s: %w", target.GetName(), err)
+	}
+	return conn, nil
+}
+
+func withCredentials(ctx context.Context, secret *corev1.Secret) context.Context {
+	if secret == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		"username", string(secret.Data["username"]),
+		"password", string(secret.Data["password"]))
+}
+
+// mergeNotifications merges the json values of all updates into a single json
+// document, updates on a non root path are nested according to their path.
+func mergeNotifications(notifications []*gnmipb.Notification) ([]byte, error) {
+	root := map[string]any{}
+	for _, n := range notifications {
+		for _, u := range n.GetUpdate() {
+			b := u.GetVal().GetJsonIetfVal()
+			if b == nil {
+				b = u.GetVal().GetJsonVal()
+			}
+			if b == nil {
+				continue
+			}
+			var v any
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal gnmi update: %w", err)
+			}
+			elems := append([]*gnmipb.PathElem{}, n.GetPrefix().GetElem()...)
+			elems = append(elems, u.GetPath().GetElem()...)
+			merge(root, elems, v)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func merge(root map[string]any, elems []*gnmipb.PathElem, v any) {
+	if len(elems) == 0 {
+		if m, ok := v.(map[string]any); ok {
+			for k, e := range m {
+				root[k] = e
+			}
+		}
+		return
+	}
+	name := elems[0].GetName()
+	keys := elems[0].GetKey()
+	if len(keys) == 0 {
+		if len(elems) == 1 {
+			root[name] = v
+			return
+		}
+		child, ok := root[name].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			root[name] = child
+		}
+		merge(child, elems[1:], v)
+		return
+	}
+	// a keyed path element is an entry of a json list, a new entry carries its
+	// key leaves so the entries of the list stay apart
+	entries, _ := root[name].([]any)
+	entry := findKeyedEntry(entries, keys)
+	if entry == nil {
+		entry = map[string]any{}
+		for k, kv := range keys {
+			entry[k] = kv
+		}
+		root[name] = append(entries, entry)
+	}
+	merge(entry, elems[1:], v)
+}
+
+// findKeyedEntry returns the entry of the list whose key leaves match the keys
+// of a path element, nil when there is none
+func findKeyedEntry(entries []any, keys map[string]string) map[string]any {
+	for _, e := range entries {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		match := true
+		for k, kv := range keys {
+			if toString(m[k]) != kv {
+				match = false
+				break
+			}
+		}
+		if match {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"k8s.io/utils/ptr"
+)
+
+// fakeDevice is a gnmi server holding the running config of a device, a
+// replace on the root path overwrites the config, an update merges into it.
+type fakeDevice struct {
+	gnmipb.UnimplementedGNMIServer
+	mu      sync.Mutex
+	running map[string]any
+}
+
+func (r *fakeDevice) Get(_ context.Context, _ *gnmipb.GetRequest) (*gnmipb.GetResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.Marshal(r.running)
+	if err != nil {
+		return nil, err
+	}
+	return &gnmipb.GetResponse{Notification: []*gnmipb.Notification{{
+		Update: []*gnmipb.Update{{
+			Path: &gnmipb.Path{},
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: b}},
+		}},
+	}}}, nil
+}
+
+func (r *fakeDevice) Set(_ context.Context, req *gnmipb.SetRequest) (*gnmipb.SetResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range req.GetReplace() {
+		v := map[string]any{}
+		if err := json.Unmarshal(u.GetVal().GetJsonIetfVal(), &v); err != nil {
+			return nil, err
+		}
+		r.running = v
+	}
+	for _, u := range req.GetUpdate() {
+		v := map[string]any{}
+		if err := json.Unmarshal(u.GetVal().GetJsonIetfVal(), &v); err != nil {
+			return nil, err
+		}
+		mergeConfig(r.running, v)
+	}
+	return &gnmipb.SetResponse{}, nil
+}
+
+func mergeConfig(running, update map[string]any) {
+	for k, v := range update {
+		switch v := v.(type) {
+		case map[string]any:
+			child, ok := running[k].(map[string]any)
+			if !ok {
+				running[k] = v
+				continue
+			}
+			mergeConfig(child, v)
+		case []any:
+			entries, _ := running[k].([]any)
+			for _, e := range v {
+				key, ok := listEntryKey(e)
+				if !ok {
+					entries = append(entries, e)
+					continue
+				}
+				existing, ok := findListEntry(entries, key).(map[string]any)
+				if !ok {
+					entries = append(entries, e)
+					continue
+				}
+				mergeConfig(existing, e.(map[string]any))
+			}
+			running[k] = entries
+		default:
+			running[k] = v
+		}
+	}
+}
+
+func TestSetConfigKeepsConfigOutsideIntent(t *testing.T) {
+	running := `{
+  "srl_nokia-system:system": {"name": {"host-name": "leaf1"}},
+  "srl_nokia-interfaces:interface": [
+    {"name": "mgmt0", "admin-state": "enable"},
+    {"name": "ethernet-1/1", "admin-state": "disable", "mtu": 1500}
+  ]
+}`
+	intended := `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "enable", "mtu": 9232}
+  ],
+  "srl_nokia-network-instance:network-instance": [
+    {"name": "vpc-1", "type": "srl_nokia-network-instance:mac-vrf"}
+  ]
+}`
+
+	device := &fakeDevice{running: map[string]any{}}
+	if err := json.Unmarshal([]byte(running), &device.running); err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	gnmipb.RegisterGNMIServer(srv, device)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	target := &invv1alpha1.Target{Spec: invv1alpha1.TargetSpec{
+		Address:  ptr.To(lis.Addr().String()),
+		Insecure: ptr.To(true),
+	}}
+	reader := &gnmiReader{}
+	if err := reader.SetConfig(context.Background(), target, nil, []byte(intended)); err != nil {
+		t.Fatalf("SetConfig() unexpected error: %v", err)
+	}
+	got, err := reader.GetRunningConfig(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("GetRunningConfig() unexpected error: %v", err)
+	}
+
+	diffs, err := Diff([]byte(intended), got, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("config not remediated: %s", Summarize(diffs))
+	}
+	// the config outside the intent is still on the device
+	diffs, err = Diff([]byte(running), got, []string{"/srl_nokia-interfaces:interface[name=ethernet-1/1]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("config outside the intent removed: %s", Summarize(diffs))
+	}
+}
+
+func TestMergeNotificationsKeyedUpdates(t *testing.T) {
+	update := func(name, val string) *gnmipb.Update {
+		return &gnmipb.Update{
+			Path: &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "srl_nokia-interfaces:interface", Key: map[string]string{"name": name}}}},
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(val)}},
+		}
+	}
+	notifications := []*gnmipb.Notification{{
+		Update: []*gnmipb.Update{
+			update("ethernet-1/1", `{"admin-state": "enable", "mtu": 9232}`),
+			update("ethernet-1/2", `{"admin-state": "disable"}`),
+		},
+	}, {
+		Update: []*gnmipb.Update{{
+			Path: &gnmipb.Path{Elem: []*gnmipb.PathElem{
+				{Name: "srl_nokia-interfaces:interface", Key: map[string]string{"name": "ethernet-1/1"}},
+				{Name: "description"},
+			}},
+			Val: &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`"uplink"`)}},
+		}},
+	}}
+	want := `{
+  "srl_nokia-interfaces:interface": [
+    {"name": "ethernet-1/1", "admin-state": "enable", "mtu": 9232, "description": "uplink"},
+    {"name": "ethernet-1/2", "admin-state": "disable"}
+  ]
+}`
+
+	got, err := mergeNotifications(notifications)
+	if err != nil {
+		t.Fatalf("mergeNotifications() unexpected error: %v", err)
+	}
+	diffs, err := Diff([]byte(want), got, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("keyed updates not merged into list entries: %s\n%s", Summarize(diffs), got)
+	}
+	// the running config has no entries the intent does not have
+	diffs, err = Diff(got, []byte(want), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("unexpected config merged: %s\n%s", Summarize(diffs), got)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"context"
+
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// A ConfigReader reads the running config from a network target and pushes
+// the intended config to it when remediation is enabled.
+type ConfigReader interface {
+	// GetRunningConfig returns the running config of the target as RFC7951 json
+	GetRunningConfig(ctx context.Context, target *invv1alpha1.Target, secret *corev1.Secret) ([]byte, error)
+	// SetConfig merges the supplied RFC7951 json into the config of the target
+	SetConfig(ctx context.Context, target *invv1alpha1.Target, secret *corev1.Secret, config []byte) error
+}
+
+// Readers holds the config readers per target provider, targets with a
+// provider that is not registered use the gnmi reader.
+var Readers = map[string]ConfigReader{}
+
+// RegisterReader registers a ConfigReader for a target provider.
+func RegisterReader(provider string, r ConfigReader) {
+	Readers[provider] = r
+}
+
+func getReader(provider string) ConfigReader {
+	if r, ok := Readers[provider]; ok {
+		return r
+	}
+	return &gnmiReader{}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkdrift
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	reconcilerinterface.Register("networkdrift", &reconciler{})
+}
+
+const (
+	// IgnorePathsKey holds a comma separated list of config paths that are
+	// excluded from drift detection, e.g. /srl_nokia-system:system
+	IgnorePathsKey = "nephio.org/drift-ignore-paths"
+	// RemediateKey enables the re-push of the intended config when drift is detected
+	RemediateKey = "nephio.org/drift-remediate"
+
+	ConditionTypeDrifted configv1alpha1.ConditionType = "Drifted"
+
+	ConditionReasonDrifted        configv1alpha1.ConditionReason = "Drifted"
+	ConditionReasonInSync         configv1alpha1.ConditionReason = "InSync"
+	ConditionReasonRemediated     configv1alpha1.ConditionReason = "Remediated"
+	ConditionReasonTargetNotFound configv1alpha1.ConditionReason = "TargetNotFound"
+	ConditionReasonReadFailed     configv1alpha1.ConditionReason = "ReadFailed"
+
+	defaultPollInterval = 5 * time.Minute
+	// errors
+	errGetCr        = "cannot get cr"
+	errUpdateStatus = "cannot update status"
+)
+
+//+kubebuilder:rbac:groups=config.nephio.org,resources=networks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.nephio.org,resources=networks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=targets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
+
+	if err := configv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := invv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.Client = mgr.GetClient()
+	r.pollInterval = defaultPollInterval
+	if cfg.Poll != 0 {
+		r.pollInterval = cfg.Poll
+	}
+
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("NetworkDriftController").
+		For(&configv1alpha1.Network{}).
+		Complete(r)
+}
+
+type reconciler struct {
+	client.Client
+	pollInterval time.Duration
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconcile", "req", req)
+
+	cr := &configv1alpha1.Network{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// if the resource no longer exists the reconcile loop is done
+		if resource.IgnoreNotFound(err) != nil {
+			log.Error(err, errGetCr)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetCr)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if resource.WasDeleted(cr) || len(cr.Spec.Config.Raw) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	nodeName, ok := cr.GetLabels()[invv1alpha1.NephioNodeNameKey]
+	if !ok {
+		log.Info("network config has no node name label, skip drift detection")
+		return ctrl.Result{}, nil
+	}
+
+	target, secret, err := r.getTarget(ctx, cr.GetNamespace(), nodeName)
+	if err != nil {
+		log.Error(err, "cannot get target", "nodeName", nodeName)
+		cr.SetConditions(unknown(ConditionReasonTargetNotFound, err.Error()))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	reader := getReader(target.Spec.Provider)
+	running, err := reader.GetRunningConfig(ctx, target, secret)
+	if err != nil {
+		log.Error(err, "cannot read running config", "target", target.GetName())
+		cr.SetConditions(unknown(ConditionReasonReadFailed, err.Error()))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	diffs, err := Diff(cr.Spec.Config.Raw, running, getIgnorePaths(cr))
+	if err != nil {
+		log.Error(err, "cannot compare running config", "target", target.GetName())
+		cr.SetConditions(unknown(ConditionReasonReadFailed, err.Error()))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	if len(diffs) == 0 {
+		cr.SetConditions(inSync())
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	summary := Summarize(diffs)
+	log.Info("running config drifted", "target", target.GetName(), "diff", summary)
+	if cr.GetAnnotations()[RemediateKey] != "true" {
+		cr.SetConditions(drifted(summary))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	if err := reader.SetConfig(ctx, target, secret, cr.Spec.Config.Raw); err != nil {
+		log.Error(err, "cannot remediate running config", "target", target.GetName())
+		cr.SetConditions(drifted(fmt.Sprintf("remediation failed: %s, %s", err.Error(), summary)))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	log.Info("running config remediated", "target", target.GetName())
+	cr.SetConditions(remediated(summary))
+	return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
+
+// getTarget returns the target with the name of the node and the secret
+// holding the credentials to connect to it.
+func (r *reconciler) getTarget(ctx context.Context, namespace, nodeName string) (*invv1alpha1.Target, *corev1.Secret, error) {
+	target := &invv1alpha1.Target{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: nodeName}, target); err != nil {
+		return nil, nil, err
+	}
+	if target.Spec.SecretName == "" {
+		return target, nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: target.Spec.SecretName}, secret); err != nil {
+		return nil, nil, err
+	}
+	return target, secret, nil
+}
+
+func getIgnorePaths(cr *configv1alpha1.Network) []string {
+	paths, ok := cr.GetAnnotations()[IgnorePathsKey]
+	if !ok {
+		return nil
+	}
+	return strings.Split(paths, ",")
+}
+
+func drifted(msg string) configv1alpha1.Condition {
+	return configv1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeDrifted),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(ConditionReasonDrifted),
+		Message:            msg,
+	}}
+}
+
+func inSync() configv1alpha1.Condition {
+	return configv1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeDrifted),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(ConditionReasonInSync),
+	}}
+}
+
+func remediated(msg string) configv1alpha1.Condition {
+	return configv1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeDrifted),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(ConditionReasonRemediated),
+		Message:            msg,
+	}}
+}
+
+func unknown(reason configv1alpha1.ConditionReason, msg string) configv1alpha1.Condition {
+	return configv1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeDrifted),
+		Status:             metav1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(reason),
+		Message:            msg,
+	}}
+}
@@ -0,0 +1,460 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/henderiw-nephio/network/pkg/device"
+	"github.com/henderiw-nephio/network/pkg/vlan"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/pkg/errors"
+	"github.com/srl-labs/ygotsrl/v22"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// fabricASNBaseKey overrides the base of the per node underlay ASN pool,
+	// the asn of a node is the base + the id claimed for the node
+	fabricASNBaseKey = "nephio.org/fabric-asn-base"
+	// fabricOverlayASNKey overrides the ASN of the evpn overlay, which is also
+	// used as the administrator of the route targets
+	fabricOverlayASNKey = "nephio.org/fabric-overlay-asn"
+	// fabricClaimsKey records the claims of the fabric on its claim indexes,
+	// such that the claims of removed nodes and network instances are released
+	fabricClaimsKey = "nephio.org/fabric-claims"
+
+	// the node and overlay ASNs are private ASNs (RFC 6996), the node ASNs are
+	// claimed above the base and stay in the private range of the base
+	defaultASNBase    = 64512
+	defaultOverlayASN = 65534
+	minPrivateASN     = 64512
+	maxPrivateASN     = 65534
+	minPrivateASN4    = 4200000000
+	maxPrivateASN4    = 4294967294
+
+	defaultNetworkInstanceName = "default"
+	underlayGroupName          = "underlay"
+	overlayGroupName           = "overlay"
+	vxlanTunnelInterfaceName   = "vxlan0"
+	bgpInstanceID              = 1
+)
+
+// getFabricIndexes returns the claim indexes from which the per node ASNs and
+// the per network instance route targets are claimed. We reuse the vlan
+// backend since it provides stable claims of ids in the 1-4094 range, which
+// is plenty for the nodes of a fabric and the evi/vni of the network instances
+func getFabricIndexes(cr client.Object, v vlan.VLAN) []client.Object {
+	return []client.Object{
+		v.ClaimVLANDB(cr, getASNIndexName(cr)),
+		v.ClaimVLANDB(cr, getRTIndexName(cr)),
+	}
+}
+
+func getASNIndexName(cr client.Object) string {
+	return fmt.Sprintf("%s-asn", cr.GetName())
+}
+
+func getRTIndexName(cr client.Object) string {
+	return fmt.Sprintf("%s-rt", cr.GetName())
+}
+
+// fabric adds the fabric routing config to the devices: an ebgp underlay with
+// a per node ASN, an evpn overlay between the system addresses of the nodes
+// and the evpn config (evi, vxlan, rd and rt) of the ip-vrf and mac-vrf network
+// instances
+type fabric struct {
+	cr         *infrav1alpha1.Network
+	vlan       vlan.VLAN
+	asnBase    uint32
+	overlayASN uint32
+	// claims are the names of the claims per index made by addRouting
+	claims map[string][]string
+}
+
+func newFabric(cr *infrav1alpha1.Network, v vlan.VLAN) (*fabric, error) {
+	asnBase, err := getASNAnnotation(cr, fabricASNBaseKey, defaultASNBase)
+	if err != nil {
+		return nil, err
+	}
+	overlayASN, err := getASNAnnotation(cr, fabricOverlayASNKey, defaultOverlayASN)
+	if err != nil {
+		return nil, err
+	}
+	return &fabric{
+		cr:         cr,
+		vlan:       v,
+		asnBase:    asnBase,
+		overlayASN: overlayASN,
+		claims:     map[string][]string{},
+	}, nil
+}
+
+func getASNAnnotation(cr client.Object, key string, defaultASN uint32) (uint32, error) {
+	v, ok := cr.GetAnnotations()[key]
+	if !ok {
+		return defaultASN, nil
+	}
+	asn, err := strconv.ParseUint(v, 10, 32)
+	if err != nil || !isPrivateASN(uint32(asn)) {
+		return 0, fmt.Errorf("invalid asn %q in annotation %s, expected a private asn in %d-%d or %d-%d",
+			v, key, minPrivateASN, maxPrivateASN, minPrivateASN4, maxPrivateASN4)
+	}
+	return uint32(asn), nil
+}
+
+func isPrivateASN(asn uint32) bool {
+	return (asn >= minPrivateASN && asn <= maxPrivateASN) || (asn >= minPrivateASN4 && asn <= maxPrivateASN4)
+}
+
+// getMaxNodeASN returns the end of the private range of the base
+func getMaxNodeASN(base uint32) uint32 {
+	if base <= maxPrivateASN {
+		return maxPrivateASN
+	}
+	return maxPrivateASN4
+}
+
+// fabricNode is the routing context of a node in the fabric
+type fabricNode struct {
+	name   string
+	device device.Device
+	asn    uint32
+	// routerID is the ipv4 address of the system interface, which is also
+	// used as the overlay peer address
+	routerID string
+}
+
+type linkAddress struct {
+	nodeName string
+	address  netip.Addr
+}
+
+func (r *fabric) addRouting(ctx context.Context, devices map[string]*ygotsrl.Device) error {
+	nodeNames := make([]string, 0, len(devices))
+	for nodeName := range devices {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	nodes := map[string]*fabricNode{}
+	for _, nodeName := range nodeNames {
+		asn, err := r.claimASN(ctx, nodeName)
+		if err != nil {
+			return err
+		}
+		d := device.Device{Device: devices[nodeName]}
+		n := &fabricNode{
+			name:   nodeName,
+			device: d,
+			asn:    asn,
+		}
+		for _, pfx := range getSubInterfacePrefixes(d, device.SystemInterfaceName, 0) {
+			if pfx.Addr().Is4() {
+				n.routerID = pfx.Addr().String()
+				break
+			}
+		}
+		nodes[nodeName] = n
+	}
+
+	// the link addresses of the default network instance are used to find
+	// the underlay peers: the other addresses in the same subnet
+	subnets := map[netip.Prefix][]linkAddress{}
+	for _, nodeName := range nodeNames {
+		for _, pfx := range getNetworkInstanceLinkPrefixes(nodes[nodeName].device, defaultNetworkInstanceName) {
+			subnets[pfx.Masked()] = append(subnets[pfx.Masked()], linkAddress{nodeName: nodeName, address: pfx.Addr()})
+		}
+	}
+
+	for _, nodeName := range nodeNames {
+		n := nodes[nodeName]
+		bgp := getDefaultBGP(n.device)
+		if bgp == nil {
+			// no default routing table in the network
+			continue
+		}
+		bgp.AutonomousSystem = ygot.Uint32(n.asn)
+		if n.routerID != "" {
+			bgp.RouterId = ygot.String(n.routerID)
+		}
+
+		for _, peers := range subnets {
+			local := false
+			for _, p := range peers {
+				if p.nodeName == nodeName {
+					local = true
+				}
+			}
+			if !local {
+				continue
+			}
+			for _, p := range peers {
+				if p.nodeName == nodeName {
+					continue
+				}
+				nb := bgp.GetOrCreateNeighbor(p.address.String())
+				nb.PeerGroup = ygot.String(underlayGroupName)
+				nb.PeerAs = ygot.Uint32(nodes[p.nodeName].asn)
+			}
+		}
+
+		// the overlay is a full mesh of ibgp sessions between the system
+		// addresses, using the overlay asn as local asn
+		if n.routerID == "" {
+			continue
+		}
+		overlay := bgp.GetOrCreateGroup(overlayGroupName)
+		overlay.PeerAs = ygot.Uint32(r.overlayASN)
+		overlay.GetOrCreateLocalAs(r.overlayASN)
+		overlay.Transport = &ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_Bgp_Group_Transport{
+			LocalAddress: ygot.String(n.routerID),
+		}
+		for _, peerName := range nodeNames {
+			if peerName == nodeName || nodes[peerName].routerID == "" {
+				continue
+			}
+			nb := bgp.GetOrCreateNeighbor(nodes[peerName].routerID)
+			nb.PeerGroup = ygot.String(overlayGroupName)
+		}
+	}
+
+	for _, nodeName := range nodeNames {
+		if err := r.addEVPN(ctx, nodes[nodeName]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEVPN adds the evpn config to the ip-vrf and mac-vrf network instances of
+// the node. The id claimed for the network instance is used as evi, vni and
+// the value of the route target and route distinguisher, such that the same
+// network instance gets the same id on all nodes
+func (r *fabric) addEVPN(ctx context.Context, n *fabricNode) error {
+	niNames := make([]string, 0, len(n.device.NetworkInstance))
+	for niName := range n.device.NetworkInstance {
+		niNames = append(niNames, niName)
+	}
+	sort.Strings(niNames)
+
+	for _, niName := range niNames {
+		ni := n.device.NetworkInstance[niName]
+		var siType ygotsrl.E_SrlNokiaInterfaces_SiType
+		switch ni.Type {
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf:
+			siType = ygotsrl.SrlNokiaInterfaces_SiType_bridged
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_ip_vrf:
+			siType = ygotsrl.SrlNokiaInterfaces_SiType_routed
+		default:
+			continue
+		}
+		id, err := r.claimRT(ctx, niName)
+		if err != nil {
+			return err
+		}
+		vxlanItfceName := fmt.Sprintf("%s.%d", vxlanTunnelInterfaceName, id)
+
+		vxlanItfce := n.device.GetOrCreateTunnelInterface(vxlanTunnelInterfaceName).GetOrCreateVxlanInterface(id)
+		vxlanItfce.Type = siType
+		vxlanItfce.Ingress = &ygotsrl.SrlNokiaTunnelInterfaces_TunnelInterface_VxlanInterface_Ingress{
+			Vni: ygot.Uint32(id),
+		}
+		ni.GetOrCreateVxlanInterface(vxlanItfceName)
+
+		bgpEvpnBgpInstance := ni.GetOrCreateProtocols().GetOrCreateBgpEvpn().GetOrCreateBgpInstance(bgpInstanceID)
+		bgpEvpnBgpInstance.AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+		bgpEvpnBgpInstance.Evi = ygot.Uint32(id)
+		bgpEvpnBgpInstance.Ecmp = ygot.Uint8(2)
+		bgpEvpnBgpInstance.VxlanInterface = ygot.String(vxlanItfceName)
+
+		bgpVpnBgpInstance := ni.GetOrCreateProtocols().GetOrCreateBgpVpn().GetOrCreateBgpInstance(bgpInstanceID)
+		bgpVpnBgpInstance.RouteTarget = &ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_BgpVpn_BgpInstance_RouteTarget{
+			ImportRt: ygot.String(getRouteTarget(r.overlayASN, id)),
+			ExportRt: ygot.String(getRouteTarget(r.overlayASN, id)),
+		}
+		if n.routerID != "" {
+			bgpVpnBgpInstance.RouteDistinguisher = &ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_BgpVpn_BgpInstance_RouteDistinguisher{
+				Rd: ygot.String(getRouteDistinguisher(n.routerID, id)),
+			}
+		}
+	}
+	return nil
+}
+
+func getRouteTarget(asn, id uint32) string {
+	return fmt.Sprintf("target:%d:%d", asn, id)
+}
+
+func getRouteDistinguisher(routerID string, id uint32) string {
+	return fmt.Sprintf("%s:%d", routerID, id)
+}
+
+func (r *fabric) claimASN(ctx context.Context, nodeName string) (uint32, error) {
+	id, err := r.claim(ctx, getASNIndexName(r.cr), fmt.Sprintf("%s-asn-%s", r.cr.GetName(), nodeName))
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("cannot claim asn for node: %s", nodeName))
+	}
+	// the claimed ids go up to 4094, which does not fit in the 2 byte private
+	// range above every base
+	asn := uint64(r.asnBase) + uint64(id)
+	if asn > uint64(getMaxNodeASN(r.asnBase)) || asn == uint64(r.overlayASN) {
+		return 0, fmt.Errorf("no private asn left for node %s: base %d + id %d", nodeName, r.asnBase, id)
+	}
+	return uint32(asn), nil
+}
+
+func (r *fabric) claimRT(ctx context.Context, niName string) (uint32, error) {
+	id, err := r.claim(ctx, getRTIndexName(r.cr), fmt.Sprintf("%s-rt-%s", r.cr.GetName(), niName))
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("cannot claim route target for network instance: %s", niName))
+	}
+	return id, nil
+}
+
+func (r *fabric) claim(ctx context.Context, dbIndexName, claimName string) (uint32, error) {
+	id, err := r.vlan.ClaimVLANID(ctx, r.cr, dbIndexName, claimName)
+	if err != nil {
+		return 0, err
+	}
+	if id == nil {
+		return 0, fmt.Errorf("no id allocated for claim %s in index %s", claimName, dbIndexName)
+	}
+	if !slices.Contains(r.claims[dbIndexName], claimName) {
+		r.claims[dbIndexName] = append(r.claims[dbIndexName], claimName)
+	}
+	return uint32(*id), nil
+}
+
+// getIndexes returns the claim indexes of the fabric annotated with the claims
+// made by addRouting
+func (r *fabric) getIndexes() ([]client.Object, error) {
+	indexes := getFabricIndexes(r.cr, r.vlan)
+	for _, o := range indexes {
+		claims := r.claims[o.GetName()]
+		if claims == nil {
+			claims = []string{}
+		}
+		sort.Strings(claims)
+		b, err := json.Marshal(claims)
+		if err != nil {
+			return nil, err
+		}
+		annotations := o.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[fabricClaimsKey] = string(b)
+		o.SetAnnotations(annotations)
+	}
+	return indexes, nil
+}
+
+// getStaleClaims returns the claims recorded on the existing index that are no
+// longer made by addRouting, e.g. of removed nodes or network instances
+func (r *fabric) getStaleClaims(existing client.Object) ([]string, error) {
+	v, ok := existing.GetAnnotations()[fabricClaimsKey]
+	if !ok {
+		return nil, nil
+	}
+	recorded := []string{}
+	if err := json.Unmarshal([]byte(v), &recorded); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s on index %s: %w", fabricClaimsKey, existing.GetName(), err)
+	}
+	claimed := map[string]bool{}
+	for _, claimName := range r.claims[existing.GetName()] {
+		claimed[claimName] = true
+	}
+	stale := []string{}
+	for _, claimName := range recorded {
+		if !claimed[claimName] {
+			stale = append(stale, claimName)
+		}
+	}
+	return stale, nil
+}
+
+func getDefaultBGP(d device.Device) *ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_Bgp {
+	ni, ok := d.NetworkInstance[defaultNetworkInstanceName]
+	if !ok || ni.Protocols == nil {
+		return nil
+	}
+	return ni.Protocols.Bgp
+}
+
+// getSubInterfacePrefixes returns the ip prefixes of a subinterface
+func getSubInterfacePrefixes(d device.Device, itfceName string, index uint32) []netip.Prefix {
+	pfxs := []netip.Prefix{}
+	itfce, ok := d.Interface[itfceName]
+	if !ok {
+		return pfxs
+	}
+	si, ok := itfce.Subinterface[index]
+	if !ok {
+		return pfxs
+	}
+	addresses := []string{}
+	if si.Ipv4 != nil {
+		for a := range si.Ipv4.Address {
+			addresses = append(addresses, a)
+		}
+	}
+	if si.Ipv6 != nil {
+		for a := range si.Ipv6.Address {
+			addresses = append(addresses, a)
+		}
+	}
+	sort.Strings(addresses)
+	for _, a := range addresses {
+		pfx, err := netip.ParsePrefix(a)
+		if err != nil {
+			continue
+		}
+		pfxs = append(pfxs, pfx)
+	}
+	return pfxs
+}
+
+// getNetworkInstanceLinkPrefixes returns the prefixes of the subinterfaces of
+// the network instance, except the system interface
+func getNetworkInstanceLinkPrefixes(d device.Device, niName string) []netip.Prefix {
+	pfxs := []netip.Prefix{}
+	ni, ok := d.NetworkInstance[niName]
+	if !ok {
+		return pfxs
+	}
+	for siName := range ni.Interface {
+		itfceName, index, ok := strings.Cut(siName, ".")
+		if !ok || itfceName == device.SystemInterfaceName {
+			continue
+		}
+		idx, err := strconv.ParseUint(index, 10, 32)
+		if err != nil {
+			continue
+		}
+		pfxs = append(pfxs, getSubInterfacePrefixes(d, itfceName, uint32(idx))...)
+	}
+	return pfxs
+}
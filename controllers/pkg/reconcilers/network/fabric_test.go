@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/henderiw-nephio/network/pkg/device"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/srl-labs/ygotsrl/v22"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeVLAN allocates ids per index in the order of the claims and returns the
+// same id for the same claim, like the vlan backend
+type fakeVLAN struct {
+	claims map[string]map[string]uint16
+}
+
+func (r *fakeVLAN) ClaimVLANDB(cr client.Object, dbIndexName string) *vlanv1alpha1.VLANIndex {
+	return vlanv1alpha1.BuildVLANIndex(metav1.ObjectMeta{Name: dbIndexName}, vlanv1alpha1.VLANIndexSpec{}, vlanv1alpha1.VLANIndexStatus{})
+}
+
+func (r *fakeVLAN) ClaimVLANID(ctx context.Context, cr client.Object, dbIndexName, claimName string) (*uint16, error) {
+	if _, ok := r.claims[dbIndexName]; !ok {
+		r.claims[dbIndexName] = map[string]uint16{}
+	}
+	id, ok := r.claims[dbIndexName][claimName]
+	if !ok {
+		id = uint16(len(r.claims[dbIndexName]) + 1)
+		r.claims[dbIndexName][claimName] = id
+	}
+	return &id, nil
+}
+
+func buildFabricDevice(systemAddress, linkPrefix string) *ygotsrl.Device {
+	d := device.Device{Device: &ygotsrl.Device{}}
+	d.AddRoutingInstance("", "", defaultNetworkInstanceName)
+	d.GetOrCreateInterface(device.SystemInterfaceName).GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress(systemAddress)
+	d.GetOrCreateInterface("ethernet-1/49").GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress(linkPrefix)
+	ni := d.GetOrCreateNetworkInstance(defaultNetworkInstanceName)
+	ni.GetOrCreateInterface("system0.0")
+	ni.GetOrCreateInterface("ethernet-1/49.0")
+	d.GetOrCreateRoutingPolicy().GetOrCreatePolicy("export-local")
+	d.AddRoutingProtocols(defaultNetworkInstanceName)
+	d.AddBridgeDomain("", "", "vpc-ran-edge1")
+	return d.Device
+}
+
+func TestFabricAddRouting(t *testing.T) {
+	cr := &infrav1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "vpc-ran",
+			Annotations: map[string]string{fabricASNBaseKey: "64600"},
+		},
+	}
+	v := &fakeVLAN{claims: map[string]map[string]uint16{}}
+
+	for i := 0; i < 2; i++ {
+		devices := map[string]*ygotsrl.Device{
+			"leaf1": buildFabricDevice("10.0.0.1/32", "100.64.0.0/31"),
+			"leaf2": buildFabricDevice("10.0.0.2/32", "100.64.0.1/31"),
+		}
+		f, err := newFabric(cr, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.addRouting(context.Background(), devices); err != nil {
+			t.Fatal(err)
+		}
+
+		bgp := devices["leaf1"].NetworkInstance[defaultNetworkInstanceName].Protocols.Bgp
+		if got := bgp.GetAutonomousSystem(); got != 64601 {
+			t.Errorf("leaf1 asn want 64601, got %d", got)
+		}
+		if got := bgp.GetRouterId(); got != "10.0.0.1" {
+			t.Errorf("leaf1 router-id want 10.0.0.1, got %s", got)
+		}
+		underlay, ok := bgp.Neighbor["100.64.0.1"]
+		if !ok {
+			t.Fatalf("leaf1 missing underlay neighbor, got: %v", bgp.Neighbor)
+		}
+		if underlay.GetPeerGroup() != underlayGroupName || underlay.GetPeerAs() != 64602 {
+			t.Errorf("leaf1 underlay neighbor want group %s, peer-as 64602, got %s, %d", underlayGroupName, underlay.GetPeerGroup(), underlay.GetPeerAs())
+		}
+		overlay, ok := bgp.Neighbor["10.0.0.2"]
+		if !ok || overlay.GetPeerGroup() != overlayGroupName {
+			t.Errorf("leaf1 missing overlay neighbor, got: %v", bgp.Neighbor)
+		}
+		if got := bgp.Group[overlayGroupName].GetPeerAs(); got != defaultOverlayASN {
+			t.Errorf("overlay peer-as want %d, got %d", defaultOverlayASN, got)
+		}
+
+		for nodeName, rd := range map[string]string{"leaf1": "10.0.0.1:1", "leaf2": "10.0.0.2:1"} {
+			ni := devices[nodeName].NetworkInstance["vpc-ran-edge1"]
+			bgpVpn := ni.Protocols.BgpVpn.BgpInstance[bgpInstanceID]
+			if got := bgpVpn.RouteTarget.GetExportRt(); got != "target:65534:1" {
+				t.Errorf("%s export rt want target:65534:1, got %s", nodeName, got)
+			}
+			if got := bgpVpn.RouteDistinguisher.GetRd(); got != rd {
+				t.Errorf("%s rd want %s, got %s", nodeName, rd, got)
+			}
+			if got := ni.Protocols.BgpEvpn.BgpInstance[bgpInstanceID].GetVxlanInterface(); got != "vxlan0.1" {
+				t.Errorf("%s vxlan interface want vxlan0.1, got %s", nodeName, got)
+			}
+			if _, err := ygot.EmitJSON(devices[nodeName], &ygot.EmitJSONConfig{Format: ygot.RFC7951}); err != nil {
+				t.Errorf("%s invalid device config: %v", nodeName, err)
+			}
+		}
+	}
+}
+
+func TestNewFabricInvalidASN(t *testing.T) {
+	cases := map[string]map[string]string{
+		"NotANumber":    {fabricOverlayASNKey: "not-an-asn"},
+		"Reserved":      {fabricOverlayASNKey: "65535"},
+		"Public":        {fabricOverlayASNKey: "23456"},
+		"Zero":          {fabricASNBaseKey: "0"},
+		"Reserved4":     {fabricASNBaseKey: "4294967295"},
+		"Documentation": {fabricASNBaseKey: "64500"},
+	}
+	for name, annotations := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Network{
+				ObjectMeta: metav1.ObjectMeta{Name: "vpc-ran", Annotations: annotations},
+			}
+			if _, err := newFabric(cr, &fakeVLAN{}); err == nil {
+				t.Errorf("expected an error for annotations %v", annotations)
+			}
+		})
+	}
+}
+
+func TestFabricClaimASN(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        uint32
+		wantErr     bool
+	}{
+		"Default": {
+			want: 64513,
+		},
+		"Private4": {
+			annotations: map[string]string{fabricASNBaseKey: "4200000000"},
+			want:        4200000001,
+		},
+		"OverlayASN": {
+			annotations: map[string]string{fabricASNBaseKey: "65533"},
+			wantErr:     true,
+		},
+		"OutOfRange": {
+			annotations: map[string]string{fabricASNBaseKey: "65534", fabricOverlayASNKey: "64512"},
+			wantErr:     true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Network{
+				ObjectMeta: metav1.ObjectMeta{Name: "vpc-ran", Annotations: tc.annotations},
+			}
+			f, err := newFabric(cr, &fakeVLAN{claims: map[string]map[string]uint16{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.claimASN(context.Background(), "leaf1")
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got asn %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want asn %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFabricStaleClaims(t *testing.T) {
+	cr := &infrav1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Name: "vpc-ran"}}
+	v := &fakeVLAN{claims: map[string]map[string]uint16{}}
+
+	f, err := newFabric(cr, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.addRouting(context.Background(), map[string]*ygotsrl.Device{
+		"leaf1": buildFabricDevice("10.0.0.1/32", "100.64.0.0/31"),
+		"leaf2": buildFabricDevice("10.0.0.2/32", "100.64.0.1/31"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := f.getIndexes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// leaf2 is removed from the topology
+	f, err = newFabric(cr, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.addRouting(context.Background(), map[string]*ygotsrl.Device{
+		"leaf1": buildFabricDevice("10.0.0.1/32", "100.64.0.0/31"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		getASNIndexName(cr): {"vpc-ran-asn-leaf2"},
+		getRTIndexName(cr):  {},
+	}
+	for _, o := range existing {
+		got, err := f.getStaleClaims(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want[o.GetName()]) {
+			t.Errorf("%s: want stale claims %v, got %v", o.GetName(), want[o.GetName()], got)
+		}
+	}
+
+	// an index without recorded claims has no stale claims
+	got, err := f.getStaleClaims(&vlanv1alpha1.VLANIndex{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("want no stale claims, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,379 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	"github.com/srl-labs/ygotsrl/v22"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// the resolved topology and intent of a network is exported in a
+	// configmap <network>-graph as json and graphviz dot
+	graphConfigMapSuffix = "graph"
+	graphJSONKey         = "graph.json"
+	graphDOTKey          = "graph.dot"
+	// maxGraphSize is the size of the outputs that fits in the configmap,
+	// leaving room for the metadata below the 1 MiB limit of an object
+	maxGraphSize = 1024*1024 - 64*1024
+	// graphExportedCondition reports the outputs that do not fit in the
+	// configmap
+	graphExportedCondition = "GraphExported"
+
+	networkInstanceTypeMacVRF  = "mac-vrf"
+	networkInstanceTypeIPVRF   = "ip-vrf"
+	networkInstanceTypeDefault = "default"
+)
+
+// graph is the resolved topology of a network: the inventory it is built
+// from and the bridge domains and routing tables with their allocations
+type graph struct {
+	Name          string                 `json:"name"`
+	Topology      string                 `json:"topology"`
+	Nodes         []graphNode            `json:"nodes"`
+	Endpoints     []graphEndpoint        `json:"endpoints"`
+	Links         []graphLink            `json:"links"`
+	BridgeDomains []graphNetworkInstance `json:"bridgeDomains"`
+	RoutingTables []graphNetworkInstance `json:"routingTables"`
+}
+
+type graphNode struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider,omitempty"`
+	Address  string `json:"address,omitempty"`
+	ASN      uint32 `json:"asn,omitempty"`
+	RouterID string `json:"routerID,omitempty"`
+}
+
+type graphEndpoint struct {
+	NodeName      string `json:"nodeName"`
+	InterfaceName string `json:"interfaceName"`
+	LinkName      string `json:"linkName,omitempty"`
+	ClusterName   string `json:"clusterName,omitempty"`
+}
+
+type graphLink struct {
+	Name      string          `json:"name"`
+	Endpoints []graphEndpoint `json:"endpoints"`
+}
+
+type graphNetworkInstance struct {
+	Name                string                     `json:"name"`
+	Type                string                     `json:"type"`
+	EVI                 uint32                     `json:"evi,omitempty"`
+	RouteTarget         string                     `json:"routeTarget,omitempty"`
+	NodeSubInterfaces   map[string][]graphSubItfce `json:"nodes"`
+	RouteDistinguishers map[string]string          `json:"routeDistinguishers,omitempty"`
+}
+
+type graphSubItfce struct {
+	Name      string   `json:"name"`
+	VLANID    uint16   `json:"vlanID,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// buildGraph builds the graph of the network from the inventory and the
+// device configs of the fabric nodes
+func buildGraph(cr *infrav1alpha1.Network, nodes []invv1alpha1.Node, eps []invv1alpha1.Endpoint, links []invv1alpha1.Link, devices map[string]*ygotsrl.Device) *graph {
+	g := &graph{
+		Name:          cr.GetName(),
+		Topology:      cr.Spec.Topology,
+		Nodes:         []graphNode{},
+		Endpoints:     []graphEndpoint{},
+		Links:         []graphLink{},
+		BridgeDomains: []graphNetworkInstance{},
+		RoutingTables: []graphNetworkInstance{},
+	}
+
+	knownNodes := map[string]struct{}{}
+	for _, n := range nodes {
+		gn := graphNode{Name: n.GetName(), Provider: n.Spec.Provider}
+		if n.Spec.Address != nil {
+			gn.Address = *n.Spec.Address
+		}
+		if d, ok := devices[n.GetName()]; ok {
+			if ni, ok := d.NetworkInstance[defaultNetworkInstanceName]; ok && ni.Protocols != nil && ni.Protocols.Bgp != nil {
+				gn.ASN = ni.Protocols.Bgp.GetAutonomousSystem()
+				gn.RouterID = ni.Protocols.Bgp.GetRouterId()
+			}
+		}
+		g.Nodes = append(g.Nodes, gn)
+		knownNodes[gn.Name] = struct{}{}
+	}
+
+	for _, ep := range eps {
+		g.Endpoints = append(g.Endpoints, graphEndpoint{
+			NodeName:      ep.Spec.NodeName,
+			InterfaceName: ep.Spec.InterfaceName,
+			LinkName:      ep.GetLabels()[invv1alpha1.NephioLinkNameKey],
+			ClusterName:   ep.GetLabels()[invv1alpha1.NephioClusterNameKey],
+		})
+	}
+
+	for _, l := range links {
+		gl := graphLink{Name: l.GetName(), Endpoints: []graphEndpoint{}}
+		for _, ep := range l.Spec.Endpoints {
+			gl.Endpoints = append(gl.Endpoints, graphEndpoint{NodeName: ep.NodeName, InterfaceName: ep.InterfaceName})
+			// nodes of other providers, e.g. the clusters attached to the fabric
+			if _, ok := knownNodes[ep.NodeName]; !ok {
+				g.Nodes = append(g.Nodes, graphNode{Name: ep.NodeName})
+				knownNodes[ep.NodeName] = struct{}{}
+			}
+		}
+		g.Links = append(g.Links, gl)
+	}
+
+	nis := map[string]*graphNetworkInstance{}
+	for nodeName, d := range devices {
+		for niName, ni := range d.NetworkInstance {
+			niType := getNetworkInstanceType(ni.Type)
+			if niType == "" {
+				continue
+			}
+			gni, ok := nis[niName]
+			if !ok {
+				gni = &graphNetworkInstance{
+					Name:                niName,
+					Type:                niType,
+					NodeSubInterfaces:   map[string][]graphSubItfce{},
+					RouteDistinguishers: map[string]string{},
+				}
+				nis[niName] = gni
+			}
+			if ni.Protocols != nil && ni.Protocols.BgpEvpn != nil {
+				if bi, ok := ni.Protocols.BgpEvpn.BgpInstance[bgpInstanceID]; ok {
+					gni.EVI = bi.GetEvi()
+				}
+			}
+			if ni.Protocols != nil && ni.Protocols.BgpVpn != nil {
+				if bi, ok := ni.Protocols.BgpVpn.BgpInstance[bgpInstanceID]; ok {
+					if bi.RouteTarget != nil {
+						gni.RouteTarget = bi.RouteTarget.GetExportRt()
+					}
+					if bi.RouteDistinguisher != nil {
+						gni.RouteDistinguishers[nodeName] = bi.RouteDistinguisher.GetRd()
+					}
+				}
+			}
+			sis := []graphSubItfce{}
+			for siName := range ni.Interface {
+				sis = append(sis, getGraphSubInterface(d, siName))
+			}
+			sort.Slice(sis, func(i, j int) bool { return sis[i].Name < sis[j].Name })
+			gni.NodeSubInterfaces[nodeName] = sis
+		}
+	}
+	for _, gni := range nis {
+		if gni.Type == networkInstanceTypeMacVRF {
+			g.BridgeDomains = append(g.BridgeDomains, *gni)
+		} else {
+			g.RoutingTables = append(g.RoutingTables, *gni)
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Name < g.Nodes[j].Name })
+	sort.Slice(g.Endpoints, func(i, j int) bool {
+		if g.Endpoints[i].NodeName != g.Endpoints[j].NodeName {
+			return g.Endpoints[i].NodeName < g.Endpoints[j].NodeName
+		}
+		return g.Endpoints[i].InterfaceName < g.Endpoints[j].InterfaceName
+	})
+	sort.Slice(g.Links, func(i, j int) bool { return g.Links[i].Name < g.Links[j].Name })
+	sort.Slice(g.BridgeDomains, func(i, j int) bool { return g.BridgeDomains[i].Name < g.BridgeDomains[j].Name })
+	sort.Slice(g.RoutingTables, func(i, j int) bool { return g.RoutingTables[i].Name < g.RoutingTables[j].Name })
+	return g
+}
+
+func getNetworkInstanceType(t ygotsrl.E_SrlNokiaNetworkInstance_NiType) string {
+	switch t {
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf:
+		return networkInstanceTypeMacVRF
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_ip_vrf:
+		return networkInstanceTypeIPVRF
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_default:
+		return networkInstanceTypeDefault
+	}
+	return ""
+}
+
+// getGraphSubInterface returns the vlan and ip allocations of a network
+// instance subinterface, e.g. ethernet-1/1.10
+func getGraphSubInterface(d *ygotsrl.Device, siName string) graphSubItfce {
+	gsi := graphSubItfce{Name: siName}
+	itfceName, index, ok := strings.Cut(siName, ".")
+	if !ok {
+		return gsi
+	}
+	idx, err := strconv.ParseUint(index, 10, 32)
+	if err != nil {
+		return gsi
+	}
+	itfce, ok := d.Interface[itfceName]
+	if !ok {
+		return gsi
+	}
+	si, ok := itfce.Subinterface[uint32(idx)]
+	if !ok {
+		return gsi
+	}
+	if si.Vlan != nil && si.Vlan.Encap != nil && si.Vlan.Encap.SingleTagged != nil {
+		if vlanID, ok := si.Vlan.Encap.SingleTagged.VlanId.(ygotsrl.UnionUint16); ok {
+			gsi.VLANID = uint16(vlanID)
+		}
+	}
+	if si.Ipv4 != nil {
+		for a := range si.Ipv4.Address {
+			gsi.Addresses = append(gsi.Addresses, a)
+		}
+	}
+	if si.Ipv6 != nil {
+		for a := range si.Ipv6.Address {
+			gsi.Addresses = append(gsi.Addresses, a)
+		}
+	}
+	sort.Strings(gsi.Addresses)
+	return gsi
+}
+
+// dot renders the graph in the graphviz dot format: the nodes are connected by
+// their links and every network instance is a cluster with a vertex per node
+// it is instantiated on
+func (r *graph) dot() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "graph %s {\n", strconv.Quote(r.Name))
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range r.Nodes {
+		label := n.Name
+		if n.Provider != "" {
+			label = fmt.Sprintf("%s\n%s", label, n.Provider)
+		}
+		if n.ASN != 0 {
+			label = fmt.Sprintf("%s\nasn %d", label, n.ASN)
+		}
+		if n.RouterID != "" {
+			label = fmt.Sprintf("%s\n%s", label, n.RouterID)
+		}
+		fmt.Fprintf(b, "  %s [label=%s];\n", strconv.Quote(n.Name), strconv.Quote(label))
+	}
+	for _, l := range r.Links {
+		if len(l.Endpoints) != 2 {
+			continue
+		}
+		fmt.Fprintf(b, "  %s -- %s [taillabel=%s, headlabel=%s];\n",
+			strconv.Quote(l.Endpoints[0].NodeName), strconv.Quote(l.Endpoints[1].NodeName),
+			strconv.Quote(l.Endpoints[0].InterfaceName), strconv.Quote(l.Endpoints[1].InterfaceName))
+	}
+	nis := append(append([]graphNetworkInstance{}, r.BridgeDomains...), r.RoutingTables...)
+	for _, ni := range nis {
+		label := fmt.Sprintf("%s (%s)", ni.Name, ni.Type)
+		if ni.RouteTarget != "" {
+			label = fmt.Sprintf("%s\n%s", label, ni.RouteTarget)
+		}
+		fmt.Fprintf(b, "  subgraph %s {\n", strconv.Quote("cluster_"+ni.Name))
+		fmt.Fprintf(b, "    label=%s;\n", strconv.Quote(label))
+		b.WriteString("    style=dashed;\n")
+		nodeNames := make([]string, 0, len(ni.NodeSubInterfaces))
+		for nodeName := range ni.NodeSubInterfaces {
+			nodeNames = append(nodeNames, nodeName)
+		}
+		sort.Strings(nodeNames)
+		for _, nodeName := range nodeNames {
+			label := nodeName
+			for _, si := range ni.NodeSubInterfaces[nodeName] {
+				label = fmt.Sprintf("%s\n%s", label, si.Name)
+				if si.VLANID != 0 {
+					label = fmt.Sprintf("%s vlan %d", label, si.VLANID)
+				}
+				if len(si.Addresses) != 0 {
+					label = fmt.Sprintf("%s %s", label, strings.Join(si.Addresses, ","))
+				}
+			}
+			fmt.Fprintf(b, "    %s [shape=ellipse, label=%s];\n", strconv.Quote(ni.Name+"/"+nodeName), strconv.Quote(label))
+		}
+		b.WriteString("  }\n")
+		for _, nodeName := range nodeNames {
+			fmt.Fprintf(b, "  %s -- %s [style=dotted];\n", strconv.Quote(ni.Name+"/"+nodeName), strconv.Quote(nodeName))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func getGraphConfigMap(cr *infrav1alpha1.Network, g *graph) (*corev1.ConfigMap, []string, error) {
+	j, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	data := map[string]string{
+		graphJSONKey: string(j),
+		graphDOTKey:  g.dot(),
+	}
+	// the outputs of large fabrics can exceed the size of an object, the dot
+	// output is dropped first as it is derived from the same graph
+	omitted := []string{}
+	for _, key := range []string{graphDOTKey, graphJSONKey} {
+		if len(data[graphJSONKey])+len(data[graphDOTKey]) <= maxGraphSize {
+			break
+		}
+		delete(data, key)
+		omitted = append(omitted, key)
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%s", cr.GetName(), graphConfigMapSuffix),
+			Namespace:       cr.GetNamespace(),
+			Labels:          resourcev1alpha1.GetOwnerLabelsFromCR(cr),
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: ptr.To(true)}},
+		},
+		Data: data,
+	}, omitted, nil
+}
+
+// getGraphCondition returns the condition of the graph export, false with the
+// outputs that do not fit in the configmap
+func getGraphCondition(omitted []string) infrav1alpha1.Condition {
+	if len(omitted) == 0 {
+		return infrav1alpha1.Condition{Condition: metav1.Condition{
+			Type:               graphExportedCondition,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "Exported",
+		}}
+	}
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               graphExportedCondition,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "TooLarge",
+		Message:            fmt.Sprintf("%s exceed the size limit of the configmap", strings.Join(omitted, ", ")),
+	}}
+}
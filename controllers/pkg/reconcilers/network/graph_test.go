@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/srl-labs/ygotsrl/v22"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildGraph(t *testing.T) {
+	cr := &infrav1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc-ran", Namespace: "default"},
+		Spec:       infrav1alpha1.NetworkSpec{Topology: "nephio"},
+	}
+	devices := map[string]*ygotsrl.Device{
+		"leaf1": buildFabricDevice("10.0.0.1/32", "100.64.0.0/31"),
+		"leaf2": buildFabricDevice("10.0.0.2/32", "100.64.0.1/31"),
+	}
+	f, err := newFabric(cr, &fakeVLAN{claims: map[string]map[string]uint16{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.addRouting(context.Background(), devices); err != nil {
+		t.Fatal(err)
+	}
+	nodes := []invv1alpha1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "leaf1"}, Spec: invv1alpha1.NodeSpec{Provider: nokiaSRLProvider}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "leaf2"}, Spec: invv1alpha1.NodeSpec{Provider: nokiaSRLProvider}},
+	}
+	eps := []invv1alpha1.Endpoint{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "leaf1-ethernet-1-1", Labels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge1"}},
+			Spec:       invv1alpha1.EndpointSpec{EndpointProperties: invv1alpha1.EndpointProperties{NodeName: "leaf1", InterfaceName: "ethernet-1/1"}},
+		},
+	}
+	links := []invv1alpha1.Link{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge1-eth1-leaf1-ethernet-1-1"},
+			Spec: invv1alpha1.LinkSpec{Endpoints: []invv1alpha1.LinkEndpoint{
+				{NodeName: "edge1", InterfaceName: "eth1"},
+				{NodeName: "leaf1", InterfaceName: "ethernet-1/1"},
+			}},
+		},
+	}
+
+	g := buildGraph(cr, nodes, eps, links, devices)
+
+	gotNodes := []string{}
+	for _, n := range g.Nodes {
+		gotNodes = append(gotNodes, n.Name)
+	}
+	if diff := cmp.Diff([]string{"edge1", "leaf1", "leaf2"}, gotNodes); diff != "" {
+		t.Errorf("nodes -want, +got:\n%s", diff)
+	}
+	if g.Nodes[1].ASN != defaultASNBase+1 || g.Nodes[1].RouterID != "10.0.0.1" {
+		t.Errorf("leaf1 want asn %d and router-id 10.0.0.1, got: %v", defaultASNBase+1, g.Nodes[1])
+	}
+	if len(g.BridgeDomains) != 1 || g.BridgeDomains[0].Name != "vpc-ran-edge1" || g.BridgeDomains[0].EVI != 1 {
+		t.Errorf("unexpected bridge domains: %v", g.BridgeDomains)
+	}
+	if len(g.RoutingTables) != 1 || g.RoutingTables[0].Name != defaultNetworkInstanceName {
+		t.Fatalf("unexpected routing tables: %v", g.RoutingTables)
+	}
+	want := []graphSubItfce{
+		{Name: "ethernet-1/49.0", Addresses: []string{"100.64.0.0/31"}},
+		{Name: "system0.0", Addresses: []string{"10.0.0.1/32"}},
+	}
+	if diff := cmp.Diff(want, g.RoutingTables[0].NodeSubInterfaces["leaf1"]); diff != "" {
+		t.Errorf("leaf1 default subinterfaces -want, +got:\n%s", diff)
+	}
+
+	cm, omitted, err := getGraphConfigMap(cr, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(omitted) != 0 {
+		t.Errorf("want all outputs, got omitted %v", omitted)
+	}
+	if cm.GetName() != "vpc-ran-graph" {
+		t.Errorf("want configmap vpc-ran-graph, got %s", cm.GetName())
+	}
+	if err := json.Unmarshal([]byte(cm.Data[graphJSONKey]), &graph{}); err != nil {
+		t.Errorf("invalid graph json: %v", err)
+	}
+	dot := cm.Data[graphDOTKey]
+	for _, s := range []string{
+		`graph "vpc-ran" {`,
+		`"edge1" -- "leaf1" [taillabel="eth1", headlabel="ethernet-1/1"];`,
+		`subgraph "cluster_vpc-ran-edge1" {`,
+		`"vpc-ran-edge1/leaf2" -- "leaf2" [style=dotted];`,
+	} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("dot output does not contain %s, got:\n%s", s, dot)
+		}
+	}
+}
+
+func TestGetGraphConfigMapSize(t *testing.T) {
+	cases := map[string]struct {
+		nodes       int
+		wantOmitted []string
+	}{
+		"Small": {
+			nodes:       10,
+			wantOmitted: []string{},
+		},
+		"DotTooLarge": {
+			nodes:       400,
+			wantOmitted: []string{graphDOTKey},
+		},
+		"TooLarge": {
+			nodes:       1000,
+			wantOmitted: []string{graphDOTKey, graphJSONKey},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Name: "vpc-ran", Namespace: "default"}}
+			g := &graph{Name: "vpc-ran"}
+			for i := range tc.nodes {
+				g.Nodes = append(g.Nodes, graphNode{Name: fmt.Sprintf("%04d-%s", i, strings.Repeat("x", 1000))})
+			}
+			cm, omitted, err := getGraphConfigMap(cr, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantOmitted, omitted); diff != "" {
+				t.Errorf("omitted -want, +got:\n%s", diff)
+			}
+			size := 0
+			for _, v := range cm.Data {
+				size += len(v)
+			}
+			if size > maxGraphSize {
+				t.Errorf("want outputs of at most %d bytes, got %d", maxGraphSize, size)
+			}
+			c := getGraphCondition(omitted)
+			if wantStatus := len(tc.wantOmitted) == 0; (c.Status == metav1.ConditionTrue) != wantStatus {
+				t.Errorf("want condition status true %t, got %s", wantStatus, c.Status)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
 	infra2v1alpha1 "github.com/henderiw-nephio/network/apis/infra2/v1alpha1"
@@ -45,9 +46,11 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/srl-labs/ygotsrl/v22"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -62,6 +65,8 @@ func init() {
 const (
 	finalizer        = "infra.nephio.org/finalizer"
 	nokiaSRLProvider = "srl.nokia.com"
+	// annotations with this prefix configure the drift detection of the node configs
+	driftAnnotationPrefix = "nephio.org/drift-"
 	// errors
 	errGetCr        = "cannot get cr"
 	errUpdateStatus = "cannot update status"
@@ -77,6 +82,11 @@ const (
 //+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=links,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=vlan.resource.nephio.org,resources=vlanindices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=vlan.resource.nephio.org,resources=vlanindices/status,verbs=get;update;patch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
@@ -116,7 +126,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 		Owns(&vlanv1alpha1.VLANIndex{}).
 		Owns(&configv1alpha1.Network{}).
 		Watches(&invv1alpha1.Endpoint{}, &endpointEventHandler{client: mgr.GetClient()}).
-		Watches(&invv1alpha1.Endpoint{}, &nodeEventHandler{client: mgr.GetClient()}).
+		Watches(&invv1alpha1.Node{}, &nodeEventHandler{client: mgr.GetClient()}).
 		Complete(r)
 
 }
@@ -218,12 +228,60 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
+// releaseFabricClaims releases the asn and route target claims of removed nodes
+// and network instances and records the current claims on the indexes
+func (r *reconciler) releaseFabricClaims(ctx context.Context, f *fabric) error {
+	indexes, err := f.getIndexes()
+	if err != nil {
+		return err
+	}
+	for _, o := range indexes {
+		existing := &vlanv1alpha1.VLANIndex{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}, existing); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+		stale, err := f.getStaleClaims(existing)
+		if err != nil {
+			return err
+		}
+		for _, claimName := range stale {
+			log.FromContext(ctx).Info("release fabric claim", "index", o.GetName(), "claim", claimName)
+			claim := vlanv1alpha1.BuildVLANClaim(
+				metav1.ObjectMeta{Name: claimName, Namespace: o.GetNamespace()},
+				vlanv1alpha1.VLANClaimSpec{
+					VLANIndex: corev1.ObjectReference{Name: o.GetName(), Namespace: o.GetNamespace()},
+				},
+				vlanv1alpha1.VLANClaimStatus{},
+			)
+			if err := r.VlanClientProxy.DeleteClaim(ctx, claim, nil); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("cannot release claim %s in index %s", claimName, o.GetName()))
+			}
+		}
+		r.resources.AddNewResource(o)
+	}
+	return nil
+}
+
 func getMatchingNodeLabels(cr client.Object, nodeName string) client.MatchingLabels {
 	labels := resourcev1alpha1.GetOwnerLabelsFromCR(cr)
 	labels[invv1alpha1.NephioNodeNameKey] = nodeName
 	return labels
 }
 
+// getDriftAnnotations returns the drift detection annotations of the network
+// such that they are propagated to the node configs
+func getDriftAnnotations(cr client.Object) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range cr.GetAnnotations() {
+		if strings.HasPrefix(k, driftAnnotationPrefix) {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
+
 func (r *reconciler) getProviderEndpoints(ctx context.Context, topology string) (*endpoints.Endpoints, error) {
 	opts := []client.ListOption{
 		client.MatchingLabels{
@@ -269,6 +327,10 @@ func (r *reconciler) applyInitialresources(ctx context.Context, cr *infrav1alpha
 		log.FromContext(ctx).Error(err, "cannot execute network run")
 		return err
 	}
+	// the indexes from which the fabric asns and route targets are claimed
+	for _, o := range getFabricIndexes(cr, vlan.NewVLAN(r.VlanClientProxy)) {
+		r.resources.AddNewResource(o)
+	}
 	if err := r.resources.APIApply(ctx); err != nil {
 		log.FromContext(ctx).Error(err, "cannot apply resources to the API")
 		return err
@@ -292,6 +354,37 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 		return err
 	}
 
+	// add the underlay/overlay routing and evpn config to the devices
+	f, err := newFabric(cr, vlan.NewVLAN(r.VlanClientProxy))
+	if err != nil {
+		return err
+	}
+	if err := f.addRouting(ctx, n.GetDevices()); err != nil {
+		log.FromContext(ctx).Error(err, "cannot add fabric routing")
+		return err
+	}
+	if err := r.releaseFabricClaims(ctx, f); err != nil {
+		log.FromContext(ctx).Error(err, "cannot release fabric claims")
+		return err
+	}
+
+	// export the resolved topology and intent for troubleshooting
+	links := &invv1alpha1.LinkList{}
+	if err := r.List(ctx, links, client.MatchingLabels{invv1alpha1.NephioTopologyKey: cr.Spec.Topology}); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list links")
+		return err
+	}
+	cm, omitted, err := getGraphConfigMap(cr, buildGraph(cr, nodes.Items, eps.Items, links.Items, n.GetDevices()))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "cannot build network graph")
+		return err
+	}
+	if len(omitted) != 0 {
+		log.FromContext(ctx).Info("network graph too large for the configmap", "omitted", omitted)
+	}
+	cr.SetConditions(getGraphCondition(omitted))
+	r.resources.AddNewResource(cm)
+
 	// list all networkConfigs
 	opts := []client.ListOption{
 		resourcev1alpha1.GetOwnerLabelsFromCR(cr),
@@ -327,6 +420,7 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 				Name:            fmt.Sprintf("%s-%s", cr.Name, nodeName),
 				Namespace:       cr.Namespace,
 				Labels:          getMatchingNodeLabels(cr, nodeName),
+				Annotations:     getDriftAnnotations(cr),
 				OwnerReferences: []metav1.OwnerReference{{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: ptr.To(true)}},
 			}, configv1alpha1.NetworkSpec{
 				Config: runtime.RawExtension{
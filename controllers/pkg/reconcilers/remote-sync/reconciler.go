@@ -0,0 +1,311 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotesync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	replicationv1alpha1 "github.com/nephio-project/nephio/controllers/pkg/apis/replication/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func init() {
+	reconcilerinterface.Register("remotesyncs", &reconciler{})
+}
+
+const (
+	// finalizer deletes the copies from the clusters
+	finalizer = "replication.nephio.org/finalizer"
+	// resyncPeriod picks up new clusters and label changes of the clusters of
+	// a cluster selector
+	resyncPeriod = 5 * time.Minute
+	// errors
+	errUpdateStatus = "cannot update status"
+)
+
+//+kubebuilder:rbac:groups=replication.nephio.org,resources=remotesyncs,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=replication.nephio.org,resources=remotesyncs/status,verbs=get;update;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	if err := replicationv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := cluster.SetupIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+	r.Client = mgr.GetClient()
+	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.replicator = replication.NewReplicator("remotesyncs")
+	r.cache = mgr.GetCache()
+	r.watches = map[schema.GroupVersionKind]bool{}
+
+	var err error
+	r.controller, err = ctrl.NewControllerManagedBy(mgr).
+		Named("RemoteSyncController").
+		For(&replicationv1alpha1.RemoteSync{}).
+		Build(r)
+	return nil, err
+}
+
+type reconciler struct {
+	client.Client
+	finalizer  *resource.APIFinalizer
+	replicator *replication.Replicator
+
+	// the kinds of the objects are only known from the RemoteSyncs, their
+	// watches are added when a RemoteSync selects them
+	controller controller.Controller
+	cache      cache.Cache
+	watchesMu  sync.Mutex
+	watches    map[schema.GroupVersionKind]bool
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	cr := &replicationv1alpha1.RemoteSync{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// if the resource no longer exists the reconcile loop is done
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if resource.WasDeleted(cr) {
+		// delete the copies from all clusters, clusters that are not ready
+		// keep the finalizer
+		remaining := []replicationv1alpha1.ClusterStatus{}
+		for _, cs := range cr.Status.Clusters {
+			if next := r.deleteFromCluster(ctx, cr, cs); next != nil {
+				remaining = append(remaining, *next)
+			}
+		}
+		if len(remaining) > 0 {
+			cr.Status.Clusters = remaining
+			setReadyCondition(cr)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
+			msg := "cannot remove finalizer"
+			log.Error(err, msg)
+			return ctrl.Result{Requeue: true}, errors.Wrap(err, msg)
+		}
+		log.Info("Successfully deleted resource")
+		return ctrl.Result{}, nil
+	}
+
+	// add finalizer to avoid deleting the RemoteSync w/o deleting the copies
+	if err := r.finalizer.AddFinalizer(ctx, cr); err != nil {
+		msg := "cannot add finalizer"
+		log.Error(err, msg)
+		return ctrl.Result{Requeue: true}, errors.Wrap(err, msg)
+	}
+
+	objects, err := r.getObjects(ctx, cr)
+	if err != nil {
+		log.Error(err, "cannot get objects")
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		meta.SetStatusCondition(&cr.Status.Conditions, failedCondition(cr, err.Error()))
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	clusterNames, err := r.getClusterNames(ctx, cr)
+	if err != nil {
+		log.Error(err, "cannot get cluster names")
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		meta.SetStatusCondition(&cr.Status.Conditions, failedCondition(cr, err.Error()))
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	previous := map[string]replicationv1alpha1.ClusterStatus{}
+	for _, cs := range cr.Status.Clusters {
+		previous[cs.ClusterName] = cs
+	}
+	clusters := []replicationv1alpha1.ClusterStatus{}
+	// each cluster is synced independently, a cluster that is not ready does
+	// not block the other clusters
+	for _, clusterName := range clusterNames {
+		clusters = append(clusters, r.syncToCluster(ctx, cr, clusterName, objects, previous[clusterName].Objects))
+	}
+	// delete the copies from the clusters that are no longer selected
+	for _, cs := range cr.Status.Clusters {
+		if slices.Contains(clusterNames, cs.ClusterName) {
+			continue
+		}
+		if next := r.deleteFromCluster(ctx, cr, cs); next != nil {
+			clusters = append(clusters, *next)
+		}
+	}
+	slices.SortFunc(clusters, func(a, b replicationv1alpha1.ClusterStatus) int {
+		return strings.Compare(a.ClusterName, b.ClusterName)
+	})
+	cr.Status.Clusters = clusters
+	cr.Status.ObservedGeneration = cr.GetGeneration()
+	setReadyCondition(cr)
+
+	// the copies are checked periodically, a copy that was deleted or changed
+	// on the remote cluster is applied again
+	result := ctrl.Result{RequeueAfter: replication.CheckInterval(ctx)}
+	if cr.Spec.ClusterSelector != nil {
+		result.RequeueAfter = min(result.RequeueAfter, resyncPeriod)
+	}
+	for _, cs := range clusters {
+		switch cs.State {
+		case stateFailed:
+			result.RequeueAfter = 30 * time.Second
+		case statePending, stateDeleting:
+			if result.RequeueAfter == 0 || result.RequeueAfter > 10*time.Second {
+				result.RequeueAfter = 10 * time.Second
+			}
+		}
+	}
+	return result, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
+
+// getObjects returns the objects selected by the resources of the RemoteSync
+// in its namespace and makes sure the changes of their kind are watched
+func (r *reconciler) getObjects(ctx context.Context, cr *replicationv1alpha1.RemoteSync) ([]unstructured.Unstructured, error) {
+	objects := []unstructured.Unstructured{}
+	for _, res := range cr.Spec.Resources {
+		gv, err := schema.ParseGroupVersion(res.APIVersion)
+		if err != nil {
+			return nil, err
+		}
+		gvk := gv.WithKind(res.Kind)
+		mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get mapping of %s", gvk)
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return nil, fmt.Errorf("%s is not namespaced", gvk)
+		}
+		selector, err := getSelector(res.Selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector of %s", gvk)
+		}
+		if err := r.watch(gvk); err != nil {
+			return nil, errors.Wrapf(err, "cannot watch %s", gvk)
+		}
+
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, l, client.InNamespace(cr.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, errors.Wrapf(err, "cannot list %s", gvk)
+		}
+		for _, o := range l.Items {
+			if resource.WasDeleted(&o) {
+				continue
+			}
+			o.SetGroupVersionKind(gvk)
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+}
+
+// getClusterNames returns the sorted names of the clusters of the RemoteSync
+func (r *reconciler) getClusterNames(ctx context.Context, cr *replicationv1alpha1.RemoteSync) ([]string, error) {
+	names := sets.New(cr.Spec.ClusterNames...)
+	if cr.Spec.ClusterSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cr.Spec.ClusterSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cluster selector")
+		}
+		selected, err := cluster.Registry{Client: r.Client}.ListClusterNames(ctx, selector)
+		if err != nil {
+			return nil, err
+		}
+		names.Insert(selected...)
+	}
+	names.Delete("")
+	return sets.List(names), nil
+}
+
+// watch adds a watch of the kind to the controller, the RemoteSyncs in the
+// namespace of an object that changed are reconciled
+func (r *reconciler) watch(gvk schema.GroupVersionKind) error {
+	r.watchesMu.Lock()
+	defer r.watchesMu.Unlock()
+	if r.controller == nil || r.watches[gvk] {
+		return nil
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(source.Kind(r.cache, u, handler.TypedEnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o *unstructured.Unstructured) []reconcile.Request {
+			return r.listRemoteSyncs(ctx, o.GetNamespace(), gvk)
+		}))); err != nil {
+		return err
+	}
+	r.watches[gvk] = true
+	return nil
+}
+
+// listRemoteSyncs returns the requests of the RemoteSyncs in the namespace
+// that select objects of the kind
+func (r *reconciler) listRemoteSyncs(ctx context.Context, namespace string, gvk schema.GroupVersionKind) []reconcile.Request {
+	remoteSyncs := &replicationv1alpha1.RemoteSyncList{}
+	if err := r.List(ctx, remoteSyncs, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list remote syncs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, rs := range remoteSyncs.Items {
+		for _, res := range rs.Spec.Resources {
+			if res.APIVersion == gvk.GroupVersion().String() && res.Kind == gvk.Kind {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rs.GetNamespace(), Name: rs.GetName()}})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+func getSelector(ls *metav1.LabelSelector) (labels.Selector, error) {
+	if ls == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(ls)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotesync
+
+import (
+	"context"
+	"testing"
+
+	replicationv1alpha1 "github.com/nephio-project/nephio/controllers/pkg/apis/replication/v1alpha1"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetObjects(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+	cases := map[string]struct {
+		resources []replicationv1alpha1.ResourceSelector
+		want      []string
+		wantErr   bool
+	}{
+		"Selected": {
+			resources: []replicationv1alpha1.ResourceSelector{{APIVersion: "v1", Kind: "ConfigMap", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "spire"}}}},
+			want:      []string{"spire-bundle"},
+		},
+		"ClusterScoped": {
+			resources: []replicationv1alpha1.ResourceSelector{{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"}},
+			wantErr:   true,
+		},
+		"UnknownKind": {
+			resources: []replicationv1alpha1.ResourceSelector{{APIVersion: "example.com/v1", Kind: "Foo"}},
+			wantErr:   true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clientMock := new(mocks.MockClient)
+			clientMock.On("RESTMapper").Return(mapper)
+			clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList"), client.InNamespace("default"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				deleted := newTestObject("ConfigMap", "default", "deleted", nil)
+				now := metav1.Now()
+				deleted.SetDeletionTimestamp(&now)
+				args.Get(1).(*unstructured.UnstructuredList).Items = []unstructured.Unstructured{
+					newTestObject("ConfigMap", "default", "spire-bundle", nil),
+					deleted,
+				}
+			})
+
+			cr := newTestRemoteSync()
+			cr.Spec.Resources = tc.resources
+			r := &reconciler{Client: clientMock}
+			objects, err := r.getObjects(context.TODO(), cr)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			got := []string{}
+			for _, o := range objects {
+				got = append(got, o.GetName())
+				assert.Equal(t, "ConfigMap", o.GetKind())
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestListRemoteSyncs(t *testing.T) {
+	clientMock := new(mocks.MockClient)
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1alpha1.RemoteSyncList"), client.InNamespace("default")).Return(nil).Run(func(args mock.Arguments) {
+		configMaps := newTestRemoteSync()
+		secrets := newTestRemoteSync()
+		secrets.SetName("secrets")
+		secrets.Spec.Resources = []replicationv1alpha1.ResourceSelector{{APIVersion: "v1", Kind: "Secret"}}
+		args.Get(1).(*replicationv1alpha1.RemoteSyncList).Items = []replicationv1alpha1.RemoteSync{*configMaps, *secrets}
+	})
+
+	r := &reconciler{Client: clientMock}
+	got := r.listRemoteSyncs(context.TODO(), "default", schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "spire"}}}, got)
+}
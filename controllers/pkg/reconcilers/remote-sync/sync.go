@@ -0,0 +1,293 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotesync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	replicationv1alpha1 "github.com/nephio-project/nephio/controllers/pkg/apis/replication/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// remoteSyncKey is the annotation of the copies with the <namespace>/<name>
+	// of their RemoteSync, only copies with the annotation are deleted
+	remoteSyncKey = "replication.nephio.org/remote-sync"
+	// renderKey is the annotation of the objects whose string values are
+	// rendered as go templates, the other objects are copied as they are
+	renderKey = "replication.nephio.org/render"
+
+	stateSynced   = "Synced"
+	statePending  = "Pending"
+	stateFailed   = "Failed"
+	stateDeleting = "Deleting"
+)
+
+// templateData is the data of the templates in the string values of the
+// objects
+type templateData struct {
+	ClusterName string
+	Namespace   string
+	Values      map[string]string
+}
+
+// getTemplateData returns the target namespace and values of the cluster, the
+// override of the cluster takes precedence over the spec
+func getTemplateData(cr *replicationv1alpha1.RemoteSync, clusterName string) templateData {
+	data := templateData{
+		ClusterName: clusterName,
+		Namespace:   cr.Spec.TargetNamespace,
+		Values:      maps.Clone(cr.Spec.Values),
+	}
+	if data.Namespace == "" {
+		data.Namespace = cr.GetNamespace()
+	}
+	if data.Values == nil {
+		data.Values = map[string]string{}
+	}
+	for _, o := range cr.Spec.Overrides {
+		if o.ClusterName != clusterName {
+			continue
+		}
+		if o.TargetNamespace != "" {
+			data.Namespace = o.TargetNamespace
+		}
+		maps.Copy(data.Values, o.Values)
+	}
+	return data
+}
+
+func getOwner(cr *replicationv1alpha1.RemoteSync) string {
+	return types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}.String()
+}
+
+func getObjectReference(u *unstructured.Unstructured) replicationv1alpha1.ObjectReference {
+	return replicationv1alpha1.ObjectReference{APIVersion: u.GetAPIVersion(), Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()}
+}
+
+// syncToCluster copies the objects to the cluster and returns the status of
+// the cluster
+func (r *reconciler) syncToCluster(ctx context.Context, cr *replicationv1alpha1.RemoteSync, clusterName string, objects []unstructured.Unstructured, previous []replicationv1alpha1.ObjectReference) replicationv1alpha1.ClusterStatus {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	pending := func(msg string) replicationv1alpha1.ClusterStatus {
+		log.Info(msg)
+		return replicationv1alpha1.ClusterStatus{ClusterName: clusterName, State: statePending, Message: msg, Objects: previous}
+	}
+	clusterClient, found, err := cluster.Registry{Client: r.Client}.GetClusterClient(ctx, clusterName)
+	if err != nil {
+		log.Error(err, "cannot get cluster credentials")
+		return replicationv1alpha1.ClusterStatus{ClusterName: clusterName, State: stateFailed, Message: err.Error(), Objects: previous}
+	}
+	if !found {
+		return pending("cluster client not found")
+	}
+	remoteClient, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		log.Error(err, "cannot get clusterClient")
+		return replicationv1alpha1.ClusterStatus{ClusterName: clusterName, State: stateFailed, Message: err.Error(), Objects: previous}
+	}
+	if !ready {
+		return pending("cluster not ready")
+	}
+	return syncObjects(ctx, r.replicator, remoteClient, cr, clusterName, objects, previous)
+}
+
+// syncObjects applies the copies of the objects to the cluster and deletes the
+// previous copies that are no longer selected. The status of a failed cluster
+// keeps the previous copies so they are deleted later.
+func syncObjects(ctx context.Context, replicator *replication.Replicator, remoteClient resource.APIPatchingApplicator, cr *replicationv1alpha1.RemoteSync, clusterName string, objects []unstructured.Unstructured, previous []replicationv1alpha1.ObjectReference) replicationv1alpha1.ClusterStatus {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	status := replicationv1alpha1.ClusterStatus{ClusterName: clusterName}
+	failed := func(err error, msg string) replicationv1alpha1.ClusterStatus {
+		log.Error(err, msg)
+		status.State = stateFailed
+		status.Message = errors.Wrap(err, msg).Error()
+		for _, ref := range previous {
+			if !slices.Contains(status.Objects, ref) {
+				status.Objects = append(status.Objects, ref)
+			}
+		}
+		return status
+	}
+
+	data := getTemplateData(cr, clusterName)
+	exists, err := replication.NamespaceExists(ctx, remoteClient, data.Namespace)
+	if err != nil {
+		return failed(err, fmt.Sprintf("cannot get namespace: %s", data.Namespace))
+	}
+	if !exists {
+		msg := fmt.Sprintf("namespace: %s, does not exist", data.Namespace)
+		log.Info(msg)
+		return replicationv1alpha1.ClusterStatus{ClusterName: clusterName, State: statePending, Message: msg, Objects: previous}
+	}
+
+	for i := range objects {
+		newu := replication.RemoteCopy(&objects[i], data.Namespace)
+		if objects[i].GetAnnotations()[renderKey] == "true" {
+			if err := replication.Render(newu, data); err != nil {
+				return failed(err, fmt.Sprintf("cannot render %s %s", newu.GetKind(), newu.GetName()))
+			}
+		}
+		annotations := newu.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[remoteSyncKey] = getOwner(cr)
+		newu.SetAnnotations(annotations)
+		if _, err := replicator.Apply(ctx, remoteClient, clusterName, newu); err != nil {
+			return failed(err, fmt.Sprintf("cannot apply %s %s", newu.GetKind(), newu.GetName()))
+		}
+		status.Objects = append(status.Objects, getObjectReference(newu))
+	}
+
+	stale := []replicationv1alpha1.ObjectReference{}
+	for _, ref := range previous {
+		if !slices.Contains(status.Objects, ref) {
+			stale = append(stale, ref)
+		}
+	}
+	if err := deleteObjects(ctx, remoteClient, getOwner(cr), stale); err != nil {
+		return failed(err, "cannot delete objects")
+	}
+	forget(replicator, clusterName, stale)
+	status.State = stateSynced
+	return status
+}
+
+// deleteFromCluster deletes the copies in the status from the cluster, it
+// returns the next status of the cluster, nil when the copies are deleted. A
+// cluster whose credentials are gone is considered deleted together with the
+// copies.
+func (r *reconciler) deleteFromCluster(ctx context.Context, cr *replicationv1alpha1.RemoteSync, cs replicationv1alpha1.ClusterStatus) *replicationv1alpha1.ClusterStatus {
+	log := log.FromContext(ctx).WithValues("cluster", cs.ClusterName)
+	deleting := func(msg string) *replicationv1alpha1.ClusterStatus {
+		return &replicationv1alpha1.ClusterStatus{ClusterName: cs.ClusterName, State: stateDeleting, Message: msg, Objects: cs.Objects}
+	}
+	if len(cs.Objects) == 0 {
+		return nil
+	}
+	clusterClient, found, err := cluster.Registry{Client: r.Client}.GetClusterClient(ctx, cs.ClusterName)
+	if err != nil {
+		log.Error(err, "cannot get cluster credentials")
+		return deleting(err.Error())
+	}
+	if !found {
+		log.Info("cluster credentials not found, skip deleting objects")
+		return nil
+	}
+	remoteClient, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		log.Error(err, "cannot get clusterClient")
+		return deleting(err.Error())
+	}
+	if !ready {
+		return deleting("cluster not ready")
+	}
+	if err := deleteObjects(ctx, remoteClient, getOwner(cr), cs.Objects); err != nil {
+		log.Error(err, "cannot delete objects")
+		return deleting(err.Error())
+	}
+	forget(r.replicator, cs.ClusterName, cs.Objects)
+	log.Info("objects deleted", "objects", len(cs.Objects))
+	return nil
+}
+
+// forget removes the deleted copies from the replicator, they are not healed
+// when they are selected again
+func forget(replicator *replication.Replicator, clusterName string, refs []replicationv1alpha1.ObjectReference) {
+	for _, ref := range refs {
+		replicator.Forget(clusterName, ref.Kind, ref.Namespace, ref.Name)
+	}
+}
+
+// deleteObjects deletes the copies of the owner, objects that are not copies
+// of the owner are left alone
+func deleteObjects(ctx context.Context, c client.Client, owner string, refs []replicationv1alpha1.ObjectReference) error {
+	for _, ref := range refs {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, u); err != nil {
+			if resource.IgnoreNotFound(err) == nil || meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if u.GetAnnotations()[remoteSyncKey] != owner {
+			continue
+		}
+		if err := c.Delete(ctx, u); resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setReadyCondition sets the Ready condition from the state of the clusters
+func setReadyCondition(cr *replicationv1alpha1.RemoteSync) {
+	notSynced := []string{}
+	reason := stateSynced
+	for _, cs := range cr.Status.Clusters {
+		if cs.State == stateSynced {
+			continue
+		}
+		notSynced = append(notSynced, fmt.Sprintf("%s: %s", cs.ClusterName, cs.State))
+		if reason != stateFailed {
+			reason = cs.State
+		}
+	}
+	if len(notSynced) == 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: cr.GetGeneration(),
+			Reason:             stateSynced,
+			Message:            fmt.Sprintf("synced to %d clusters", len(cr.Status.Clusters)),
+		})
+		return
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cr.GetGeneration(),
+		Reason:             reason,
+		Message:            strings.Join(notSynced, ", "),
+	})
+}
+
+func failedCondition(cr *replicationv1alpha1.RemoteSync, msg string) metav1.Condition {
+	return metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cr.GetGeneration(),
+		Reason:             stateFailed,
+		Message:            msg,
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotesync
+
+import (
+	"context"
+	"testing"
+
+	replicationv1alpha1 "github.com/nephio-project/nephio/controllers/pkg/apis/replication/v1alpha1"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestRemoteSync() *replicationv1alpha1.RemoteSync {
+	return &replicationv1alpha1.RemoteSync{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "spire"},
+		Spec: replicationv1alpha1.RemoteSyncSpec{
+			Resources:       []replicationv1alpha1.ResourceSelector{{APIVersion: "v1", Kind: "ConfigMap"}},
+			TargetNamespace: "spire",
+			Values:          map[string]string{"region": "west", "zone": "a"},
+			Overrides: []replicationv1alpha1.ClusterOverride{
+				{ClusterName: "edge02", TargetNamespace: "spire-system", Values: map[string]string{"zone": "b"}},
+			},
+		},
+	}
+}
+
+func newTestObject(kind, namespace, name string, data map[string]any) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]any{"data": data}}
+	u.SetAPIVersion("v1")
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func newRenderedObject(kind, namespace, name string, data map[string]any) unstructured.Unstructured {
+	u := newTestObject(kind, namespace, name, data)
+	u.SetAnnotations(map[string]string{renderKey: "true"})
+	return u
+}
+
+func TestGetTemplateData(t *testing.T) {
+	cases := map[string]struct {
+		clusterName string
+		targetNs    string
+		want        templateData
+	}{
+		"Spec": {
+			clusterName: "edge01",
+			targetNs:    "spire",
+			want:        templateData{ClusterName: "edge01", Namespace: "spire", Values: map[string]string{"region": "west", "zone": "a"}},
+		},
+		"Override": {
+			clusterName: "edge02",
+			targetNs:    "spire",
+			want:        templateData{ClusterName: "edge02", Namespace: "spire-system", Values: map[string]string{"region": "west", "zone": "b"}},
+		},
+		"DefaultNamespace": {
+			clusterName: "edge01",
+			want:        templateData{ClusterName: "edge01", Namespace: "default", Values: map[string]string{"region": "west", "zone": "a"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := newTestRemoteSync()
+			cr.Spec.TargetNamespace = tc.targetNs
+			assert.Equal(t, tc.want, getTemplateData(cr, tc.clusterName))
+			// the values of the spec are not modified by the override
+			assert.Equal(t, "a", cr.Spec.Values["zone"])
+		})
+	}
+}
+
+func TestSyncObjects(t *testing.T) {
+	notFound := func(name string) error {
+		return kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	stale := replicationv1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "stale"}
+	cases := map[string]struct {
+		namespaceErr error
+		objects      []unstructured.Unstructured
+		wantState    string
+		wantCreated  map[string]any
+		wantDeleted  []string
+		wantObjects  []replicationv1alpha1.ObjectReference
+	}{
+		"Synced": {
+			objects: []unstructured.Unstructured{
+				newRenderedObject("ConfigMap", "default", "agent", map[string]any{"cluster": "{{ .ClusterName }}", "region": "{{ .Values.region }}"}),
+			},
+			wantState:   stateSynced,
+			wantCreated: map[string]any{"cluster": "edge01", "region": "west"},
+			wantDeleted: []string{"stale"},
+			wantObjects: []replicationv1alpha1.ObjectReference{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "agent"}},
+		},
+		"NotRendered": {
+			objects: []unstructured.Unstructured{
+				newTestObject("ConfigMap", "default", "agent", map[string]any{"rule": "{{ $labels.instance }} is down"}),
+			},
+			wantState:   stateSynced,
+			wantCreated: map[string]any{"rule": "{{ $labels.instance }} is down"},
+			wantDeleted: []string{"stale"},
+			wantObjects: []replicationv1alpha1.ObjectReference{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "agent"}},
+		},
+		"NamespaceNotFound": {
+			namespaceErr: kerrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "spire"),
+			wantState:    statePending,
+			wantObjects:  []replicationv1alpha1.ObjectReference{stale},
+		},
+		"RenderFailed": {
+			objects: []unstructured.Unstructured{
+				newRenderedObject("ConfigMap", "default", "agent", map[string]any{"cluster": "{{ .Values.missing }}"}),
+			},
+			wantState:   stateFailed,
+			wantObjects: []replicationv1alpha1.ObjectReference{stale},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var created map[string]any
+			deleted := []string{}
+			remoteMock := new(mocks.MockClient)
+			remoteMock.On("Get", context.TODO(), types.NamespacedName{Name: "spire"}, mock.AnythingOfType("*v1.Namespace")).Return(tc.namespaceErr)
+			remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "agent"}, mock.AnythingOfType("*unstructured.Unstructured")).Return(notFound("agent"))
+			remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "stale"}, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*unstructured.Unstructured).SetName("stale")
+				args.Get(2).(*unstructured.Unstructured).SetAnnotations(map[string]string{remoteSyncKey: "default/spire"})
+			})
+			remoteMock.On("Create", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+				u := args.Get(1).(*unstructured.Unstructured)
+				assert.Equal(t, "default/spire", u.GetAnnotations()[remoteSyncKey])
+				assert.NotEmpty(t, u.GetAnnotations()[replication.ContentHashKey])
+				created, _, _ = unstructured.NestedMap(u.Object, "data")
+			})
+			remoteMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+				deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+			})
+
+			status := syncObjects(context.TODO(), replication.NewReplicator("test"), resource.NewAPIPatchingApplicator(remoteMock), newTestRemoteSync(), "edge01", tc.objects, []replicationv1alpha1.ObjectReference{stale})
+			assert.Equal(t, tc.wantState, status.State)
+			assert.Equal(t, tc.wantCreated, created)
+			if tc.wantDeleted == nil {
+				tc.wantDeleted = []string{}
+			}
+			assert.Equal(t, tc.wantDeleted, deleted)
+			assert.Equal(t, tc.wantObjects, status.Objects)
+		})
+	}
+}
+
+func TestDeleteObjects(t *testing.T) {
+	refs := []replicationv1alpha1.ObjectReference{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "owned"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "other"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "spire", Name: "gone"},
+	}
+	deleted := []string{}
+	remoteMock := new(mocks.MockClient)
+	remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "owned"}, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*unstructured.Unstructured).SetName("owned")
+		args.Get(2).(*unstructured.Unstructured).SetAnnotations(map[string]string{remoteSyncKey: "default/spire"})
+	})
+	remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "other"}, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*unstructured.Unstructured).SetName("other")
+		args.Get(2).(*unstructured.Unstructured).SetAnnotations(map[string]string{remoteSyncKey: "default/other"})
+	})
+	remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "gone"}, mock.AnythingOfType("*unstructured.Unstructured")).Return(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "gone"))
+	remoteMock.On("Delete", context.TODO(), mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		deleted = append(deleted, args.Get(1).(*unstructured.Unstructured).GetName())
+	})
+
+	assert.NoError(t, deleteObjects(context.TODO(), remoteMock, "default/spire", refs))
+	assert.Equal(t, []string{"owned"}, deleted)
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	cases := map[string]struct {
+		states     []string
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		"Synced":  {states: []string{stateSynced, stateSynced}, wantStatus: metav1.ConditionTrue, wantReason: stateSynced},
+		"Pending": {states: []string{stateSynced, statePending}, wantStatus: metav1.ConditionFalse, wantReason: statePending},
+		"Failed":  {states: []string{stateFailed, statePending}, wantStatus: metav1.ConditionFalse, wantReason: stateFailed},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := newTestRemoteSync()
+			for i, state := range tc.states {
+				cr.Status.Clusters = append(cr.Status.Clusters, replicationv1alpha1.ClusterStatus{ClusterName: string(rune('a' + i)), State: state})
+			}
+			setReadyCondition(cr)
+			assert.Len(t, cr.Status.Conditions, 1)
+			assert.Equal(t, tc.wantStatus, cr.Status.Conditions[0].Status)
+			assert.Equal(t, tc.wantReason, cr.Status.Conditions[0].Reason)
+		})
+	}
+}
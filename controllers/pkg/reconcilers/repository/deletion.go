@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"os"
+	"time"
+
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// deletionPolicyKey overwrites the deletion policy of the lifecycle, it
+	// also accepts the archive policy which the lifecycle api does not support
+	deletionPolicyKey = "repository.nephio.org/deletion-policy"
+	// forceDeleteKey skips the check for recent commits when set to "true"
+	forceDeleteKey = "repository.nephio.org/force-delete"
+
+	// DeletionArchive archives the repository in the git server, it becomes
+	// read-only and keeps its content
+	DeletionArchive commonv1alpha1.DeletionPolicy = "archive"
+
+	// defaultDeletionMinAge is the minimum age of the latest commit of a
+	// repository before it is deleted, overwritten by REPOSITORY_DELETION_MIN_AGE.
+	// The check is opt-in, repositories with recent commits (auto init,
+	// seeding) are deleted by default
+	defaultDeletionMinAge = 0
+)
+
+// getDeletionMinAge returns the minimum age of the latest commit of a
+// repository to be deleted, 0 disables the check
+func getDeletionMinAge(ctx context.Context) time.Duration {
+	v, ok := os.LookupEnv("REPOSITORY_DELETION_MIN_AGE")
+	if !ok {
+		return defaultDeletionMinAge
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.FromContext(ctx).Error(err, "invalid REPOSITORY_DELETION_MIN_AGE, using default", "value", v, "default", defaultDeletionMinAge)
+		return defaultDeletionMinAge
+	}
+	return d
+}
+
+// getDeletionPolicy returns the deletion policy of the annotation, or of the
+// lifecycle when the annotation is not set or invalid
+func getDeletionPolicy(cr *infrav1alpha1.Repository) commonv1alpha1.DeletionPolicy {
+	switch policy := commonv1alpha1.DeletionPolicy(cr.GetAnnotations()[deletionPolicyKey]); policy {
+	case commonv1alpha1.DeletionDelete, commonv1alpha1.DeletionOrphan, DeletionArchive:
+		return policy
+	}
+	if cr.Spec.Lifecycle.DeletionPolicy == "" {
+		return commonv1alpha1.DeletionDelete
+	}
+	return cr.Spec.Lifecycle.DeletionPolicy
+}
+
+// checkDeletion returns how long the deletion of the repository is refused,
+// the repository is not deleted when its latest commit is newer than the
+// minimum age unless the force delete annotation is set
+func (r *reconciler) checkDeletion(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) (time.Duration, error) {
+	log := log.FromContext(ctx)
+	if r.deletionMinAge <= 0 || cr.GetAnnotations()[forceDeleteKey] == "true" {
+		return 0, nil
+	}
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		return 0, err
+	}
+	commit, err := gitClient.GetLatestCommit(getRepoOwner(cr, u.UserName), cr.GetName())
+	if err != nil {
+		if gitprovider.IsNotFound(err) {
+			return 0, nil
+		}
+		log.Error(err, "cannot get latest commit")
+		return 0, err
+	}
+	if commit == nil {
+		return 0, nil
+	}
+	if age := time.Since(commit.Created); age < r.deletionMinAge {
+		log.Info("repo has recent commits, deletion refused", "name", cr.GetName(), "commit", commit.SHA, "age", age.Round(time.Second))
+		return r.deletionMinAge - age, nil
+	}
+	return 0, nil
+}
+
+// archiveRepo archives the repository in the git server, a repository that
+// does not exist is ignored
+func (r *reconciler) archiveRepo(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	owner := getRepoOwner(cr, u.UserName)
+	repo, err := gitClient.GetRepo(owner, cr.GetName())
+	if err != nil {
+		if gitprovider.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "cannot get repo")
+		cr.SetConditions(infrav1alpha1.Failed("cannot get repo"))
+		return err
+	}
+	if repo.Archived {
+		return nil
+	}
+	if _, err := gitClient.EditRepo(owner, cr.GetName(), gitprovider.EditRepoOptions{Archived: ptr.To(true)}); err != nil {
+		log.Error(err, "cannot archive repo")
+		cr.SetConditions(infrav1alpha1.Failed("cannot archive repo"))
+		return err
+	}
+	log.Info("repo archived", "name", cr.GetName())
+	return nil
+}
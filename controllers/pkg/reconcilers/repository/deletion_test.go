@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetDeletionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		annotation string
+		lifecycle  commonv1alpha1.DeletionPolicy
+		want       commonv1alpha1.DeletionPolicy
+	}{
+		"default":            {want: commonv1alpha1.DeletionDelete},
+		"lifecycle":          {lifecycle: commonv1alpha1.DeletionOrphan, want: commonv1alpha1.DeletionOrphan},
+		"archive annotation": {annotation: "archive", lifecycle: commonv1alpha1.DeletionDelete, want: DeletionArchive},
+		"invalid annotation": {annotation: "keep", lifecycle: commonv1alpha1.DeletionOrphan, want: commonv1alpha1.DeletionOrphan},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := newPorchTestRepository(map[string]string{deletionPolicyKey: tt.annotation})
+			cr.Spec.Lifecycle.DeletionPolicy = tt.lifecycle
+			if got := getDeletionPolicy(cr); got != tt.want {
+				t.Errorf("getDeletionPolicy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDeletionMinAge(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		set   bool
+		want  time.Duration
+	}{
+		"default disabled": {want: 0},
+		"set":              {value: "24h", set: true, want: 24 * time.Hour},
+		"invalid":          {value: "a day", set: true, want: 0},
+		"negative":         {value: "-1h", set: true, want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv("REPOSITORY_DELETION_MIN_AGE", tt.value)
+			}
+			if got := getDeletionMinAge(context.TODO()); got != tt.want {
+				t.Errorf("getDeletionMinAge() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDeletion(t *testing.T) {
+	tests := map[string]struct {
+		minAge      time.Duration
+		annotations map[string]string
+		commit      *gitprovider.Commit
+		commitErr   error
+		wantWait    bool
+		wantErr     bool
+	}{
+		"disabled": {
+			commit: &gitprovider.Commit{SHA: "c1", Created: time.Now()},
+		},
+		"old commit": {
+			minAge: time.Hour,
+			commit: &gitprovider.Commit{SHA: "c1", Created: time.Now().Add(-2 * time.Hour)},
+		},
+		"recent commit": {
+			minAge:   time.Hour,
+			commit:   &gitprovider.Commit{SHA: "c1", Created: time.Now().Add(-time.Minute)},
+			wantWait: true,
+		},
+		"recent commit forced": {
+			minAge:      time.Hour,
+			annotations: map[string]string{forceDeleteKey: "true"},
+			commit:      &gitprovider.Commit{SHA: "c1", Created: time.Now()},
+		},
+		"empty repo": {
+			minAge: time.Hour,
+		},
+		"repo not found": {
+			minAge:    time.Hour,
+			commitErr: gitprovider.ErrNotFound,
+		},
+		"git server error": {
+			minAge:    time.Hour,
+			commitErr: context.DeadlineExceeded,
+			wantErr:   true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gitClient := new(gitprovider.MockGitClient)
+			gitClient.On("GetMyUserInfo").Return(&gitprovider.User{UserName: "gitea"}, nil)
+			gitClient.On("GetLatestCommit", "gitea", "edge01").Return(tt.commit, tt.commitErr)
+
+			r := &reconciler{deletionMinAge: tt.minAge}
+			wait, err := r.checkDeletion(context.TODO(), gitClient, newPorchTestRepository(tt.annotations))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkDeletion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (wait > 0) != tt.wantWait || wait > tt.minAge {
+				t.Errorf("checkDeletion() wait = %s, want wait %t", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestArchiveRepo(t *testing.T) {
+	tests := map[string]struct {
+		repo        *gitprovider.Repository
+		repoErr     error
+		wantArchive bool
+	}{
+		"archive": {
+			repo:        &gitprovider.Repository{Name: "edge01"},
+			wantArchive: true,
+		},
+		"already archived": {
+			repo: &gitprovider.Repository{Name: "edge01", Archived: true},
+		},
+		"not found": {
+			repoErr: gitprovider.ErrNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gitClient := new(gitprovider.MockGitClient)
+			gitClient.On("GetMyUserInfo").Return(&gitprovider.User{UserName: "gitea"}, nil)
+			gitClient.On("GetRepo", "gitea", "edge01").Return(tt.repo, tt.repoErr)
+			gitClient.On("EditRepo", "gitea", "edge01", gitprovider.EditRepoOptions{Archived: ptr.To(true)}).Return(&gitprovider.Repository{Archived: true}, nil)
+
+			r := &reconciler{}
+			if err := r.archiveRepo(context.TODO(), gitClient, newPorchTestRepository(nil)); err != nil {
+				t.Fatalf("archiveRepo() error = %v", err)
+			}
+			gitClient.AssertNumberOfCalls(t, "EditRepo", map[bool]int{true: 1, false: 0}[tt.wantArchive])
+		})
+	}
+}
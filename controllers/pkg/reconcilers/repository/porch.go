@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// porchRegistrationKey enables the registration of the repository in
+	// porch when set to "true"
+	porchRegistrationKey = "repository.nephio.org/porch-registration"
+	// porchTokenKey is the name of the Token that supplies the credentials of
+	// the porch Repository, the default is <repo>-access-token-porch
+	porchTokenKey = "repository.nephio.org/token"
+	// porchDeploymentKey marks the porch Repository as deployment repository
+	// when set to "true"
+	porchDeploymentKey = "nephio.org/deployment"
+	// porchStagingKey is carried over to the porch Repository, it marks the
+	// staging repository of the bootstrap packages
+	porchStagingKey = "nephio.org/staging"
+
+	defaultBranch = "main"
+
+	// ConditionTypePorchRegistration reports the registration of the repository
+	// in porch
+	ConditionTypePorchRegistration infrav1alpha1.ConditionType   = "PorchRegistration"
+	ConditionReasonRegistered      infrav1alpha1.ConditionReason = "Registered"
+)
+
+func porchRegistrationEnabled(cr *infrav1alpha1.Repository) bool {
+	return cr.GetAnnotations()[porchRegistrationKey] == "true"
+}
+
+func getPorchTokenName(cr *infrav1alpha1.Repository) string {
+	if name := cr.GetAnnotations()[porchTokenKey]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-access-token-porch", cr.GetName())
+}
+
+// buildPorchRepository returns the porch Repository of the repository, the
+// credentials are in the secret of the token which has the name of the token
+func buildPorchRepository(cr *infrav1alpha1.Repository, tokenName string) *porchconfigv1alpha1.Repository {
+	annotations := map[string]string{}
+	if v, ok := cr.GetAnnotations()[porchStagingKey]; ok {
+		annotations[porchStagingKey] = v
+	}
+	branch := defaultBranch
+	if cr.Spec.DefaultBranch != nil && *cr.Spec.DefaultBranch != "" {
+		branch = *cr.Spec.DefaultBranch
+	}
+	description := ""
+	if cr.Spec.Description != nil {
+		description = *cr.Spec.Description
+	}
+
+	return &porchconfigv1alpha1.Repository{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: porchconfigv1alpha1.GroupVersion.String(),
+			Kind:       porchconfigv1alpha1.TypeRepository.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cr.GetNamespace(),
+			Name:        cr.GetName(),
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: infrav1alpha1.GroupVersion.String(),
+					Kind:       infrav1alpha1.RepositoryKind,
+					Name:       cr.GetName(),
+					UID:        cr.GetUID(),
+					Controller: ptr.To(true),
+				},
+			},
+		},
+		Spec: porchconfigv1alpha1.RepositorySpec{
+			Description: description,
+			Deployment:  cr.GetAnnotations()[porchDeploymentKey] == "true",
+			Type:        porchconfigv1alpha1.RepositoryTypeGit,
+			Git: &porchconfigv1alpha1.GitRepository{
+				Repo:      *cr.Status.URL,
+				Branch:    branch,
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: tokenName},
+			},
+		},
+	}
+}
+
+// upsertPorchRepository registers the repository in porch once it exists in
+// the git server, the credentials are supplied by the matching Token
+func (r *reconciler) upsertPorchRepository(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	if !porchRegistrationEnabled(cr) || cr.Status.URL == nil {
+		return nil
+	}
+
+	tokenName := getPorchTokenName(cr)
+	token := &infrav1alpha1.Token{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: tokenName}, token); err != nil {
+		msg := fmt.Sprintf("cannot get token %s for porch repository", tokenName)
+		log.Error(err, msg)
+		cr.SetConditions(porchRegistrationFailed(msg))
+		return err
+	}
+
+	porchRepo := buildPorchRepository(cr, token.GetName())
+	if err := r.porchClient.Apply(ctx, porchRepo); err != nil {
+		log.Error(err, "cannot apply porch repository")
+		cr.SetConditions(porchRegistrationFailed("cannot apply porch repository"))
+		return err
+	}
+	log.Info("porch repository applied", "name", porchRepo.GetName(), "url", porchRepo.Spec.Git.Repo)
+	cr.SetConditions(porchRegistered(porchRepo.GetName()))
+	return nil
+}
+
+// deletePorchRepository removes the porch Repository according to the
+// deletion policy, with the orphan policy the owner reference is removed so
+// the porch Repository is not garbage collected
+func (r *reconciler) deletePorchRepository(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	porchRepo := &porchconfigv1alpha1.Repository{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, porchRepo); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			log.Error(err, "cannot get porch repository")
+			cr.SetConditions(porchRegistrationFailed("cannot get porch repository"))
+			return err
+		}
+		return nil
+	}
+	// only the porch Repository created by this reconciler is handled
+	owner := metav1.GetControllerOf(porchRepo)
+	if owner == nil || owner.UID != cr.GetUID() {
+		return nil
+	}
+
+	if getDeletionPolicy(cr) == commonv1alpha1.DeletionOrphan {
+		refs := []metav1.OwnerReference{}
+		for _, ref := range porchRepo.GetOwnerReferences() {
+			if ref.UID != cr.GetUID() {
+				refs = append(refs, ref)
+			}
+		}
+		porchRepo.SetOwnerReferences(refs)
+		if err := r.porchClient.Update(ctx, porchRepo); err != nil {
+			log.Error(err, "cannot orphan porch repository")
+			cr.SetConditions(porchRegistrationFailed("cannot orphan porch repository"))
+			return err
+		}
+		log.Info("porch repository orphaned", "name", porchRepo.GetName())
+		return nil
+	}
+
+	if err := r.porchClient.Delete(ctx, porchRepo); resource.IgnoreNotFound(err) != nil {
+		log.Error(err, "cannot delete porch repository")
+		cr.SetConditions(porchRegistrationFailed("cannot delete porch repository"))
+		return err
+	}
+	log.Info("porch repository deleted", "name", porchRepo.GetName())
+	return nil
+}
+
+func porchRegistered(name string) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypePorchRegistration),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(ConditionReasonRegistered),
+		Message:            fmt.Sprintf("registered as porch repository %s", name),
+	}}
+}
+
+func porchRegistrationFailed(msg string) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypePorchRegistration),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(infrav1alpha1.ConditionReasonFailed),
+		Message:            msg,
+	}}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func newPorchTestRepository(annotations map[string]string) *infrav1alpha1.Repository {
+	return &infrav1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "edge01",
+			UID:         "1234",
+			Annotations: annotations,
+		},
+		Status: infrav1alpha1.RepositoryStatus{URL: ptr.To("http://gitea/nephio/edge01.git")},
+	}
+}
+
+func TestBuildPorchRepository(t *testing.T) {
+	cr := newPorchTestRepository(map[string]string{
+		porchRegistrationKey: "true",
+		porchDeploymentKey:   "true",
+		porchStagingKey:      "true",
+		"other":              "value",
+	})
+	cr.Spec.DefaultBranch = ptr.To("develop")
+
+	got := buildPorchRepository(cr, "edge01-access-token-porch")
+	if got.GetNamespace() != "default" || got.GetName() != "edge01" {
+		t.Errorf("buildPorchRepository() got %s/%s, want default/edge01", got.GetNamespace(), got.GetName())
+	}
+	if _, ok := got.GetAnnotations()[porchStagingKey]; !ok || len(got.GetAnnotations()) != 1 {
+		t.Errorf("buildPorchRepository() annotations = %v, want only %s", got.GetAnnotations(), porchStagingKey)
+	}
+	if !got.Spec.Deployment || got.Spec.Type != porchconfigv1alpha1.RepositoryTypeGit {
+		t.Errorf("buildPorchRepository() spec = %+v, want git deployment repository", got.Spec)
+	}
+	wantGit := porchconfigv1alpha1.GitRepository{
+		Repo:      "http://gitea/nephio/edge01.git",
+		Branch:    "develop",
+		SecretRef: porchconfigv1alpha1.SecretRef{Name: "edge01-access-token-porch"},
+	}
+	if *got.Spec.Git != wantGit {
+		t.Errorf("buildPorchRepository() git = %+v, want %+v", *got.Spec.Git, wantGit)
+	}
+	if owner := metav1.GetControllerOf(got); owner == nil || owner.UID != cr.GetUID() || owner.Kind != infrav1alpha1.RepositoryKind {
+		t.Errorf("buildPorchRepository() owner = %+v, want the infra repository", owner)
+	}
+}
+
+func TestUpsertPorchRepository(t *testing.T) {
+	notFound := kerrors.NewNotFound(schema.GroupResource{Group: infrav1alpha1.GroupVersion.Group, Resource: "tokens"}, "edge01-access-token-porch")
+
+	tests := map[string]struct {
+		annotations map[string]string
+		url         *string
+		tokenErr    error
+		wantErr     bool
+		wantApply   bool
+	}{
+		"disabled": {},
+		"repo without url": {
+			annotations: map[string]string{porchRegistrationKey: "true"},
+		},
+		"token not found": {
+			annotations: map[string]string{porchRegistrationKey: "true"},
+			url:         ptr.To("http://gitea/nephio/edge01.git"),
+			tokenErr:    notFound,
+			wantErr:     true,
+		},
+		"registered": {
+			annotations: map[string]string{porchRegistrationKey: "true"},
+			url:         ptr.To("http://gitea/nephio/edge01.git"),
+			wantApply:   true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := newPorchTestRepository(tt.annotations)
+			cr.Status.URL = tt.url
+
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "edge01-access-token-porch"}, mock.AnythingOfType("*v1alpha1.Token")).Return(tt.tokenErr).Run(func(args mock.Arguments) {
+				args.Get(2).(*infrav1alpha1.Token).SetName("edge01-access-token-porch")
+			})
+			porchMock := new(mocks.MockClient)
+			porchMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "edge01"}, mock.AnythingOfType("*v1alpha1.Repository")).Return(nil)
+			porchMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1alpha1.Repository"), mock.AnythingOfType("*resource.patch")).Return(nil)
+
+			r := &reconciler{
+				APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock),
+				porchClient:           resource.NewAPIPatchingApplicator(porchMock),
+			}
+			if err := r.upsertPorchRepository(context.TODO(), cr); (err != nil) != tt.wantErr {
+				t.Fatalf("upsertPorchRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			porchMock.AssertNumberOfCalls(t, "Patch", map[bool]int{true: 1, false: 0}[tt.wantApply])
+			if tt.wantApply && cr.GetCondition(ConditionTypePorchRegistration).Status != metav1.ConditionTrue {
+				t.Errorf("upsertPorchRepository() condition = %+v, want registered", cr.GetCondition(ConditionTypePorchRegistration))
+			}
+			if tt.wantErr && cr.GetCondition(ConditionTypePorchRegistration).Status != metav1.ConditionFalse {
+				t.Errorf("upsertPorchRepository() condition = %+v, want failed", cr.GetCondition(ConditionTypePorchRegistration))
+			}
+		})
+	}
+}
+
+func TestDeletePorchRepository(t *testing.T) {
+	tests := map[string]struct {
+		deletionPolicy commonv1alpha1.DeletionPolicy
+		ownerUID       types.UID
+		wantDelete     bool
+		wantUpdate     bool
+	}{
+		"delete": {
+			deletionPolicy: commonv1alpha1.DeletionDelete,
+			ownerUID:       "1234",
+			wantDelete:     true,
+		},
+		"orphan": {
+			deletionPolicy: commonv1alpha1.DeletionOrphan,
+			ownerUID:       "1234",
+			wantUpdate:     true,
+		},
+		"not owned": {
+			deletionPolicy: commonv1alpha1.DeletionDelete,
+			ownerUID:       "5678",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := newPorchTestRepository(nil)
+			cr.Spec.Lifecycle.DeletionPolicy = tt.deletionPolicy
+
+			var updated *porchconfigv1alpha1.Repository
+			porchMock := new(mocks.MockClient)
+			porchMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "edge01"}, mock.AnythingOfType("*v1alpha1.Repository")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*porchconfigv1alpha1.Repository).SetOwnerReferences([]metav1.OwnerReference{
+					{Kind: infrav1alpha1.RepositoryKind, Name: "edge01", UID: tt.ownerUID, Controller: ptr.To(true)},
+				})
+			})
+			porchMock.On("Delete", context.TODO(), mock.AnythingOfType("*v1alpha1.Repository")).Return(nil)
+			porchMock.On("Update", context.TODO(), mock.AnythingOfType("*v1alpha1.Repository")).Return(nil).Run(func(args mock.Arguments) {
+				updated = args.Get(1).(*porchconfigv1alpha1.Repository)
+			})
+
+			r := &reconciler{porchClient: resource.NewAPIPatchingApplicator(porchMock)}
+			if err := r.deletePorchRepository(context.TODO(), cr); err != nil {
+				t.Fatalf("deletePorchRepository() error = %v", err)
+			}
+			porchMock.AssertNumberOfCalls(t, "Delete", map[bool]int{true: 1, false: 0}[tt.wantDelete])
+			porchMock.AssertNumberOfCalls(t, "Update", map[bool]int{true: 1, false: 0}[tt.wantUpdate])
+			if tt.wantUpdate && len(updated.GetOwnerReferences()) != 0 {
+				t.Errorf("deletePorchRepository() owner references = %v, want none", updated.GetOwnerReferences())
+			}
+		})
+	}
+}
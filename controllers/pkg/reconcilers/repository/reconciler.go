@@ -20,20 +20,27 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
-	"code.gitea.io/sdk/gitea"
 	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	porchconfigv1alpha1 "github.com/nephio-project/porch/api/porchconfig/v1alpha1"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func init() {
@@ -47,17 +54,21 @@ const (
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisionresources,verbs=get
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git client, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
 
 	var e error
-	r.giteaClient, e = giteaclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	r.gitClient, e = gitprovider.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
 	if e != nil {
 		return nil, e
 	}
@@ -69,20 +80,54 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	if err := infrav1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
 		return nil, err
 	}
+	if err := porchconfigv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
 
 	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
+	r.porchClient = resource.NewAPIPatchingApplicator(cfg.PorchClient)
 	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.deletionMinAge = getDeletionMinAge(ctx)
+	r.resyncInterval = getResyncInterval(ctx)
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("RepositoryController").
 		For(&infrav1alpha1.Repository{}).
+		// restore the registered porch Repository when it is deleted or its
+		// spec is changed, status updates of porch are ignored
+		Owns(&porchconfigv1alpha1.Repository{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// reconnect the git client when its secret changes and requeue the
+		// repositories once it reconnected
+		Watches(&corev1.Secret{}, gitprovider.SecretEventHandler(r.gitClient)).
+		WatchesRawSource(gitprovider.ReconnectSource(ctx, r.gitClient, r.listRepositories)).
 		Complete(r)
 }
 
+// listRepositories returns the requests of all repositories
+func (r *reconciler) listRepositories(ctx context.Context, _ client.Object) []reconcile.Request {
+	repos := &infrav1alpha1.RepositoryList{}
+	if err := r.List(ctx, repos); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list repositories")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(repos.Items))
+	for _, repo := range repos.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: repo.GetNamespace(), Name: repo.GetName()}})
+	}
+	return requests
+}
+
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
+	porchClient resource.APIPatchingApplicator
+	gitClient   gitprovider.GitClient
 	finalizer   *resource.APIFinalizer
+	// deletionMinAge is the minimum age of the latest commit of a repo to be
+	// deleted
+	deletionMinAge time.Duration
+	// resyncInterval is the interval a ready repository is reconciled again
+	// so changes made in the git server are reverted
+	resyncInterval time.Duration
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -100,23 +145,46 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// check if client exists otherwise retry
-	if !r.giteaClient.IsInitialized() {
-		err := fmt.Errorf("gitea server unreachable")
+	if !r.gitClient.IsInitialized() {
+		err := fmt.Errorf("git server unreachable")
 		log.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if resource.WasDeleted(cr) {
-		// TODO DELETION POLICY: "orphan" deletion policy
 		// repo being deleted
-		// Delete the repo from the git server
+		// orphan leaves the repo untouched, archive makes it read-only and
+		// delete removes it from the git server unless it has recent commits
 		// when successful remove the finalizer
-		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteRepo(ctx, r.giteaClient, cr); err != nil {
+		policy := getDeletionPolicy(cr)
+		if policy == commonv1alpha1.DeletionDelete {
+			wait, err := r.checkDeletion(ctx, r.gitClient, cr)
+			if err != nil {
+				cr.SetConditions(infrav1alpha1.Failed("cannot check repo commits"))
+				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
+			if wait > 0 {
+				cr.SetConditions(infrav1alpha1.Failed(fmt.Sprintf("deletion refused, repo has commits newer than %s, set %s to delete it", r.deletionMinAge, forceDeleteKey)))
+				return ctrl.Result{RequeueAfter: wait}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
+		}
+		// porch stops using the repo before it is archived or deleted
+		if err := r.deletePorchRepository(ctx, cr); err != nil {
+			cr.SetConditions(infrav1alpha1.Failed("cannot delete porch repository"))
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		switch policy {
+		case commonv1alpha1.DeletionDelete:
+			if err := r.deleteRepo(ctx, r.gitClient, cr); err != nil {
 				log.Error(err, "cannot delete repo in git server")
 				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 			}
+		case DeletionArchive:
+			if err := r.archiveRepo(ctx, r.gitClient, cr); err != nil {
+				log.Error(err, "cannot archive repo in git server")
+				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
 		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
@@ -137,26 +205,51 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// upsert repo in git server
-	if err := r.upsertRepo(ctx, r.giteaClient, cr); err != nil {
+	if err := r.upsertRepo(ctx, r.gitClient, cr); err != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	// commit the initial content of a new repo
+	if err := r.seedRepo(ctx, r.gitClient, cr); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed("cannot seed repo"))
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	// apply the team access, branch protection and webhooks, after the seed
+	// commit as the protection can block pushes to the default branch
+	if err := r.upsertSettings(ctx, r.gitClient, cr); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed("cannot configure repo"))
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	// register the repo in porch when enabled
+	if err := r.upsertPorchRepository(ctx, cr); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed("cannot register porch repository"))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	cr.SetConditions(infrav1alpha1.Ready())
-	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	return ctrl.Result{RequeueAfter: r.resyncInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Repository) error {
+func (r *reconciler) upsertRepo(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) error {
 	log := log.FromContext(ctx)
-	u, _, err := giteaClient.GetMyUserInfo()
+	u, err := gitClient.GetMyUserInfo()
 	if err != nil {
 		log.Error(err, "cannot get user info")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
+	owner := getRepoOwner(cr, u.UserName)
 
-	_, _, err = giteaClient.GetRepo(u.UserName, cr.GetName())
+	_, err = gitClient.GetRepo(owner, cr.GetName())
 	if err != nil {
+		if !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot get repo")
+			cr.SetConditions(infrav1alpha1.Failed("cannot get repo"))
+			return err
+		}
 		// create repo
-		createRepo := gitea.CreateRepoOption{Name: cr.GetName()}
+		createRepo := gitprovider.CreateRepoOptions{
+			Organization: cr.GetAnnotations()[organizationKey],
+			Name:         cr.GetName(),
+		}
 		if cr.Spec.Description != nil {
 			createRepo.Description = *cr.Spec.Description
 		}
@@ -179,12 +272,19 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 			createRepo.DefaultBranch = *cr.Spec.DefaultBranch
 		}
 		if cr.Spec.TrustModel != nil {
-			createRepo.TrustModel = gitea.TrustModel(*cr.Spec.TrustModel)
+			createRepo.TrustModel = string(*cr.Spec.TrustModel)
 		}
 		createRepo.AutoInit = true
 		log.Info("repository", "config", createRepo)
 
-		repo, _, err := giteaClient.CreateRepo(createRepo)
+		if cr.GetAnnotations()[seedPackageKey] != "" {
+			if err := r.setSeedPending(ctx, cr, true); err != nil {
+				cr.SetConditions(infrav1alpha1.Failed("cannot mark repo to be seeded"))
+				return err
+			}
+		}
+
+		repo, err := createSeededRepo(gitClient, owner, cr, createRepo)
 		if err != nil {
 			log.Error(err, "cannot create repo")
 			// Here we don't provide the full error since the message change every time and this will re-trigger
@@ -196,7 +296,7 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 		cr.Status.URL = &repo.CloneURL
 		return nil
 	}
-	editRepo := gitea.EditRepoOption{Name: ptr.To(cr.GetName())}
+	editRepo := gitprovider.EditRepoOptions{Name: ptr.To(cr.GetName())}
 	if cr.Spec.Description != nil {
 		editRepo.Description = cr.Spec.Description
 	} else {
@@ -207,7 +307,7 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 	} else {
 		editRepo.Private = nil
 	}
-	repo, _, err := giteaClient.EditRepo(u.UserName, cr.GetName(), editRepo)
+	repo, err := gitClient.EditRepo(owner, cr.GetName(), editRepo)
 	if err != nil {
 		log.Error(err, "cannot update repo")
 		// Here we don't provide the full error since the message change every time and this will re-trigger
@@ -221,16 +321,16 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 	return nil
 }
 
-func (r *reconciler) deleteRepo(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Repository) error {
+func (r *reconciler) deleteRepo(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) error {
 	log := log.FromContext(ctx)
-	u, _, err := giteaClient.GetMyUserInfo()
+	u, err := gitClient.GetMyUserInfo()
 	if err != nil {
 		log.Error(err, "cannot get user info")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
 
-	_, err = giteaClient.DeleteRepo(u.UserName, cr.GetName())
+	err = gitClient.DeleteRepo(getRepoOwner(cr, u.UserName), cr.GetName())
 	if err != nil {
 		log.Error(err, "cannot delete repo")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
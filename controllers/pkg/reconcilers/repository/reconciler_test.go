@@ -20,26 +20,25 @@ import (
 	"testing"
 
 	"github.com/go-logr/logr"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/nephio-project/nephio/testing/mockeryutils"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	"code.gitea.io/sdk/gitea"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 )
 
 type fields struct {
 	APIPatchingApplicator resource.APIPatchingApplicator
-	giteaClient           giteaclient.GiteaClient
+	gitClient             gitprovider.GitClient
 	finalizer             *resource.APIFinalizer
 	l                     logr.Logger
 }
 type args struct {
-	ctx         context.Context
-	giteaClient giteaclient.GiteaClient
-	cr          *infrav1alpha1.Repository
+	ctx       context.Context
+	gitClient gitprovider.GitClient
+	cr        *infrav1alpha1.Repository
 }
 type repoTest struct {
 	name    string
@@ -60,7 +59,7 @@ func TestUpsertRepo(t *testing.T) {
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
 			args:   args{nil, nil, &infrav1alpha1.Repository{}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, nil, fmt.Errorf("error getting User Information")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, fmt.Errorf("error getting User Information")}},
 			},
 			wantErr: true,
 		},
@@ -69,9 +68,9 @@ func TestUpsertRepo(t *testing.T) {
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
 			args:   args{nil, nil, &infrav1alpha1.Repository{Status: infrav1alpha1.RepositoryStatus{}}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
-				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -90,9 +89,9 @@ func TestUpsertRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
-				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -105,10 +104,10 @@ func TestUpsertRepo(t *testing.T) {
 				&infrav1alpha1.Repository{},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 				{MethodName: "EditRepo", ArgType: []string{"string", "string",
-					"gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("error updating repo")}},
+					"gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, fmt.Errorf("error updating repo")}},
 			},
 			wantErr: true,
 		},
@@ -132,9 +131,9 @@ func TestUpsertRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.ErrNotFound}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -147,9 +146,9 @@ func TestUpsertRepo(t *testing.T) {
 				&infrav1alpha1.Repository{},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.ErrNotFound}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -162,9 +161,23 @@ func TestUpsertRepo(t *testing.T) {
 				&infrav1alpha1.Repository{},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo creation fails")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.ErrNotFound}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, fmt.Errorf("repo creation fails")}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Get repo: fails",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil, fmt.Errorf("git server error")}},
 			},
 			wantErr: true,
 		}}
@@ -172,13 +185,13 @@ func TestUpsertRepo(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.upsertRepo(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.upsertRepo(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("upsertRepo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -200,8 +213,8 @@ func TestDeleteRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Response{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil}},
 			},
 			wantErr: false,
 		}, {
@@ -217,7 +230,7 @@ func TestDeleteRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, fmt.Errorf("Error getting User Information")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, fmt.Errorf("Error getting User Information")}},
 			},
 			wantErr: true,
 		}, {
@@ -233,8 +246,8 @@ func TestDeleteRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Response{}, fmt.Errorf("Error deleting repo")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{fmt.Errorf("Error deleting repo")}},
 			},
 			wantErr: true,
 		}}
@@ -242,13 +255,13 @@ func TestDeleteRepo(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.deleteRepo(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.deleteRepo(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("deleteRepo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -256,8 +269,8 @@ func TestDeleteRepo(t *testing.T) {
 }
 
 func initMockeryMocks(tt *repoTest) {
-	mockGClient := new(giteaclient.MockGiteaClient)
-	tt.args.giteaClient = mockGClient
-	tt.fields.giteaClient = mockGClient
+	mockGClient := new(gitprovider.MockGitClient)
+	tt.args.gitClient = mockGClient
+	tt.fields.gitClient = mockGClient
 	mockeryutils.InitMocks(&mockGClient.Mock, tt.mocks)
 }
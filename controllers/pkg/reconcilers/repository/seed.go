@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	porchv1alpha1 "github.com/nephio-project/porch/api/porch/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// seedTemplateKey is the template repository <owner>/<name> the new
+	// repository is generated from
+	seedTemplateKey = "repository.nephio.org/template"
+	// seedPackageKey is the name of a porch PackageRevision in the namespace
+	// of the repository, its resources are committed to the new repository
+	seedPackageKey = "repository.nephio.org/package"
+	// seedDirectoryKey is the directory in the repository the package is
+	// committed to, the default is the root of the repository
+	seedDirectoryKey = "repository.nephio.org/package-directory"
+	// seedPendingKey marks a repository created by this reconciler whose
+	// package is not committed yet, it is set before the repository is created
+	// so the seeding is not lost when the status update of the pass fails
+	seedPendingKey = "repository.nephio.org/seed-pending"
+
+	// ConditionTypeSeeded reports the initial content of the repository
+	ConditionTypeSeeded   infrav1alpha1.ConditionType   = "Seeded"
+	ConditionReasonSeeded infrav1alpha1.ConditionReason = "Seeded"
+	// ConditionReasonPending is set when the repository is created and the
+	// package still needs to be committed
+	ConditionReasonPending infrav1alpha1.ConditionReason = "Pending"
+)
+
+// getSeedTemplate returns the owner and name of the template repository
+func getSeedTemplate(cr *infrav1alpha1.Repository) (string, string, bool, error) {
+	template, ok := cr.GetAnnotations()[seedTemplateKey]
+	if !ok || template == "" {
+		return "", "", false, nil
+	}
+	owner, name, found := strings.Cut(template, "/")
+	if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", false, fmt.Errorf("invalid annotation %s: %q, expected <owner>/<name>", seedTemplateKey, template)
+	}
+	return owner, name, true, nil
+}
+
+// createSeededRepo creates the repository, from the template repository when
+// set. When a package is set the repository is marked pending so the package
+// is committed by seedRepo.
+func createSeededRepo(gitClient gitprovider.GitClient, owner string, cr *infrav1alpha1.Repository, opts gitprovider.CreateRepoOptions) (*gitprovider.Repository, error) {
+	templateOwner, templateName, ok, err := getSeedTemplate(cr)
+	if err != nil {
+		return nil, err
+	}
+	var repo *gitprovider.Repository
+	if ok {
+		repo, err = gitClient.CreateRepoFromTemplate(templateOwner, templateName, gitprovider.CreateRepoFromTemplateOptions{
+			Owner:       owner,
+			Name:        opts.Name,
+			Description: opts.Description,
+			Private:     opts.Private,
+		})
+	} else {
+		repo, err = gitClient.CreateRepo(opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cr.GetAnnotations()[seedPackageKey] != "":
+		cr.SetConditions(seedCondition(metav1.ConditionFalse, ConditionReasonPending, fmt.Sprintf("package %s not committed", cr.GetAnnotations()[seedPackageKey])))
+	case ok:
+		cr.SetConditions(seedCondition(metav1.ConditionTrue, ConditionReasonSeeded, fmt.Sprintf("generated from template %s/%s", templateOwner, templateName)))
+	}
+	return repo, nil
+}
+
+// setSeedPending adds or removes the seed pending marker of the repository,
+// the status of the pass is kept as the update returns the stored status
+func (r *reconciler) setSeedPending(ctx context.Context, cr *infrav1alpha1.Repository, pending bool) error {
+	annotations := cr.GetAnnotations()
+	if (annotations[seedPendingKey] == "true") == pending {
+		return nil
+	}
+	if pending {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[seedPendingKey] = "true"
+	} else {
+		delete(annotations, seedPendingKey)
+	}
+	cr.SetAnnotations(annotations)
+	status := cr.Status.DeepCopy()
+	if err := r.Update(ctx, cr); err != nil {
+		log.FromContext(ctx).Error(err, "cannot update seed pending marker")
+		return err
+	}
+	cr.Status = *status
+	return nil
+}
+
+// seedRepo commits the resources of the package to a repository created by
+// this reconciler, repositories that existed before are never changed
+func (r *reconciler) seedRepo(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	pkg := cr.GetAnnotations()[seedPackageKey]
+	if pkg == "" || cr.GetAnnotations()[seedPendingKey] != "true" {
+		return nil
+	}
+
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: pkg}, prr); err != nil {
+		log.Error(err, "cannot get package resources", "package", pkg)
+		cr.SetConditions(seedCondition(metav1.ConditionFalse, ConditionReasonPending, fmt.Sprintf("cannot get package %s", pkg)))
+		return err
+	}
+	dir := cr.GetAnnotations()[seedDirectoryKey]
+	files := make(map[string][]byte, len(prr.Spec.Resources))
+	for name, content := range prr.Spec.Resources {
+		files[path.Join(dir, name)] = []byte(content)
+	}
+
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		return err
+	}
+	owner := getRepoOwner(cr, u.UserName)
+	repo, err := gitClient.GetRepo(owner, cr.GetName())
+	if err != nil {
+		log.Error(err, "cannot get repo")
+		return err
+	}
+	if err := gitClient.CommitFiles(owner, cr.GetName(), gitprovider.CommitFilesOptions{
+		Branch:  repo.DefaultBranch,
+		Message: fmt.Sprintf("Initialize repository from package %s", pkg),
+		Files:   files,
+	}); err != nil {
+		log.Error(err, "cannot commit package", "package", pkg)
+		cr.SetConditions(seedCondition(metav1.ConditionFalse, ConditionReasonPending, fmt.Sprintf("cannot commit package %s", pkg)))
+		return err
+	}
+	log.Info("repo seeded", "name", cr.GetName(), "package", pkg, "files", len(files))
+	cr.SetConditions(seedCondition(metav1.ConditionTrue, ConditionReasonSeeded, fmt.Sprintf("initialized from package %s", pkg)))
+	return r.setSeedPending(ctx, cr, false)
+}
+
+func seedCondition(status metav1.ConditionStatus, reason infrav1alpha1.ConditionReason, msg string) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeSeeded),
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(reason),
+		Message:            msg,
+	}}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	porchv1alpha1 "github.com/nephio-project/porch/api/porch/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetSeedTemplate(t *testing.T) {
+	tests := map[string]struct {
+		template  string
+		wantOwner string
+		wantName  string
+		wantOk    bool
+		wantErr   bool
+	}{
+		"not set": {},
+		"template": {
+			template:  "nephio/cluster-template",
+			wantOwner: "nephio",
+			wantName:  "cluster-template",
+			wantOk:    true,
+		},
+		"missing owner": {
+			template: "cluster-template",
+			wantErr:  true,
+		},
+		"nested name": {
+			template: "nephio/templates/cluster",
+			wantErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.template != "" {
+				annotations[seedTemplateKey] = tt.template
+			}
+			owner, repoName, ok, err := getSeedTemplate(newPorchTestRepository(annotations))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getSeedTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repoName != tt.wantName || ok != tt.wantOk {
+				t.Errorf("getSeedTemplate() = %s, %s, %t, want %s, %s, %t", owner, repoName, ok, tt.wantOwner, tt.wantName, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCreateSeededRepo(t *testing.T) {
+	tests := map[string]struct {
+		annotations  map[string]string
+		wantTemplate bool
+		wantStatus   metav1.ConditionStatus
+		wantReason   infrav1alpha1.ConditionReason
+	}{
+		"empty repo": {},
+		"template": {
+			annotations:  map[string]string{seedTemplateKey: "nephio/cluster-template"},
+			wantTemplate: true,
+			wantStatus:   metav1.ConditionTrue,
+			wantReason:   ConditionReasonSeeded,
+		},
+		"package": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1"},
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  ConditionReasonPending,
+		},
+		"template and package": {
+			annotations:  map[string]string{seedTemplateKey: "nephio/cluster-template", seedPackageKey: "blueprints.cluster.v1"},
+			wantTemplate: true,
+			wantStatus:   metav1.ConditionFalse,
+			wantReason:   ConditionReasonPending,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := newPorchTestRepository(tt.annotations)
+			gitClient := new(gitprovider.MockGitClient)
+			gitClient.On("CreateRepo", mock.AnythingOfType("gitprovider.CreateRepoOptions")).Return(&gitprovider.Repository{}, nil)
+			gitClient.On("CreateRepoFromTemplate", "nephio", "cluster-template", gitprovider.CreateRepoFromTemplateOptions{Owner: "gitea", Name: "edge01", Private: true}).Return(&gitprovider.Repository{}, nil)
+
+			if _, err := createSeededRepo(gitClient, "gitea", cr, gitprovider.CreateRepoOptions{Name: "edge01", Private: true}); err != nil {
+				t.Fatalf("createSeededRepo() error = %v", err)
+			}
+			gitClient.AssertNumberOfCalls(t, "CreateRepoFromTemplate", map[bool]int{true: 1, false: 0}[tt.wantTemplate])
+			gitClient.AssertNumberOfCalls(t, "CreateRepo", map[bool]int{true: 0, false: 1}[tt.wantTemplate])
+			c := cr.GetCondition(ConditionTypeSeeded)
+			if c.Reason != string(tt.wantReason) || (tt.wantReason != "" && c.Status != tt.wantStatus) {
+				t.Errorf("createSeededRepo() condition = %+v, want %s/%s", c, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSeedRepo(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		condition   *infrav1alpha1.Condition
+		wantFiles   map[string][]byte
+		wantReason  infrav1alpha1.ConditionReason
+	}{
+		"no package": {},
+		"already seeded": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1"},
+			condition:   ptrTo(seedCondition(metav1.ConditionTrue, ConditionReasonSeeded, "")),
+			wantReason:  ConditionReasonSeeded,
+		},
+		"existing repo": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1"},
+		},
+		"pending": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1", seedPendingKey: "true"},
+			condition:   ptrTo(seedCondition(metav1.ConditionFalse, ConditionReasonPending, "")),
+			wantFiles: map[string][]byte{
+				"Kptfile":   []byte("kind: Kptfile"),
+				"README.md": []byte("# cluster"),
+			},
+			wantReason: ConditionReasonSeeded,
+		},
+		// the status update of the pass that created the repo failed
+		"pending without condition": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1", seedPendingKey: "true"},
+			wantFiles: map[string][]byte{
+				"Kptfile":   []byte("kind: Kptfile"),
+				"README.md": []byte("# cluster"),
+			},
+			wantReason: ConditionReasonSeeded,
+		},
+		"pending in directory": {
+			annotations: map[string]string{seedPackageKey: "blueprints.cluster.v1", seedDirectoryKey: "cluster", seedPendingKey: "true"},
+			condition:   ptrTo(seedCondition(metav1.ConditionFalse, ConditionReasonPending, "")),
+			wantFiles: map[string][]byte{
+				"cluster/Kptfile":   []byte("kind: Kptfile"),
+				"cluster/README.md": []byte("# cluster"),
+			},
+			wantReason: ConditionReasonSeeded,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := newPorchTestRepository(tt.annotations)
+			if tt.condition != nil {
+				cr.SetConditions(*tt.condition)
+			}
+
+			porchMock := new(mocks.MockClient)
+			porchMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "blueprints.cluster.v1"}, mock.AnythingOfType("*v1alpha1.PackageRevisionResources")).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*porchv1alpha1.PackageRevisionResources).Spec.Resources = map[string]string{
+					"Kptfile":   "kind: Kptfile",
+					"README.md": "# cluster",
+				}
+			})
+			var gotOpts gitprovider.CommitFilesOptions
+			gitClient := new(gitprovider.MockGitClient)
+			gitClient.On("GetMyUserInfo").Return(&gitprovider.User{UserName: "gitea"}, nil)
+			gitClient.On("GetRepo", "gitea", "edge01").Return(&gitprovider.Repository{DefaultBranch: "main"}, nil)
+			gitClient.On("CommitFiles", "gitea", "edge01", mock.AnythingOfType("gitprovider.CommitFilesOptions")).Return(nil).Run(func(args mock.Arguments) {
+				gotOpts = args.Get(2).(gitprovider.CommitFilesOptions)
+			})
+
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Update", context.TODO(), mock.AnythingOfType("*v1alpha1.Repository")).Return(nil)
+
+			r := &reconciler{
+				APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock),
+				porchClient:           resource.NewAPIPatchingApplicator(porchMock),
+			}
+			if err := r.seedRepo(context.TODO(), gitClient, cr); err != nil {
+				t.Fatalf("seedRepo() error = %v", err)
+			}
+			clientMock.AssertNumberOfCalls(t, "Update", map[bool]int{true: 1, false: 0}[tt.wantFiles != nil])
+			if _, ok := cr.GetAnnotations()[seedPendingKey]; ok && tt.wantFiles != nil {
+				t.Errorf("seedRepo() annotations = %v, want the seed pending marker removed", cr.GetAnnotations())
+			}
+			gitClient.AssertNumberOfCalls(t, "CommitFiles", map[bool]int{true: 1, false: 0}[tt.wantFiles != nil])
+			if tt.wantFiles != nil && (gotOpts.Branch != "main" || !reflect.DeepEqual(gotOpts.Files, tt.wantFiles)) {
+				t.Errorf("seedRepo() commit = %+v, want files %v on main", gotOpts, tt.wantFiles)
+			}
+			if c := cr.GetCondition(ConditionTypeSeeded); c.Reason != string(tt.wantReason) {
+				t.Errorf("seedRepo() condition = %+v, want reason %q", c, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSetSeedPending(t *testing.T) {
+	cr := newPorchTestRepository(map[string]string{seedPackageKey: "blueprints.cluster.v1"})
+	cr.Status.URL = ptr.To("http://gitea/nephio/edge01.git")
+	var updated map[string]string
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Update", context.TODO(), mock.AnythingOfType("*v1alpha1.Repository")).Return(nil).Run(func(args mock.Arguments) {
+		o := args.Get(1).(*infrav1alpha1.Repository)
+		updated = o.GetAnnotations()
+		// the update returns the stored status
+		o.Status = infrav1alpha1.RepositoryStatus{}
+	})
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock)}
+
+	if err := r.setSeedPending(context.TODO(), cr, true); err != nil {
+		t.Fatalf("setSeedPending() error = %v", err)
+	}
+	if updated[seedPendingKey] != "true" {
+		t.Errorf("setSeedPending() annotations = %v, want the seed pending marker", updated)
+	}
+	if cr.Status.URL == nil {
+		t.Error("setSeedPending() status of the pass not kept")
+	}
+	// the marker is already set
+	if err := r.setSeedPending(context.TODO(), cr, true); err != nil {
+		t.Fatalf("setSeedPending() error = %v", err)
+	}
+	clientMock.AssertNumberOfCalls(t, "Update", 1)
+
+	if err := r.setSeedPending(context.TODO(), cr, false); err != nil {
+		t.Fatalf("setSeedPending() error = %v", err)
+	}
+	if _, ok := updated[seedPendingKey]; ok {
+		t.Errorf("setSeedPending() annotations = %v, want the seed pending marker removed", updated)
+	}
+}
+
+func ptrTo(c infrav1alpha1.Condition) *infrav1alpha1.Condition {
+	return &c
+}
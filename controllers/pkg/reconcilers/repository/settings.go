@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// organizationKey is the organization that owns the repository, the
+	// default is the user of the git client
+	organizationKey = "repository.nephio.org/organization"
+	// teamsKey is a comma separated list of <team>=<permission> of the
+	// organization that get access to the repository
+	teamsKey = "repository.nephio.org/teams"
+	// branchProtectionKey protects the default branch when set to "true"
+	branchProtectionKey = "repository.nephio.org/branch-protection"
+	// requiredApprovalsKey is the number of approving reviews of a protected
+	// branch, the default is 1
+	requiredApprovalsKey = "repository.nephio.org/required-approvals"
+	// allowForcePushKey allows force pushes to a protected branch when set to
+	// "true"
+	allowForcePushKey = "repository.nephio.org/allow-force-push"
+	// webhooksKey is a json list of the webhooks of the repository
+	webhooksKey = "repository.nephio.org/webhooks"
+
+	defaultRequiredApprovals  = 1
+	defaultWebhookContentType = "json"
+	// webhookSecretKey is the key of the webhook secret in the secret
+	// referenced by the webhook
+	webhookSecretKey = "secret"
+	// defaultResyncInterval is the interval the settings are applied again to
+	// revert changes made in the git server, overwritten by
+	// REPOSITORY_RESYNC_INTERVAL
+	defaultResyncInterval = 10 * time.Minute
+)
+
+// getResyncInterval returns the interval a ready repository is reconciled
+// again
+func getResyncInterval(ctx context.Context) time.Duration {
+	v, ok := os.LookupEnv("REPOSITORY_RESYNC_INTERVAL")
+	if !ok {
+		return defaultResyncInterval
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.FromContext(ctx).Error(err, "invalid REPOSITORY_RESYNC_INTERVAL, using default", "value", v, "default", defaultResyncInterval)
+		return defaultResyncInterval
+	}
+	return d
+}
+
+// webhook is the spec of a webhook in the webhooks annotation
+type webhook struct {
+	URL string `json:"url"`
+	// Events default to push
+	Events []string `json:"events,omitempty"`
+	// ContentType defaults to json
+	ContentType string `json:"contentType,omitempty"`
+	// SecretRef is the name of a secret in the namespace of the repository
+	// with the webhook secret in the secret key
+	SecretRef string `json:"secretRef,omitempty"`
+}
+
+// getRepoOwner returns the organization of the repository or the user of the
+// git client
+func getRepoOwner(cr *infrav1alpha1.Repository, userName string) string {
+	if org := cr.GetAnnotations()[organizationKey]; org != "" {
+		return org
+	}
+	return userName
+}
+
+// getTeams returns the permission per team of the teams annotation
+func getTeams(cr *infrav1alpha1.Repository) (map[string]string, error) {
+	teams := map[string]string{}
+	for _, t := range strings.Split(cr.GetAnnotations()[teamsKey], ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		team, permission, _ := strings.Cut(t, "=")
+		if permission == "" {
+			permission = gitprovider.PermissionRead
+		}
+		switch permission {
+		case gitprovider.PermissionRead, gitprovider.PermissionWrite, gitprovider.PermissionAdmin:
+		default:
+			return nil, fmt.Errorf("invalid permission %q of team %s, supported: %s, %s, %s", permission, team, gitprovider.PermissionRead, gitprovider.PermissionWrite, gitprovider.PermissionAdmin)
+		}
+		teams[team] = permission
+	}
+	if len(teams) != 0 && cr.GetAnnotations()[organizationKey] == "" {
+		return nil, fmt.Errorf("annotation %s requires annotation %s", teamsKey, organizationKey)
+	}
+	return teams, nil
+}
+
+// getBranchProtection returns the protection of the default branch, nil when
+// the branch is not protected
+func getBranchProtection(cr *infrav1alpha1.Repository, branch string) (*gitprovider.BranchProtectionOptions, error) {
+	if cr.GetAnnotations()[branchProtectionKey] != "true" {
+		return nil, nil
+	}
+	approvals := defaultRequiredApprovals
+	if v, ok := cr.GetAnnotations()[requiredApprovalsKey]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid annotation %s: %q", requiredApprovalsKey, v)
+		}
+		approvals = n
+	}
+	return &gitprovider.BranchProtectionOptions{
+		Branch:            branch,
+		RequiredApprovals: approvals,
+		AllowForcePush:    cr.GetAnnotations()[allowForcePushKey] == "true",
+	}, nil
+}
+
+// getWebhooks returns the webhooks of the annotation with the defaults set
+func getWebhooks(cr *infrav1alpha1.Repository) ([]webhook, error) {
+	v, ok := cr.GetAnnotations()[webhooksKey]
+	if !ok || v == "" {
+		return nil, nil
+	}
+	hooks := []webhook{}
+	if err := json.Unmarshal([]byte(v), &hooks); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %w", webhooksKey, err)
+	}
+	for i := range hooks {
+		if hooks[i].URL == "" {
+			return nil, fmt.Errorf("invalid annotation %s: webhook without url", webhooksKey)
+		}
+		if len(hooks[i].Events) == 0 {
+			hooks[i].Events = []string{gitprovider.WebhookEventPush}
+		}
+		if hooks[i].ContentType == "" {
+			hooks[i].ContentType = defaultWebhookContentType
+		}
+	}
+	return hooks, nil
+}
+
+// webhookChanged returns true when the webhook in the git server differs from
+// the spec, the secret cannot be read back and is not compared
+func webhookChanged(current *gitprovider.Webhook, hook webhook) bool {
+	currentEvents := slices.Clone(current.Events)
+	events := slices.Clone(hook.Events)
+	slices.Sort(currentEvents)
+	slices.Sort(events)
+	return !current.Active || current.ContentType != hook.ContentType || !slices.Equal(currentEvents, events)
+}
+
+// upsertSettings applies the team access, branch protection and webhooks of
+// the annotations, each pass so changes in the git server are reverted. A
+// ready repository is reconciled every resync interval.
+func (r *reconciler) upsertSettings(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		return err
+	}
+	owner := getRepoOwner(cr, u.UserName)
+
+	teams, err := getTeams(cr)
+	if err != nil {
+		log.Error(err, "cannot get teams")
+		return err
+	}
+	for team, permission := range teams {
+		if err := gitClient.AddTeamRepo(owner, team, cr.GetName(), permission); err != nil {
+			log.Error(err, "cannot add team", "team", team)
+			return err
+		}
+	}
+
+	repo, err := gitClient.GetRepo(owner, cr.GetName())
+	if err != nil {
+		log.Error(err, "cannot get repo")
+		return err
+	}
+	protection, err := getBranchProtection(cr, repo.DefaultBranch)
+	if err != nil {
+		log.Error(err, "cannot get branch protection")
+		return err
+	}
+	if protection != nil {
+		if err := gitClient.ProtectBranch(owner, cr.GetName(), *protection); err != nil {
+			log.Error(err, "cannot protect branch", "branch", protection.Branch)
+			return err
+		}
+	}
+
+	return r.upsertWebhooks(ctx, gitClient, cr, owner)
+}
+
+// upsertWebhooks creates the webhooks that do not exist and updates the
+// changed ones, webhooks that are not in the annotation are left untouched
+func (r *reconciler) upsertWebhooks(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Repository, owner string) error {
+	log := log.FromContext(ctx)
+	hooks, err := getWebhooks(cr)
+	if err != nil {
+		log.Error(err, "cannot get webhooks")
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	current, err := gitClient.ListWebhooks(owner, cr.GetName())
+	if err != nil {
+		log.Error(err, "cannot list webhooks")
+		return err
+	}
+	for _, hook := range hooks {
+		i := slices.IndexFunc(current, func(w *gitprovider.Webhook) bool { return w.URL == hook.URL })
+		if i >= 0 && !webhookChanged(current[i], hook) {
+			continue
+		}
+		opts := gitprovider.WebhookOptions{
+			URL:         hook.URL,
+			ContentType: hook.ContentType,
+			Events:      hook.Events,
+			Active:      true,
+		}
+		if hook.SecretRef != "" {
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: hook.SecretRef}, secret); err != nil {
+				log.Error(err, "cannot get webhook secret", "secret", hook.SecretRef)
+				return err
+			}
+			opts.Secret = string(secret.Data[webhookSecretKey])
+		}
+		if i < 0 {
+			if _, err := gitClient.CreateWebhook(owner, cr.GetName(), opts); err != nil {
+				log.Error(err, "cannot create webhook", "url", hook.URL)
+				return err
+			}
+			log.Info("webhook created", "url", hook.URL)
+			continue
+		}
+		if err := gitClient.EditWebhook(owner, cr.GetName(), current[i].ID, opts); err != nil {
+			log.Error(err, "cannot update webhook", "url", hook.URL)
+			return err
+		}
+		log.Info("webhook updated", "url", hook.URL)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetTeams(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{
+		"no teams": {
+			want: map[string]string{},
+		},
+		"teams": {
+			annotations: map[string]string{organizationKey: "nephio-org", teamsKey: "platform=write, ops,admins=admin"},
+			want:        map[string]string{"platform": "write", "ops": "read", "admins": "admin"},
+		},
+		"invalid permission": {
+			annotations: map[string]string{organizationKey: "nephio-org", teamsKey: "platform=owner"},
+			wantErr:     true,
+		},
+		"no organization": {
+			annotations: map[string]string{teamsKey: "platform=write"},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := getTeams(newPorchTestRepository(tt.annotations))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getTeams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getTeams() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchProtection(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        *gitprovider.BranchProtectionOptions
+		wantErr     bool
+	}{
+		"not protected": {},
+		"defaults": {
+			annotations: map[string]string{branchProtectionKey: "true"},
+			want:        &gitprovider.BranchProtectionOptions{Branch: "main", RequiredApprovals: 1},
+		},
+		"force push": {
+			annotations: map[string]string{branchProtectionKey: "true", requiredApprovalsKey: "0", allowForcePushKey: "true"},
+			want:        &gitprovider.BranchProtectionOptions{Branch: "main", AllowForcePush: true},
+		},
+		"invalid approvals": {
+			annotations: map[string]string{branchProtectionKey: "true", requiredApprovalsKey: "-1"},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := getBranchProtection(newPorchTestRepository(tt.annotations), "main")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBranchProtection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBranchProtection() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWebhooks(t *testing.T) {
+	cr := newPorchTestRepository(map[string]string{
+		webhooksKey: `[{"url": "http://hook", "secretRef": "hook-secret"}, {"url": "http://ci", "events": ["pull_request"], "contentType": "form"}]`,
+	})
+	got, err := getWebhooks(cr)
+	if err != nil {
+		t.Fatalf("getWebhooks() error = %v", err)
+	}
+	want := []webhook{
+		{URL: "http://hook", Events: []string{"push"}, ContentType: "json", SecretRef: "hook-secret"},
+		{URL: "http://ci", Events: []string{"pull_request"}, ContentType: "form"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getWebhooks() got = %+v, want %+v", got, want)
+	}
+
+	for _, v := range []string{`{"url": "http://hook"}`, `[{"events": ["push"]}]`} {
+		if _, err := getWebhooks(newPorchTestRepository(map[string]string{webhooksKey: v})); err == nil {
+			t.Errorf("getWebhooks(%s) expected error", v)
+		}
+	}
+}
+
+func TestUpsertSettings(t *testing.T) {
+	cr := newPorchTestRepository(map[string]string{
+		organizationKey:     "nephio-org",
+		teamsKey:            "platform=write",
+		branchProtectionKey: "true",
+		webhooksKey:         `[{"url": "http://hook", "secretRef": "hook-secret"}, {"url": "http://ci", "events": ["push", "pull_request"]}, {"url": "http://new", "secretRef": "hook-secret"}]`,
+	})
+
+	gitClient := new(gitprovider.MockGitClient)
+	gitClient.On("GetMyUserInfo").Return(&gitprovider.User{UserName: "gitea"}, nil)
+	gitClient.On("AddTeamRepo", "nephio-org", "platform", "edge01", "write").Return(nil)
+	gitClient.On("GetRepo", "nephio-org", "edge01").Return(&gitprovider.Repository{DefaultBranch: "develop"}, nil)
+	gitClient.On("ProtectBranch", "nephio-org", "edge01", gitprovider.BranchProtectionOptions{Branch: "develop", RequiredApprovals: 1}).Return(nil)
+	gitClient.On("ListWebhooks", "nephio-org", "edge01").Return([]*gitprovider.Webhook{
+		// unchanged
+		{ID: 1, URL: "http://hook", ContentType: "json", Events: []string{"push"}, Active: true},
+		// events changed
+		{ID: 2, URL: "http://ci", ContentType: "json", Events: []string{"push"}, Active: true},
+		// not managed
+		{ID: 3, URL: "http://other", ContentType: "json", Events: []string{"push"}, Active: true},
+	}, nil)
+	gitClient.On("EditWebhook", "nephio-org", "edge01", int64(2), gitprovider.WebhookOptions{URL: "http://ci", ContentType: "json", Events: []string{"push", "pull_request"}, Active: true}).Return(nil)
+	gitClient.On("CreateWebhook", "nephio-org", "edge01", gitprovider.WebhookOptions{URL: "http://new", ContentType: "json", Secret: "s3cr3t", Events: []string{"push"}, Active: true}).Return(&gitprovider.Webhook{ID: 4}, nil)
+
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "hook-secret"}, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(*corev1.Secret).Data = map[string][]byte{webhookSecretKey: []byte("s3cr3t")}
+	})
+
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock)}
+	if err := r.upsertSettings(context.TODO(), gitClient, cr); err != nil {
+		t.Fatalf("upsertSettings() error = %v", err)
+	}
+	gitClient.AssertExpectations(t)
+	gitClient.AssertNumberOfCalls(t, "EditWebhook", 1)
+	gitClient.AssertNumberOfCalls(t, "CreateWebhook", 1)
+	// the secret is only read for the webhooks that are created or updated
+	clientMock.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func TestGetResyncInterval(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		set   bool
+		want  time.Duration
+	}{
+		"default":  {want: defaultResyncInterval},
+		"set":      {value: "1h", set: true, want: time.Hour},
+		"invalid":  {value: "hourly", set: true, want: defaultResyncInterval},
+		"disabled": {value: "0", set: true, want: defaultResyncInterval},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv("REPOSITORY_RESYNC_INTERVAL", tt.value)
+			}
+			if got := getResyncInterval(context.TODO()); got != tt.want {
+				t.Errorf("getResyncInterval() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
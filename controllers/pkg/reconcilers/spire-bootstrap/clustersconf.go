@@ -0,0 +1,215 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ClustersConfig is the clusters config of the k8s_psat node attestor of the
+// spire server, the content of clusters.conf
+//
+//	clusters = {
+//	  "edge01" = {
+//	    kube_config_file           = "/run/spire/kubeconfigs/kubeconfig-edge01"
+//	    service_account_allow_list = ["spire:spire-agent"]
+//	  }
+//	}
+type ClustersConfig struct {
+	Clusters map[string]ClusterConfig
+}
+
+// ClusterConfig is the config of a cluster of the k8s_psat node attestor
+type ClusterConfig struct {
+	ServiceAccountAllowList []string
+	Audience                []string
+	KubeConfigFile          string
+	AllowedNodeLabelKeys    []string
+	AllowedPodLabelKeys     []string
+}
+
+// attributes of ClusterConfig in clusters.conf
+const (
+	serviceAccountAllowListAttr = "service_account_allow_list"
+	audienceAttr                = "audience"
+	kubeConfigFileAttr          = "kube_config_file"
+	allowedNodeLabelKeysAttr    = "allowed_node_label_keys"
+	allowedPodLabelKeysAttr     = "allowed_pod_label_keys"
+)
+
+// parseClustersConfig parses the content of clusters.conf, empty content is
+// an empty config. Content written by previous versions of the controller
+// starts with a pipe operator which is ignored. Attributes that are not part
+// of the model are an error, they would be lost when the config is rendered.
+func parseClustersConfig(content string) (*ClustersConfig, error) {
+	cfg := &ClustersConfig{Clusters: map[string]ClusterConfig{}}
+	content = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "|"))
+	if content == "" {
+		return cfg, nil
+	}
+	f, diags := hclsyntax.ParseConfig([]byte(content), "clusters.conf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("cannot parse clusters.conf: %s", diags.Error())
+	}
+	attrs, diags := f.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("cannot parse clusters.conf: %s", diags.Error())
+	}
+	for name := range attrs {
+		if name != "clusters" {
+			return nil, fmt.Errorf("unsupported attribute %s in clusters.conf", name)
+		}
+	}
+	attr, ok := attrs["clusters"]
+	if !ok {
+		return cfg, nil
+	}
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("cannot parse clusters: %s", diags.Error())
+	}
+	if !v.Type().IsObjectType() && !v.Type().IsMapType() {
+		return nil, fmt.Errorf("clusters is not an object")
+	}
+	for it := v.ElementIterator(); it.Next(); {
+		k, cv := it.Element()
+		clusterName := k.AsString()
+		cc, err := parseClusterConfig(cv)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", clusterName, err)
+		}
+		cfg.Clusters[clusterName] = cc
+	}
+	return cfg, nil
+}
+
+func parseClusterConfig(v cty.Value) (ClusterConfig, error) {
+	cc := ClusterConfig{}
+	if !v.Type().IsObjectType() && !v.Type().IsMapType() {
+		return cc, fmt.Errorf("not an object")
+	}
+	for it := v.ElementIterator(); it.Next(); {
+		k, av := it.Element()
+		var err error
+		switch name := k.AsString(); name {
+		case serviceAccountAllowListAttr:
+			cc.ServiceAccountAllowList, err = parseStringList(name, av)
+		case audienceAttr:
+			cc.Audience, err = parseStringList(name, av)
+		case allowedNodeLabelKeysAttr:
+			cc.AllowedNodeLabelKeys, err = parseStringList(name, av)
+		case allowedPodLabelKeysAttr:
+			cc.AllowedPodLabelKeys, err = parseStringList(name, av)
+		case kubeConfigFileAttr:
+			if av.Type() != cty.String || av.IsNull() {
+				return cc, fmt.Errorf("%s is not a string", name)
+			}
+			cc.KubeConfigFile = av.AsString()
+		default:
+			return cc, fmt.Errorf("unsupported attribute %s", name)
+		}
+		if err != nil {
+			return cc, err
+		}
+	}
+	return cc, nil
+}
+
+func parseStringList(name string, v cty.Value) ([]string, error) {
+	if v.IsNull() || !(v.Type().IsTupleType() || v.Type().IsListType()) {
+		return nil, fmt.Errorf("%s is not a list", name)
+	}
+	l := []string{}
+	for it := v.ElementIterator(); it.Next(); {
+		_, e := it.Element()
+		if e.Type() != cty.String || e.IsNull() {
+			return nil, fmt.Errorf("%s is not a list of strings", name)
+		}
+		l = append(l, e.AsString())
+	}
+	return l, nil
+}
+
+// SetCluster adds or replaces the config of the cluster
+func (r *ClustersConfig) SetCluster(clusterName string, cc ClusterConfig) {
+	if r.Clusters == nil {
+		r.Clusters = map[string]ClusterConfig{}
+	}
+	r.Clusters[clusterName] = cc
+}
+
+// RemoveCluster removes the config of the cluster
+func (r *ClustersConfig) RemoveCluster(clusterName string) {
+	delete(r.Clusters, clusterName)
+}
+
+// Render returns the config in HCL, the clusters are sorted by name and the
+// attributes are in a fixed order so the same config renders the same content
+func (r *ClustersConfig) Render() string {
+	names := make([]string, 0, len(r.Clusters))
+	for name := range r.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clusters := []hclwrite.ObjectAttrTokens{}
+	for _, name := range names {
+		clusters = append(clusters, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(name)),
+			Value: r.Clusters[name].tokens(),
+		})
+	}
+	f := hclwrite.NewEmptyFile()
+	f.Body().SetAttributeRaw("clusters", hclwrite.TokensForObject(clusters))
+	return string(hclwrite.Format(f.Bytes()))
+}
+
+// tokens returns the attributes of the cluster that are set
+func (r ClusterConfig) tokens() hclwrite.Tokens {
+	attrs := []hclwrite.ObjectAttrTokens{}
+	addList := func(name string, l []string) {
+		if l == nil {
+			return
+		}
+		values := make([]cty.Value, 0, len(l))
+		for _, e := range l {
+			values = append(values, cty.StringVal(e))
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: hclwrite.TokensForValue(cty.TupleVal(values)),
+		})
+	}
+	addList(serviceAccountAllowListAttr, r.ServiceAccountAllowList)
+	addList(audienceAttr, r.Audience)
+	if r.KubeConfigFile != "" {
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(kubeConfigFileAttr),
+			Value: hclwrite.TokensForValue(cty.StringVal(r.KubeConfigFile)),
+		})
+	}
+	addList(allowedNodeLabelKeysAttr, r.AllowedNodeLabelKeys)
+	addList(allowedPodLabelKeysAttr, r.AllowedPodLabelKeys)
+	return hclwrite.TokensForObject(attrs)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestParseClustersConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expected    *ClustersConfig
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			expected: &ClustersConfig{Clusters: map[string]ClusterConfig{}},
+		},
+		{
+			name:    "legacy",
+			content: testClustersConf,
+			expected: &ClustersConfig{Clusters: map[string]ClusterConfig{
+				"edge01": {ServiceAccountAllowList: []string{"spire:spire-agent"}, KubeConfigFile: "/run/spire/kubeconfigs/kubeconfig-edge01"},
+				"edge02": {ServiceAccountAllowList: []string{"spire:spire-agent"}, KubeConfigFile: "/run/spire/kubeconfigs/kubeconfig-edge02"},
+			}},
+		},
+		{
+			name: "all-attributes",
+			content: `clusters = {
+  "edge01" = {
+    service_account_allow_list = ["spire:spire-agent", "spire:other"]
+    audience = ["spire-server"]
+    kube_config_file = "/run/spire/kubeconfigs/kubeconfig-edge01"
+    allowed_node_label_keys = ["topology.kubernetes.io/zone"]
+    allowed_pod_label_keys = []
+  }
+}`,
+			expected: &ClustersConfig{Clusters: map[string]ClusterConfig{
+				"edge01": {
+					ServiceAccountAllowList: []string{"spire:spire-agent", "spire:other"},
+					Audience:                []string{"spire-server"},
+					KubeConfigFile:          "/run/spire/kubeconfigs/kubeconfig-edge01",
+					AllowedNodeLabelKeys:    []string{"topology.kubernetes.io/zone"},
+					AllowedPodLabelKeys:     []string{},
+				},
+			}},
+		},
+		{
+			name:        "unclosed-block",
+			content:     "clusters = {\n  \"edge01\" = {\n",
+			expectError: true,
+		},
+		{
+			name:        "unsupported-attribute",
+			content:     "clusters = {\n  \"edge01\" = {\n    use_token_review_api_validation = true\n  }\n}",
+			expectError: true,
+		},
+		{
+			name:        "unsupported-top-level-attribute",
+			content:     "clusters = {}\nother = 1",
+			expectError: true,
+		},
+		{
+			name:        "invalid-type",
+			content:     "clusters = {\n  \"edge01\" = {\n    service_account_allow_list = \"spire:spire-agent\"\n  }\n}",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseClustersConfig(tc.content)
+			if tc.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, cfg); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderClustersConfig(t *testing.T) {
+	cfg, err := parseClustersConfig(testClustersConf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// update a cluster, keeping the attributes not managed by the controller
+	cc := cfg.Clusters["edge02"]
+	cc.ServiceAccountAllowList = []string{"spire:spire-agent", "spire:other"}
+	cc.Audience = []string{"spire-server"}
+	cfg.SetCluster("edge02", cc)
+	cfg.SetCluster("core", ClusterConfig{ServiceAccountAllowList: []string{"spire:spire-agent"}, KubeConfigFile: "/run/spire/kubeconfigs/kubeconfig-core"})
+	cfg.RemoveCluster("edge01")
+
+	expected := `clusters = {
+  "core" = {
+    service_account_allow_list = ["spire:spire-agent"]
+    kube_config_file           = "/run/spire/kubeconfigs/kubeconfig-core"
+  }
+  "edge02" = {
+    service_account_allow_list = ["spire:spire-agent", "spire:other"]
+    audience                   = ["spire-server"]
+    kube_config_file           = "/run/spire/kubeconfigs/kubeconfig-edge02"
+  }
+}
+`
+	rendered := cfg.Render()
+	if diff := cmp.Diff(expected, rendered); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+
+	// the rendered content parses to the same config and renders the same
+	parsed, err := parseClustersConfig(rendered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(cfg, parsed); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if parsed.Render() != rendered {
+		t.Errorf("expected the same content when rendered again, got\n%s", parsed.Render())
+	}
+}
+
+func TestGetServiceAccountAllowList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		annotation string
+		expected   []string
+	}{
+		{name: "default", expected: []string{"spire:spire-agent"}},
+		{name: "annotation", annotation: "spire:spire-agent, spire:other", expected: []string{"spire:spire-agent", "spire:other"}},
+		{name: "empty", annotation: " , ", expected: []string{"spire:spire-agent"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := &capiv1beta1.Cluster{}
+			if tc.annotation != "" {
+				cl.SetAnnotations(map[string]string{serviceAccountAllowListKey: tc.annotation})
+			}
+			if diff := cmp.Diff(tc.expected, getServiceAccountAllowList(cl)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
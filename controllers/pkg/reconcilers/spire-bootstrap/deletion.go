@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// finalizer removes the cluster from the spire server before the cluster is
+// deleted
+const finalizer = "nephio.org/spire-bootstrap"
+
+// remoteConfigMaps are the configMaps the controller installs in the spire
+// namespace of the clusters
+var remoteConfigMaps = []string{"spire-bundle", "spire-agent"}
+
+// removeCluster removes the kubeconfig and the clusters.conf block of the
+// cluster from the spire server configuration
+func (r *reconciler) removeCluster(ctx context.Context, clusterName string) error {
+	if err := r.removeKubeconfig(ctx, clusterName); err != nil {
+		return err
+	}
+	return r.removeClusterFromClusterList(ctx, clusterName)
+}
+
+// removeKubeconfig removes the kubeconfig-<cluster> entry and the token
+// status of the cluster from the kubeconfigs secret, and the kubeconfig of
+// previous versions of the controller from the kubeconfigs configMap
+func (r *reconciler) removeKubeconfig(ctx context.Context, clusterName string) error {
+	secret := &v1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kubeconfigsName, Namespace: "spire"}, secret); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, "cannot get kubeconfigs secret")
+		}
+		return r.removeLegacyKubeconfig(ctx, clusterName)
+	}
+	key := fmt.Sprintf("kubeconfig-%s", clusterName)
+	status := getTokenStatus(secret)
+	_, hasKubeconfig := secret.Data[key]
+	_, hasStatus := status[clusterName]
+	if hasKubeconfig || hasStatus {
+		delete(secret.Data, key)
+		delete(status, clusterName)
+		if err := setTokenStatus(secret, status); err != nil {
+			return err
+		}
+		if err := r.Update(ctx, secret); err != nil {
+			return errors.Wrap(err, "cannot update kubeconfigs secret")
+		}
+		log.FromContext(ctx).Info("Kubeconfig removed from the Secret", "clusterName", clusterName)
+	}
+	return r.removeLegacyKubeconfig(ctx, clusterName)
+}
+
+// removeLegacyKubeconfig removes the kubeconfig-<cluster> entry of the
+// kubeconfigs configMap, previous versions of the controller stored the
+// kubeconfigs with long-lived tokens in the configMap
+func (r *reconciler) removeLegacyKubeconfig(ctx context.Context, clusterName string) error {
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kubeconfigsName, Namespace: "spire"}, cm); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), "cannot get kubeconfigs configMap")
+	}
+	key := fmt.Sprintf("kubeconfig-%s", clusterName)
+	if _, ok := cm.Data[key]; !ok {
+		return nil
+	}
+	delete(cm.Data, key)
+	if err := r.Update(ctx, cm); err != nil {
+		return errors.Wrap(err, "cannot update kubeconfigs configMap")
+	}
+	log.FromContext(ctx).Info("Kubeconfig removed from the ConfigMap", "clusterName", clusterName)
+	return nil
+}
+
+// removeClusterFromClusterList removes the cluster from the clusters.conf of
+// the clusters configMap. A clusters.conf that cannot be parsed is logged and
+// left as it is, so it does not block the deletion of the cluster.
+func (r *reconciler) removeClusterFromClusterList(ctx context.Context, clusterName string) error {
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "clusters", Namespace: "spire"}, cm); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), "cannot get Cluster List configMap")
+	}
+	cfg, err := parseClustersConfig(cm.Data["clusters.conf"])
+	if err != nil {
+		log.FromContext(ctx).Error(err, "invalid clusters.conf, the cluster needs to be removed by hand", "clusterName", clusterName)
+		return nil
+	}
+	if _, ok := cfg.Clusters[clusterName]; !ok {
+		return nil
+	}
+	cfg.RemoveCluster(clusterName)
+	if _, err := r.updateClustersConf(ctx, cm, cfg); err != nil {
+		return errors.Wrap(err, "error updating Cluster List ConfigMap")
+	}
+	log.FromContext(ctx).Info("Cluster removed from the Cluster List", "clusterName", clusterName)
+	return nil
+}
+
+// deleteRemoteConfigMaps deletes the configMaps installed by the controller
+// from the cluster, it returns false when the cluster is not reachable. A
+// cluster without credentials is considered gone together with its configMaps.
+func (r *reconciler) deleteRemoteConfigMaps(ctx context.Context, clusterName string) (bool, error) {
+	log := log.FromContext(ctx).WithValues("cluster", clusterName)
+	secret, err := cluster.Registry{Client: r.Client}.GetClusterSecret(ctx, clusterName)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get cluster credentials")
+	}
+	if secret == nil {
+		log.Info("cluster credentials not found, skip deleting configMaps")
+		return true, nil
+	}
+	clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(secret)
+	if !ok {
+		return true, nil
+	}
+	remoteClient, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get clusterClient")
+	}
+	if !ready {
+		return false, nil
+	}
+	for _, name := range remoteConfigMaps {
+		cm := &v1.ConfigMap{}
+		if err := remoteClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "spire"}, cm); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return false, errors.Wrapf(err, "cannot get %s configMap", name)
+			}
+			continue
+		}
+		// only the copies applied by the controller carry the content hash
+		if _, ok := cm.GetAnnotations()[replication.ContentHashKey]; !ok {
+			continue
+		}
+		if err := remoteClient.Delete(ctx, cm); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrapf(err, "cannot delete %s configMap", name)
+		}
+		r.replicator.Forget(clusterName, "ConfigMap", "spire", name)
+		log.Info("configMap deleted", "name", name)
+	}
+	return true, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// testClustersConf is the clusters.conf written by previous versions of the
+// controller
+const testClustersConf = `|
+    clusters = {
+          "edge01" = {
+            service_account_allow_list = ["spire:spire-agent"]
+            kube_config_file = "/run/spire/kubeconfigs/kubeconfig-edge01"
+          }
+          "edge02" = {
+            service_account_allow_list = ["spire:spire-agent"]
+            kube_config_file = "/run/spire/kubeconfigs/kubeconfig-edge02"
+          }
+    }`
+
+func TestReconcileDeletedCluster(t *testing.T) {
+	cases := map[string]struct {
+		clustersConf    string
+		wantClustersCfg bool
+	}{
+		"LegacyClustersConf": {
+			clustersConf:    testClustersConf,
+			wantClustersCfg: true,
+		},
+		// a clusters.conf that cannot be parsed does not block the deletion
+		"InvalidClustersConf": {
+			clustersConf: `clusters = { "edge01" = { unknown = "value" } }`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			testReconcileDeletedCluster(t, tc.clustersConf, tc.wantClustersCfg)
+		})
+	}
+}
+
+func testReconcileDeletedCluster(t *testing.T, clustersConf string, wantClustersCfg bool) {
+	now := metav1.Now()
+	cl := &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "default",
+		Name:              "edge01",
+		DeletionTimestamp: &now,
+		Finalizers:        []string{finalizer},
+	}}
+	kubeconfigs := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: "kubeconfigs"},
+		Data:       map[string]string{"kubeconfig-edge01": "edge01", "kubeconfig-edge02": "edge02"},
+	}
+	kubeconfigSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: "kubeconfigs"},
+		Data:       map[string][]byte{"kubeconfig-edge01": []byte("edge01"), "kubeconfig-edge02": []byte("edge02")},
+	}
+	if err := setTokenStatus(kubeconfigSecret, map[string]tokenStatus{"edge01": {}, "edge02": {}}); err != nil {
+		t.Fatal(err)
+	}
+	clusters := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: "clusters"},
+		Data:       map[string]string{"clusters.conf": clustersConf, "other": "kept"},
+	}
+
+	updated := map[string]client.Object{}
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "default", Name: "edge01"}, mock.AnythingOfType("*v1beta1.Cluster")).Return(nil).Run(func(args mock.Arguments) {
+		cl.DeepCopyInto(args.Get(2).(*capiv1beta1.Cluster))
+	})
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "kubeconfigs"}, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+		kubeconfigs.DeepCopyInto(args.Get(2).(*v1.ConfigMap))
+	})
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "kubeconfigs"}, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		kubeconfigSecret.DeepCopyInto(args.Get(2).(*v1.Secret))
+	})
+	clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "clusters"}, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+		clusters.DeepCopyInto(args.Get(2).(*v1.ConfigMap))
+	})
+	// the credentials of the cluster are gone, so are the configMaps of the agent
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*unstructured.UnstructuredList")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*unstructured.UnstructuredList).Items = nil
+	})
+	clientMock.On("List", context.TODO(), mock.AnythingOfType("*v1.SecretList"), client.MatchingFields{cluster.ClusterNameIndex: "edge01"}).Return(nil)
+	clientMock.On("Update", context.TODO(), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		o := args.Get(1).(client.Object)
+		if _, ok := o.(*v1.ConfigMap); ok && o.GetName() == "kubeconfigs" {
+			updated["kubeconfigs-legacy"] = o
+			return
+		}
+		updated[o.GetName()] = o
+	})
+
+	r := &reconciler{Client: clientMock, finalizer: resource.NewAPIFinalizer(clientMock, finalizer), replicator: replication.NewReplicator("test")}
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "edge01"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (ctrl.Result{}) {
+		t.Errorf("expected no requeue, got %v", result)
+	}
+	if secret, ok := updated["kubeconfigs"].(*v1.Secret); !ok {
+		t.Error("kubeconfigs secret not updated")
+	} else {
+		if _, exists := secret.Data["kubeconfig-edge01"]; exists || len(secret.Data) != 1 {
+			t.Errorf("expected only kubeconfig-edge02, got %v", secret.Data)
+		}
+		if status := getTokenStatus(secret); len(status) != 1 {
+			t.Errorf("expected only the token status of edge02, got %v", status)
+		}
+	}
+	if cm, ok := updated["kubeconfigs-legacy"].(*v1.ConfigMap); !ok {
+		t.Error("kubeconfigs configMap not updated")
+	} else if _, exists := cm.Data["kubeconfig-edge01"]; exists || len(cm.Data) != 1 {
+		t.Errorf("expected only kubeconfig-edge02, got %v", cm.Data)
+	}
+	if cm, ok := updated["clusters"].(*v1.ConfigMap); !wantClustersCfg {
+		if ok {
+			t.Errorf("expected clusters configMap not to be updated, got %v", cm.Data)
+		}
+	} else if !ok {
+		t.Error("clusters configMap not updated")
+	} else {
+		cfg, err := parseClustersConfig(cm.Data["clusters.conf"])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := cfg.Clusters["edge01"]; ok || len(cfg.Clusters) != 1 {
+			t.Errorf("expected only edge02 in clusters.conf, got\n%s", cm.Data["clusters.conf"])
+		}
+		if cm.Data["other"] != "kept" {
+			t.Errorf("expected other data to be kept, got %v", cm.Data)
+		}
+	}
+	if o, ok := updated["edge01"]; !ok {
+		t.Error("cluster not updated")
+	} else if len(o.GetFinalizers()) != 0 {
+		t.Errorf("expected no finalizers, got %v", o.GetFinalizers())
+	}
+}
@@ -24,11 +24,12 @@ import (
 
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/replication"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -40,18 +41,31 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-const spirekubeconfig = "spire-kubeconfig"
+const (
+	spirekubeconfig = "spire-kubeconfig"
+	// serviceAccountAllowListKey is the annotation of the cluster with the
+	// comma separated service accounts allowed to attest the agents of the
+	// cluster, e.g. spire:spire-agent
+	serviceAccountAllowListKey = "nephio.org/spire-service-account-allow-list"
+	defaultServiceAccount      = "spire:spire-agent"
+)
 
 func init() {
 	reconcilerinterface.Register("workloadidentity", &reconciler{})
 }
 
-//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	if err := cluster.SetupIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
 	r.Client = mgr.GetClient()
+	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.replicator = replication.NewReplicator("workloadidentity")
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapSpireController").
@@ -61,6 +75,8 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 
 type reconciler struct {
 	client.Client
+	finalizer  *resource.APIFinalizer
+	replicator *replication.Replicator
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -71,8 +87,49 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			log.Error(err, "unable to fetch Cluster")
+			return reconcile.Result{}, err
+		}
+		// a cluster deleted without the finalizer, e.g. before the finalizer
+		// was introduced, is removed from the spire server configuration
+		if err := r.removeCluster(ctx, req.Name); err != nil {
+			msg := fmt.Sprintf("cannot remove cluster %s from spire", req.Name)
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
 		}
-		return reconcile.Result{}, client.IgnoreNotFound(err)
+		return reconcile.Result{}, nil
+	}
+
+	if resource.WasDeleted(cl) {
+		log.Info("Removing Cluster", "cluster", cl.Name)
+		// the configMaps of the agent are only deleted when the cluster is
+		// reachable, a cluster that is torn down does not block its deletion
+		deleted, err := r.deleteRemoteConfigMaps(ctx, cl.Name)
+		if err != nil {
+			msg := fmt.Sprintf("cannot delete spire configMaps from cluster %s", cl.Name)
+			log.Error(err, msg)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+		}
+		if !deleted {
+			log.Info("cluster not reachable, skip deleting spire configMaps")
+		}
+		if err := r.removeCluster(ctx, cl.Name); err != nil {
+			msg := fmt.Sprintf("cannot remove cluster %s from spire", cl.Name)
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		if err := r.finalizer.RemoveFinalizer(ctx, cl); err != nil {
+			msg := "cannot remove finalizer"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// add finalizer to remove the cluster from spire before it is deleted
+	if err := r.finalizer.AddFinalizer(ctx, cl); err != nil {
+		msg := "cannot add finalizer"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
 	}
 
 	// Add your reconciliation logic here
@@ -87,13 +144,6 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return reconcile.Result{}, err
 	}
 
-	secrets := &v1.SecretList{}
-	if err := r.List(ctx, secrets); err != nil {
-		msg := "cannot list secrets"
-		log.Error(err, msg)
-		return ctrl.Result{}, errors.Wrap(err, msg)
-	}
-
 	// Get the spire-server service
 	spireService := &v1.Service{}
 	err = r.Get(ctx, types.NamespacedName{Name: "spire-server", Namespace: "spire"}, spireService)
@@ -125,96 +175,115 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, errors.Wrap(err, msg)
 	}
 
-	for _, secret := range secrets.Items {
-		if strings.Contains(secret.GetName(), cl.Name) {
-			secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-			clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
-			if ok {
-				client, ready, err := clusterClient.GetClusterClient(ctx)
-				if err != nil {
-					msg := "cannot get clusterClient"
-					log.Error(err, msg)
-					return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-				}
-				if !ready {
-					log.Info("cluster not ready")
-					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-				}
-				kubeconfig := secret.Data["value"]
-				config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
-				if err != nil {
-					msg := "failed to get kubeconfig"
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-
-				}
-				clientset, err := kubernetes.NewForConfig(config)
-				if err != nil {
-					msg := "failed to create rest config"
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-
-				kubeconfigCM, err := r.createKubeconfigConfigMap(ctx, clientset, cl.Name)
-				if err != nil {
-					msg := "Error creating Kubeconfig configmap"
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-
-				err = r.Update(ctx, kubeconfigCM)
-				if err != nil {
-					msg := "failed to Update Kubeconfig list configmap"
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-
-				err = r.updateClusterListConfigMap(ctx, cl.Name)
-				if err != nil {
-					msg := "Cluster List could not be updated"
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-
-				remoteNamespace := configMap.Namespace
-				ns := &v1.Namespace{}
-				if err = client.Get(ctx, types.NamespacedName{Name: remoteNamespace}, ns); err != nil {
-					if resource.IgnoreNotFound(err) != nil {
-						msg := fmt.Sprintf("cannot get namespace: %s", remoteNamespace)
-						log.Error(err, msg)
-						return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-					}
-					msg := fmt.Sprintf("namespace: %s, does not exist, retry...", remoteNamespace)
-					log.Error(err, msg)
-					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-				}
-
-				newcr := configMap.DeepCopy()
-
-				newcr.ResourceVersion = ""
-				newcr.UID = ""
-				newcr.Namespace = remoteNamespace
-
-				newAgentConf := spireAgentCM.DeepCopy()
-				newAgentConf.ResourceVersion = ""
-				newAgentConf.UID = ""
-				newAgentConf.Namespace = remoteNamespace
-				log.Info("secret info", "secret", newcr.Annotations)
-				log.Info("configMap info", "configMap", newAgentConf.Annotations)
-				if err := client.Apply(ctx, newcr); err != nil {
-					msg := fmt.Sprintf("cannot apply spire-bundle configMap to cluster %s", cl.Name)
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-				if err := client.Apply(ctx, newAgentConf); err != nil {
-					msg := fmt.Sprintf("cannot apply spire-agent configMap to cluster %s", cl.Name)
-					log.Error(err, msg)
-					return ctrl.Result{}, errors.Wrap(err, msg)
-				}
-			}
+	secret, err := cluster.Registry{Client: r.Client}.GetClusterSecret(ctx, cl.Name)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get cluster credentials for: %s", cl.Name)
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	if secret == nil {
+		log.Info("cluster credentials not found", "cluster", cl.Name)
+		return reconcile.Result{}, nil
+	}
+	clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(secret)
+	if ok {
+		client, ready, err := clusterClient.GetClusterClient(ctx)
+		if err != nil {
+			msg := "cannot get clusterClient"
+			log.Error(err, msg)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+		}
+		if !ready {
+			log.Info("cluster not ready")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		config, err := getRESTConfig(clusterClient)
+		if err != nil {
+			msg := "failed to get rest config"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			msg := "failed to create rest config"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+
+		refresh, err := r.updateKubeconfig(ctx, clientset, config.Host, cl)
+		if err != nil {
+			msg := "failed to update Kubeconfig secret"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+
+		err = r.updateClusterListConfigMap(ctx, cl.Name, getServiceAccountAllowList(cl))
+		if err != nil {
+			msg := "Cluster List could not be updated"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+
+		remoteNamespace := configMap.Namespace
+		exists, err := replication.NamespaceExists(ctx, client, remoteNamespace)
+		if err != nil {
+			msg := fmt.Sprintf("cannot get namespace: %s", remoteNamespace)
+			log.Error(err, msg)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+		}
+		if !exists {
+			msg := fmt.Sprintf("namespace: %s, does not exist, retry...", remoteNamespace)
+			log.Info(msg)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		}
 
+		newcr := configMap.DeepCopy()
+		replication.ResetMetadata(newcr, remoteNamespace)
+
+		newAgentConf := spireAgentCM.DeepCopy()
+		replication.ResetMetadata(newAgentConf, remoteNamespace)
+		log.Info("secret info", "secret", newcr.Annotations)
+		log.Info("configMap info", "configMap", newAgentConf.Annotations)
+		if _, err := r.replicator.Apply(ctx, client, cl.Name, newcr); err != nil {
+			msg := fmt.Sprintf("cannot apply spire-bundle configMap to cluster %s", cl.Name)
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		if _, err := r.replicator.Apply(ctx, client, cl.Name, newAgentConf); err != nil {
+			msg := fmt.Sprintf("cannot apply spire-agent configMap to cluster %s", cl.Name)
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		// check the configMaps on the cluster periodically, a configMap that
+		// was deleted or changed is applied again, and refresh the token of
+		// the kubeconfig before it expires
+		return ctrl.Result{RequeueAfter: max(min(replication.CheckInterval(ctx), time.Until(refresh)), time.Second)}, nil
 	}
 
 	return reconcile.Result{}, nil
 }
+
+// getServiceAccountAllowList returns the service accounts in the annotation of
+// the cluster, spire:spire-agent when the annotation is not set
+func getServiceAccountAllowList(cl *capiv1beta1.Cluster) []string {
+	allowList := []string{}
+	for _, sa := range strings.Split(cl.GetAnnotations()[serviceAccountAllowListKey], ",") {
+		if sa = strings.TrimSpace(sa); sa != "" {
+			allowList = append(allowList, sa)
+		}
+	}
+	if len(allowList) == 0 {
+		return []string{defaultServiceAccount}
+	}
+	return allowList
+}
+
+// getRESTConfig returns the rest config of the cluster credentials, whatever
+// kind of secret they are stored in (capi, kubeconfig key or service account)
+func getRESTConfig(clusterClient cluster.ClusterClient) (*rest.Config, error) {
+	pooled, ok := clusterClient.(cluster.PooledClusterClient)
+	if !ok {
+		return nil, errors.Errorf("cluster client of %s has no rest config", clusterClient.GetClusterName())
+	}
+	return pooled.GetRESTConfig()
+}
@@ -19,7 +19,10 @@ package spirebootstrap
 import (
 	"testing"
 
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Tests
@@ -147,3 +150,29 @@ func TestGetServiceExternalIP(t *testing.T) {
 		})
 	}
 }
+
+// the rest config of the credentials is used, not the capi kubeconfig key
+func TestGetRESTConfig(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "edge01-sa",
+			Labels: map[string]string{
+				kubeconfig.ClusterNameLabel: "edge01",
+				kubeconfig.ClusterTypeLabel: kubeconfig.TypeServiceAccount,
+			},
+			Annotations: map[string]string{kubeconfig.ServerKey: "https://edge01:6443"},
+		},
+		Data: map[string][]byte{v1.ServiceAccountTokenKey: []byte("token")},
+	}
+	clusterClient, ok := cluster.Cluster{}.GetClusterClient(secret)
+	if !ok {
+		t.Fatalf("no cluster client for the service account secret")
+	}
+	config, err := getRESTConfig(clusterClient)
+	if err != nil {
+		t.Fatalf("getRESTConfig() unexpected error: %v", err)
+	}
+	if config.Host != "https://edge01:6443" || config.BearerToken != "token" {
+		t.Errorf("getRESTConfig() got host %s, token %s", config.Host, config.BearerToken)
+	}
+}
@@ -96,28 +96,12 @@ func getServiceExternalIP(service *v1.Service) (string, error) {
 	return "", nil
 }
 
-func (r *reconciler) createKubeconfigConfigMap(ctx context.Context, clientset *kubernetes.Clientset, clustername string) (*v1.ConfigMap, error) {
+// createKubeconfig returns the kubeconfig the spire server uses to access the
+// cluster with the token
+func createKubeconfig(ctx context.Context, clientset kubernetes.Interface, server, clustername, token string) ([]byte, error) {
 	log := log.FromContext(ctx)
 
-	log.Info("Creating Kubeconfig ConfigMap for the cluster", "clusterName", clustername)
-
-	cmName := types.NamespacedName{Name: "kubeconfigs", Namespace: "spire"}
-	restrictedKC := &v1.ConfigMap{}
-	err := r.Get(ctx, cmName, restrictedKC)
-	if err != nil {
-		msg := "failed to get existing ConfigMap"
-		log.Error(err, msg)
-		return nil, errors.Wrap(err, msg)
-	}
-
-	// Retrieve the ServiceAccount token
-	secret, err := clientset.CoreV1().Secrets("spire").Get(ctx, "agent-sa-secret", metav1.GetOptions{})
-	if err != nil {
-		msg := "failed to get Service Account token"
-		log.Error(err, msg)
-		return nil, errors.Wrap(err, msg)
-	}
-	token := string(secret.Data["token"])
+	log.Info("Creating Kubeconfig for the cluster", "clusterName", clustername)
 
 	// Retrieve the cluster's CA certificate
 	configMap, err := clientset.CoreV1().ConfigMaps("kube-system").Get(ctx, "kube-root-ca.crt", metav1.GetOptions{})
@@ -137,7 +121,7 @@ func (r *reconciler) createKubeconfigConfigMap(ctx context.Context, clientset *k
 				Name: clustername,
 				Cluster: ClusterDetail{
 					CertificateAuthorityData: caCertEncoded,
-					Server:                   clientset.RESTClient().Get().URL().String(),
+					Server:                   server,
 				},
 			},
 		},
@@ -165,26 +149,16 @@ func (r *reconciler) createKubeconfigConfigMap(ctx context.Context, clientset *k
 	// Convert to YAML
 	yamlData, err := yaml.Marshal(&config)
 	if err != nil {
-		msg := "failed to create kubeconfig CM"
+		msg := "failed to create kubeconfig"
 		log.Error(err, msg)
 		return nil, errors.Wrap(err, msg)
 	}
-
-	// Generate a unique key for the new kubeconfig
-	newConfigKey := fmt.Sprintf("kubeconfig-%s", clustername)
-
-	// Add the new kubeconfig to the existing ConfigMap
-	if restrictedKC.Data == nil {
-		restrictedKC.Data = make(map[string]string)
-	}
-	restrictedKC.Data[newConfigKey] = string(yamlData)
-
-	log.Info("Kubeconfig added to the ConfigMap successfully", "clusterName", clustername)
-
-	return restrictedKC, nil
+	return yamlData, nil
 }
 
-func (r *reconciler) updateClusterListConfigMap(ctx context.Context, clusterName string) error {
+// updateClusterListConfigMap adds or updates the cluster in clusters.conf, the
+// attributes of the cluster that are not managed by the controller are kept
+func (r *reconciler) updateClusterListConfigMap(ctx context.Context, clusterName string, allowList []string) error {
 	log := log.FromContext(ctx)
 
 	log.Info("Updating Cluster List...", "ClusterName", clusterName)
@@ -195,57 +169,45 @@ func (r *reconciler) updateClusterListConfigMap(ctx context.Context, clusterName
 		Namespace: "spire",
 		Name:      "clusters",
 	}, cm); err != nil {
-		msg := "failed to create kubeconfig CM"
+		msg := "failed to get Cluster List ConfigMap"
 		log.Error(err, msg)
 		return errors.Wrap(err, msg)
 	}
 
-	// Get the clusters.conf data
-	clustersConf, ok := cm.Data["clusters.conf"]
-	if !ok {
-		// Initialize with basic structure if not exists
-		clustersConf = "clusters = {\n}"
-	}
-
-	// Remove any initial whitespace if present
-	clustersConf = strings.TrimPrefix(clustersConf, "|")
-	clustersConf = strings.TrimSpace(clustersConf)
-
-	// Check if cluster already exists
-	if strings.Contains(clustersConf, fmt.Sprintf(`"%s"`, clusterName)) {
-		return nil
-	}
-
-	// Add new cluster with proper indentation
-	newCluster := fmt.Sprintf(`      "%s" = {
-        service_account_allow_list = ["spire:spire-agent"]
-        kube_config_file = "/run/spire/kubeconfigs/kubeconfig-%s"
-      }`, clusterName, clusterName)
-
-	// Insert the new cluster before the last closing brace
-	lastBraceIndex := strings.LastIndex(clustersConf, "}")
-	if lastBraceIndex != -1 {
-		clustersConf = clustersConf[:lastBraceIndex] + newCluster + "\n" + clustersConf[lastBraceIndex:]
-	} else {
-		return fmt.Errorf("invalid clusters.conf format")
-	}
-
-	// Format the final content with pipe operator and proper indentation
-	formattedConf := "|\n    " + strings.ReplaceAll(clustersConf, "\n", "\n    ")
-
-	// Update the ConfigMap
-	cm.Data = map[string]string{
-		"clusters.conf": formattedConf,
+	cfg, err := parseClustersConfig(cm.Data["clusters.conf"])
+	if err != nil {
+		msg := "invalid clusters.conf"
+		log.Error(err, msg)
+		return errors.Wrap(err, msg)
 	}
+	cc := cfg.Clusters[clusterName]
+	cc.ServiceAccountAllowList = allowList
+	cc.KubeConfigFile = fmt.Sprintf("/run/spire/kubeconfigs/kubeconfig-%s", clusterName)
+	cfg.SetCluster(clusterName, cc)
 
-	// Apply the changes
-	if err := r.Update(ctx, cm); err != nil {
+	updated, err := r.updateClustersConf(ctx, cm, cfg)
+	if err != nil {
 		msg := "error updating Cluster List ConfigMap"
 		log.Error(err, msg)
 		return errors.Wrap(err, msg)
 	}
-
-	log.Info("Cluster added to the Cluster List", "clusterName", clusterName)
-
+	if updated {
+		log.Info("Cluster updated in the Cluster List", "clusterName", clusterName)
+	}
 	return nil
 }
+
+// updateClustersConf renders the config in the clusters.conf of the
+// configMap, the configMap is only updated when the content changed and its
+// other data is kept
+func (r *reconciler) updateClustersConf(ctx context.Context, cm *v1.ConfigMap, cfg *ClustersConfig) (bool, error) {
+	clustersConf := cfg.Render()
+	if cm.Data["clusters.conf"] == clustersConf {
+		return false, nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data["clusters.conf"] = clustersConf
+	return true, r.Update(ctx, cm)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// kubeconfigsName is the secret with the kubeconfigs of the clusters the
+	// spire server mounts in /run/spire/kubeconfigs
+	kubeconfigsName = "kubeconfigs"
+	// tokenExpiryKey is the annotation of the kubeconfigs secret with the
+	// expiry and refresh time of the token of each cluster
+	tokenExpiryKey = "nephio.org/token-expiry"
+	// tokenServiceAccountKey is the annotation of the cluster with the service
+	// account in the spire namespace the tokens are requested for
+	tokenServiceAccountKey = "nephio.org/spire-token-service-account"
+	// defaultTokenServiceAccount is the service account of the spire agent
+	defaultTokenServiceAccount = "spire-agent"
+	// defaultTokenExpiration is the requested lifetime of the tokens,
+	// overwritten by SPIRE_TOKEN_EXPIRATION
+	defaultTokenExpiration = time.Hour
+	// minTokenExpiration is the minimum lifetime of the TokenRequest api
+	minTokenExpiration = 10 * time.Minute
+	// defaultKubeconfigsConfigMap removes the kubeconfigs from the configMap of
+	// previous versions of the controller, overwritten by
+	// SPIRE_KUBECONFIGS_CONFIGMAP
+	defaultKubeconfigsConfigMap = false
+)
+
+// getTokenExpiration returns the lifetime of the tokens requested for the
+// kubeconfigs
+func getTokenExpiration(ctx context.Context) time.Duration {
+	v, ok := os.LookupEnv("SPIRE_TOKEN_EXPIRATION")
+	if !ok {
+		return defaultTokenExpiration
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < minTokenExpiration {
+		log.FromContext(ctx).Error(err, "invalid SPIRE_TOKEN_EXPIRATION, using default", "value", v, "min", minTokenExpiration, "default", defaultTokenExpiration)
+		return defaultTokenExpiration
+	}
+	return d
+}
+
+// getKubeconfigsConfigMap returns whether the kubeconfigs are also stored in
+// the kubeconfigs configMap, spire servers deployed with previous versions of
+// the controller mount the configMap in /run/spire/kubeconfigs. It is enabled
+// until the spire server mounts the secret, otherwise the configMap entries are
+// removed.
+func getKubeconfigsConfigMap(ctx context.Context) bool {
+	v, ok := os.LookupEnv("SPIRE_KUBECONFIGS_CONFIGMAP")
+	if !ok {
+		return defaultKubeconfigsConfigMap
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "invalid SPIRE_KUBECONFIGS_CONFIGMAP, using default", "value", v, "default", defaultKubeconfigsConfigMap)
+		return defaultKubeconfigsConfigMap
+	}
+	return b
+}
+
+// tokenStatus is the expiry of the token of a cluster and the time it is
+// refreshed, when two thirds of its lifetime passed
+type tokenStatus struct {
+	Expiry  time.Time `json:"expiry"`
+	Refresh time.Time `json:"refresh"`
+}
+
+func newTokenStatus(issued, expiry time.Time) tokenStatus {
+	return tokenStatus{Expiry: expiry, Refresh: expiry.Add(-expiry.Sub(issued) / 3)}
+}
+
+func getTokenServiceAccount(cl *capiv1beta1.Cluster) string {
+	if sa := cl.GetAnnotations()[tokenServiceAccountKey]; sa != "" {
+		return sa
+	}
+	return defaultTokenServiceAccount
+}
+
+// getTokenStatus returns the token status in the annotation of the secret per
+// cluster, an invalid annotation is treated as no status so the tokens are
+// refreshed
+func getTokenStatus(secret *v1.Secret) map[string]tokenStatus {
+	status := map[string]tokenStatus{}
+	if v, ok := secret.GetAnnotations()[tokenExpiryKey]; ok {
+		if err := json.Unmarshal([]byte(v), &status); err != nil {
+			return map[string]tokenStatus{}
+		}
+	}
+	return status
+}
+
+// setTokenStatus writes the token status in the annotation of the secret, the
+// annotation is removed when there is no status
+func setTokenStatus(secret *v1.Secret, status map[string]tokenStatus) error {
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(status) == 0 {
+		delete(annotations, tokenExpiryKey)
+	} else {
+		b, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		annotations[tokenExpiryKey] = string(b)
+	}
+	secret.SetAnnotations(annotations)
+	return nil
+}
+
+// getKubeconfigSecret returns the kubeconfigs secret, a secret that does not
+// exist yet is returned without resource version
+func (r *reconciler) getKubeconfigSecret(ctx context.Context) (*v1.Secret, error) {
+	secret := &v1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kubeconfigsName, Namespace: "spire"}, secret); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: kubeconfigsName, Namespace: "spire"},
+			Type:       v1.SecretTypeOpaque,
+		}, nil
+	}
+	return secret, nil
+}
+
+func (r *reconciler) saveKubeconfigSecret(ctx context.Context, secret *v1.Secret) error {
+	if secret.GetResourceVersion() == "" {
+		return r.Create(ctx, secret)
+	}
+	return r.Update(ctx, secret)
+}
+
+// updateKubeconfig requests a token for the service account of the cluster and
+// stores the kubeconfig with the token in the kubeconfigs secret. The token is
+// only requested when there is none or two thirds of its lifetime passed. It
+// returns the time the token is to be refreshed.
+func (r *reconciler) updateKubeconfig(ctx context.Context, clientset kubernetes.Interface, server string, cl *capiv1beta1.Cluster) (time.Time, error) {
+	log := log.FromContext(ctx)
+	expiration := getTokenExpiration(ctx)
+	key := fmt.Sprintf("kubeconfig-%s", cl.Name)
+
+	secret, err := r.getKubeconfigSecret(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "cannot get kubeconfigs secret")
+	}
+	status := getTokenStatus(secret)
+	if _, ok := secret.Data[key]; ok && time.Now().Before(status[cl.Name].Refresh) {
+		return status[cl.Name].Refresh, nil
+	}
+
+	issued := time.Now()
+	expirationSeconds := int64(expiration.Seconds())
+	tr, err := clientset.CoreV1().ServiceAccounts("spire").CreateToken(ctx, getTokenServiceAccount(cl), &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "cannot request token for service account %s", getTokenServiceAccount(cl))
+	}
+	kubeconfig, err := createKubeconfig(ctx, clientset, server, cl.Name, tr.Status.Token)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = kubeconfig
+	// the api server can change the lifetime of the token
+	status[cl.Name] = newTokenStatus(issued.UTC(), tr.Status.ExpirationTimestamp.UTC())
+	if err := setTokenStatus(secret, status); err != nil {
+		return time.Time{}, err
+	}
+	if err := r.saveKubeconfigSecret(ctx, secret); err != nil {
+		return time.Time{}, errors.Wrap(err, "cannot update kubeconfigs secret")
+	}
+	log.Info("Kubeconfig token refreshed", "clusterName", cl.Name, "expiry", status[cl.Name].Expiry)
+
+	// spire servers that still mount the configMap get the refreshed
+	// kubeconfig, otherwise the kubeconfig with the long-lived token of
+	// previous versions of the controller is removed from the configMap
+	if getKubeconfigsConfigMap(ctx) {
+		err = r.updateLegacyKubeconfig(ctx, cl.Name, kubeconfig)
+	} else {
+		err = r.removeLegacyKubeconfig(ctx, cl.Name)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return status[cl.Name].Refresh, nil
+}
+
+// updateLegacyKubeconfig stores the kubeconfig as kubeconfig-<cluster> in the
+// kubeconfigs configMap, a missing configMap is not created as no spire server
+// mounts it
+func (r *reconciler) updateLegacyKubeconfig(ctx context.Context, clusterName string, kubeconfig []byte) error {
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kubeconfigsName, Namespace: "spire"}, cm); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), "cannot get kubeconfigs configMap")
+	}
+	key := fmt.Sprintf("kubeconfig-%s", clusterName)
+	if cm.Data[key] == string(kubeconfig) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[key] = string(kubeconfig)
+	if err := r.Update(ctx, cm); err != nil {
+		return errors.Wrap(err, "cannot update kubeconfigs configMap")
+	}
+	log.FromContext(ctx).Info("Kubeconfig updated in the ConfigMap", "clusterName", clusterName)
+	return nil
+}
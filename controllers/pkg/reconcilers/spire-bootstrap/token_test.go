@@ -0,0 +1,290 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spirebootstrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/stretchr/testify/mock"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// newTestClientset returns a clientset of a workload cluster that issues
+// tokens for the spire-agent service account with the lifetime requested
+func newTestClientset(requests *int) *fake.Clientset {
+	clientset := fake.NewClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "kube-root-ca.crt"},
+		Data:       map[string]string{"ca.crt": "ca"},
+	})
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateAction)
+		if create.GetSubresource() != "token" || create.GetNamespace() != "spire" || create.(k8stesting.CreateActionImpl).Name != "spire-agent" {
+			return true, nil, kerrors.NewNotFound(schema.GroupResource{Resource: "serviceaccounts"}, "spire-agent")
+		}
+		*requests++
+		tr := create.GetObject().(*authenticationv1.TokenRequest)
+		tr.Status = authenticationv1.TokenRequestStatus{
+			Token:               "token",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(*tr.Spec.ExpirationSeconds) * time.Second)),
+		}
+		return true, tr, nil
+	})
+	return clientset
+}
+
+func TestUpdateKubeconfig(t *testing.T) {
+	now := time.Now().UTC()
+	testCases := []struct {
+		name          string
+		secret        *v1.Secret
+		expectRequest bool
+		expectCreate  bool
+	}{
+		{
+			name:          "no-secret",
+			expectRequest: true,
+			expectCreate:  true,
+		},
+		{
+			name: "valid-token",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: kubeconfigsName, ResourceVersion: "1"},
+				Data:       map[string][]byte{"kubeconfig-edge01": []byte("kubeconfig")},
+			},
+		},
+		{
+			name: "refresh-token",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: kubeconfigsName, ResourceVersion: "1"},
+				Data:       map[string][]byte{"kubeconfig-edge01": []byte("kubeconfig"), "kubeconfig-edge02": []byte("kubeconfig")},
+			},
+			expectRequest: true,
+		},
+		{
+			name: "no-kubeconfig",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: kubeconfigsName, ResourceVersion: "1"},
+				Data:       map[string][]byte{},
+			},
+			expectRequest: true,
+		},
+	}
+	// the token of valid-token is refreshed in 30 minutes, the one of
+	// refresh-token should have been refreshed 10 minutes ago
+	testCases[1].secret.SetAnnotations(map[string]string{tokenExpiryKey: statusAnnotation(t, map[string]tokenStatus{"edge01": newTokenStatus(now.Add(-30*time.Minute), now.Add(time.Hour))})})
+	testCases[2].secret.SetAnnotations(map[string]string{tokenExpiryKey: statusAnnotation(t, map[string]tokenStatus{
+		"edge01": newTokenStatus(now.Add(-50*time.Minute), now.Add(10*time.Minute)),
+		"edge02": newTokenStatus(now, now.Add(time.Hour)),
+	})})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var saved *v1.Secret
+			created := false
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: kubeconfigsName}, mock.AnythingOfType("*v1.Secret")).Return(func() error {
+				if tc.secret == nil {
+					return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, kubeconfigsName)
+				}
+				return nil
+			}()).Run(func(args mock.Arguments) {
+				if tc.secret != nil {
+					tc.secret.DeepCopyInto(args.Get(2).(*v1.Secret))
+				}
+			})
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: kubeconfigsName}, mock.AnythingOfType("*v1.ConfigMap")).Return(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, kubeconfigsName))
+			clientMock.On("Create", context.TODO(), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				created = true
+				saved = args.Get(1).(*v1.Secret)
+			})
+			clientMock.On("Update", context.TODO(), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*v1.Secret)
+			})
+
+			requests := 0
+			r := &reconciler{Client: clientMock}
+			cl := &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}
+			refresh, err := r.updateKubeconfig(context.TODO(), newTestClientset(&requests), "https://edge01:6443", cl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (requests == 1) != tc.expectRequest {
+				t.Errorf("expected token request %t, got %d requests", tc.expectRequest, requests)
+			}
+			if !refresh.After(time.Now()) {
+				t.Errorf("expected refresh in the future, got %v", refresh)
+			}
+			if !tc.expectRequest {
+				if saved != nil {
+					t.Error("expected secret not to be saved")
+				}
+				return
+			}
+			if saved == nil {
+				t.Fatal("expected secret to be saved")
+			}
+			if created != tc.expectCreate {
+				t.Errorf("expected create %t, got %t", tc.expectCreate, created)
+			}
+			if !strings.Contains(string(saved.Data["kubeconfig-edge01"]), "token: token") {
+				t.Errorf("expected kubeconfig with the token, got\n%s", saved.Data["kubeconfig-edge01"])
+			}
+			status := getTokenStatus(saved)
+			expected := now.Add(defaultTokenExpiration)
+			if status["edge01"].Expiry.Sub(expected).Abs() > time.Minute {
+				t.Errorf("expected expiry around %v, got %v", expected, status["edge01"].Expiry)
+			}
+			if !status["edge01"].Refresh.Equal(refresh) {
+				t.Errorf("expected refresh %v, got %v", refresh, status["edge01"].Refresh)
+			}
+			// the other clusters are kept
+			if tc.secret != nil && len(saved.Data) != max(len(tc.secret.Data), 1) {
+				t.Errorf("expected the other kubeconfigs to be kept, got %v", saved.Data)
+			}
+		})
+	}
+}
+
+func TestNewTokenStatus(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := newTokenStatus(issued, issued.Add(90*time.Minute))
+	if expected := issued.Add(time.Hour); !status.Refresh.Equal(expected) {
+		t.Errorf("expected refresh %v, got %v", expected, status.Refresh)
+	}
+}
+
+func TestGetTokenExpiration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "default", expected: defaultTokenExpiration},
+		{name: "set", value: "2h", expected: 2 * time.Hour},
+		{name: "invalid", value: "often", expected: defaultTokenExpiration},
+		{name: "too-short", value: "1m", expected: defaultTokenExpiration},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv("SPIRE_TOKEN_EXPIRATION", tc.value)
+			}
+			if got := getTokenExpiration(context.TODO()); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateKubeconfigConfigMap(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expectKey bool
+	}{
+		{name: "default"},
+		// the spire server still mounts the configMap
+		{name: "enabled", value: "true", expectKey: true},
+		{name: "disabled", value: "false"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv("SPIRE_KUBECONFIGS_CONFIGMAP", tc.value)
+			}
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: kubeconfigsName},
+				Data:       map[string]string{"kubeconfig-edge01": "long-lived", "kubeconfig-edge02": "long-lived"},
+			}
+			var saved *v1.ConfigMap
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: kubeconfigsName}, mock.AnythingOfType("*v1.Secret")).Return(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, kubeconfigsName))
+			clientMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: kubeconfigsName}, mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+				cm.DeepCopyInto(args.Get(2).(*v1.ConfigMap))
+			})
+			clientMock.On("Create", context.TODO(), mock.AnythingOfType("*v1.Secret")).Return(nil)
+			clientMock.On("Update", context.TODO(), mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*v1.ConfigMap)
+			})
+
+			requests := 0
+			r := &reconciler{Client: clientMock}
+			cl := &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}
+			if _, err := r.updateKubeconfig(context.TODO(), newTestClientset(&requests), "https://edge01:6443", cl); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if saved == nil {
+				t.Fatal("expected configMap to be saved")
+			}
+			kubeconfig, ok := saved.Data["kubeconfig-edge01"]
+			if ok != tc.expectKey {
+				t.Errorf("expected kubeconfig in the configMap %t, got %v", tc.expectKey, saved.Data)
+			}
+			if ok && !strings.Contains(kubeconfig, "token: token") {
+				t.Errorf("expected kubeconfig with the token, got\n%s", kubeconfig)
+			}
+			if saved.Data["kubeconfig-edge02"] != "long-lived" {
+				t.Errorf("expected the other kubeconfigs to be kept, got %v", saved.Data)
+			}
+		})
+	}
+}
+
+func TestGetKubeconfigsConfigMap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{name: "default", expected: defaultKubeconfigsConfigMap},
+		{name: "enabled", value: "true", expected: true},
+		{name: "disabled", value: "false", expected: false},
+		{name: "invalid", value: "later", expected: defaultKubeconfigsConfigMap},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv("SPIRE_KUBECONFIGS_CONFIGMAP", tc.value)
+			}
+			if got := getKubeconfigsConfigMap(context.TODO()); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func statusAnnotation(t *testing.T, status map[string]tokenStatus) string {
+	t.Helper()
+	secret := &v1.Secret{}
+	if err := setTokenStatus(secret, status); err != nil {
+		t.Fatal(err)
+	}
+	return secret.GetAnnotations()[tokenExpiryKey]
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// tokenCredentialTypeKey selects the kind of credentials, the default is an
+	// access token of the user of the git client
+	tokenCredentialTypeKey = "token.nephio.org/credential-type"
+	// tokenRepositoryKey is the repository a deploy key or service user gets
+	// access to, <owner>/<repo> for a repository of an organization
+	tokenRepositoryKey = "token.nephio.org/repository"
+	// tokenScopesKey is a comma separated list of scopes
+	tokenScopesKey = "token.nephio.org/scopes"
+
+	credentialTypeToken     = "token"
+	credentialTypeDeployKey = "deploy-key"
+	credentialTypeUser      = "user"
+
+	// secret annotations that track the deploy key and service user
+	secretDeployKeyIDKey = "token.nephio.org/deploy-key-id"
+	secretUserKey        = "token.nephio.org/user"
+
+	serviceUserEmailDomain = "nephio.local"
+	// serviceUserPrefix is reserved for the service users of the controller,
+	// users without the prefix are never replaced or deleted
+	serviceUserPrefix = "nephio-svc-"
+)
+
+// getScopes returns the scopes of the token annotation or the default scope
+func getScopes(cr *infrav1alpha1.Token, defaultScope string) []string {
+	scopes := []string{}
+	for _, s := range strings.Split(cr.GetAnnotations()[tokenScopesKey], ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 {
+		return []string{defaultScope}
+	}
+	return scopes
+}
+
+// getPermission returns the repository permission of deploy keys and service
+// users, they are read only unless the write repo scope is requested
+func getPermission(cr *infrav1alpha1.Token) string {
+	if slices.Contains(getScopes(cr, gitprovider.AccessTokenScopeRepoRead), gitprovider.AccessTokenScopeRepo) {
+		return gitprovider.PermissionWrite
+	}
+	return gitprovider.PermissionRead
+}
+
+// getRepository returns the owner and name of the repository annotation,
+// <owner>/<repo> or <repo> for a repository of the user of the git client
+func getRepository(cr *infrav1alpha1.Token, userName string) (string, string) {
+	repo := cr.GetAnnotations()[tokenRepositoryKey]
+	if owner, name, ok := strings.Cut(repo, "/"); ok {
+		return owner, name
+	}
+	return userName, repo
+}
+
+// getServiceUserName returns the name of the service user of the token
+func getServiceUserName(cr *infrav1alpha1.Token) string {
+	return serviceUserPrefix + cr.GetTokenName()
+}
+
+func getCredentialType(cr *infrav1alpha1.Token) (string, error) {
+	credentialType, ok := cr.GetAnnotations()[tokenCredentialTypeKey]
+	if !ok || credentialType == "" {
+		return credentialTypeToken, nil
+	}
+	switch credentialType {
+	case credentialTypeToken:
+	case credentialTypeDeployKey, credentialTypeUser:
+		if cr.GetAnnotations()[tokenRepositoryKey] == "" {
+			return "", fmt.Errorf("credential type %s requires annotation %s", credentialType, tokenRepositoryKey)
+		}
+	default:
+		return "", fmt.Errorf("unsupported credential type %q, supported: %s, %s, %s", credentialType, credentialTypeToken, credentialTypeDeployKey, credentialTypeUser)
+	}
+	return credentialType, nil
+}
+
+// upsertCredentials creates the credentials of the credential type and writes
+// them in the secret of the token
+func (r *reconciler) upsertCredentials(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) (time.Duration, error) {
+	credentialType, err := getCredentialType(cr)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "cannot get credential type")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	switch credentialType {
+	case credentialTypeDeployKey:
+		return 0, r.upsertDeployKey(ctx, gitClient, cr)
+	case credentialTypeUser:
+		return 0, r.upsertUser(ctx, gitClient, cr)
+	default:
+		return r.upsertToken(ctx, gitClient, cr)
+	}
+}
+
+func (r *reconciler) deleteCredentials(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	credentialType, err := getCredentialType(cr)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "cannot get credential type")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	switch credentialType {
+	case credentialTypeDeployKey:
+		return r.deleteDeployKey(ctx, gitClient, cr)
+	case credentialTypeUser:
+		return r.deleteUser(ctx, gitClient, cr)
+	default:
+		return r.deleteToken(ctx, gitClient, cr)
+	}
+}
+
+// upsertDeployKey adds a deploy key to the repository and writes the private
+// key in a ssh-auth secret, the key is read only unless the repo scope is set
+func (r *reconciler) upsertDeployKey(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	log := log.FromContext(ctx)
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	owner, repo := getRepository(cr, u.UserName)
+	keys, err := gitClient.ListDeployKeys(owner, repo)
+	if err != nil {
+		log.Error(err, "cannot list deploy keys", "repo", repo)
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	title := cr.GetTokenName()
+	if secret != nil && !secretModified(secret) && len(secret.Data[corev1.SSHAuthPrivateKey]) != 0 {
+		for _, k := range keys {
+			if strconv.FormatInt(k.ID, 10) == secret.GetAnnotations()[secretDeployKeyIDKey] {
+				cr.SetConditions(credentialVerified())
+				return nil
+			}
+		}
+	}
+	// the private key of a deploy key without secret is lost, replace it. The
+	// deploy key of a modified secret is replaced as the key might have leaked.
+	reason := secretReason(secret)
+	for _, k := range keys {
+		if k.Title != title {
+			continue
+		}
+		if err := gitClient.DeleteDeployKey(owner, repo, k.ID); err != nil && !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot delete stale deploy key", "repo", repo)
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+	}
+
+	privateKey, publicKey, err := generateSSHKey(title)
+	if err != nil {
+		log.Error(err, "cannot generate ssh key")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	key, err := gitClient.CreateDeployKey(owner, repo, gitprovider.CreateDeployKeyOptions{
+		Title:    title,
+		Key:      publicKey,
+		ReadOnly: getPermission(cr) == gitprovider.PermissionRead,
+	})
+	if err != nil {
+		log.Error(err, "cannot create deploy key", "repo", repo)
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	log.Info("deploy key created", "name", cr.GetName(), "repo", repo, "reason", reason)
+	cr.SetConditions(credentialRegenerated(reason))
+	return r.writeSecret(ctx, cr, secret, corev1.SecretTypeSSHAuth, map[string]string{
+		secretDeployKeyIDKey: strconv.FormatInt(key.ID, 10),
+	}, map[string][]byte{
+		corev1.SSHAuthPrivateKey: privateKey,
+		"ssh":                    privateKey, // needed for configsync
+	})
+}
+
+func (r *reconciler) deleteDeployKey(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	log := log.FromContext(ctx)
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	owner, repo := getRepository(cr, u.UserName)
+	keys, err := gitClient.ListDeployKeys(owner, repo)
+	if err != nil {
+		if gitprovider.IsNotFound(err) {
+			// the repo is gone together with its deploy keys
+			return nil
+		}
+		log.Error(err, "cannot list deploy keys", "repo", repo)
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	for _, k := range keys {
+		if k.Title != cr.GetTokenName() {
+			continue
+		}
+		if err := gitClient.DeleteDeployKey(owner, repo, k.ID); err != nil && !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot delete deploy key", "repo", repo)
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+		log.Info("deploy key deleted", "name", cr.GetName(), "repo", repo)
+	}
+	return nil
+}
+
+// upsertUser creates a service user that is a collaborator of the repository
+// and writes its credentials in a basic-auth secret
+func (r *reconciler) upsertUser(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	log := log.FromContext(ctx)
+	userName := getServiceUserName(cr)
+
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil {
+		return err
+	}
+	userFound := true
+	if _, err := gitClient.GetUser(userName); err != nil {
+		if !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot get user", "user", userName)
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+		userFound = false
+	}
+	if userFound && secret != nil && !secretModified(secret) && len(secret.Data["password"]) != 0 && secret.GetAnnotations()[secretUserKey] == userName {
+		cr.SetConditions(credentialVerified())
+		return nil
+	}
+	// the password of a user without secret is lost, replace the user. The
+	// user of a modified secret is replaced as the password might have leaked.
+	reason := secretReason(secret)
+	if userFound {
+		if err := gitClient.DeleteUser(userName); err != nil && !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot delete stale user", "user", userName)
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+	}
+
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	owner, repo := getRepository(cr, u.UserName)
+	password, err := generatePassword()
+	if err != nil {
+		log.Error(err, "cannot generate password")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if _, err := gitClient.CreateUser(gitprovider.CreateUserOptions{
+		UserName: userName,
+		Email:    fmt.Sprintf("%s@%s", userName, serviceUserEmailDomain),
+		Password: password,
+	}); err != nil {
+		log.Error(err, "cannot create user", "user", userName)
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if err := gitClient.AddCollaborator(owner, repo, userName, getPermission(cr)); err != nil {
+		log.Error(err, "cannot add collaborator", "user", userName, "repo", repo)
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	log.Info("user created", "name", cr.GetName(), "user", userName, "repo", repo, "reason", reason)
+	cr.SetConditions(credentialRegenerated(reason))
+	return r.writeSecret(ctx, cr, secret, corev1.SecretTypeBasicAuth, map[string]string{
+		secretUserKey: userName,
+	}, map[string][]byte{
+		"username": []byte(userName),
+		"password": []byte(password), // needed for porch
+		"token":    []byte(password), // needed for configsync
+	})
+}
+
+func (r *reconciler) deleteUser(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	userName := getServiceUserName(cr)
+	if err := gitClient.DeleteUser(userName); err != nil && !gitprovider.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "cannot delete user")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	log.FromContext(ctx).Info("user deleted", "name", cr.GetName(), "user", userName)
+	return nil
+}
+
+// generateSSHKey returns an ed25519 private key in openssh pem format and the
+// public key in authorized_keys format
+func generateSSHKey(comment string) ([]byte, string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return nil, "", err
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, "", err
+	}
+	return pem.EncodeToMemory(block), strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), nil
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
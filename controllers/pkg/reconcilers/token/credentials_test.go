@@ -0,0 +1,248 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"reflect"
+	"testing"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/testing/mockeryutils"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetCredentialType(t *testing.T) {
+	tests := map[string]struct {
+		annotations    map[string]string
+		want           string
+		wantPermission string
+		wantErr        bool
+	}{
+		"default": {
+			want:           credentialTypeToken,
+			wantPermission: gitprovider.PermissionRead,
+		},
+		"deploy key": {
+			annotations:    map[string]string{tokenCredentialTypeKey: credentialTypeDeployKey, tokenRepositoryKey: "edge01"},
+			want:           credentialTypeDeployKey,
+			wantPermission: gitprovider.PermissionRead,
+		},
+		"user with write access": {
+			annotations: map[string]string{
+				tokenCredentialTypeKey: credentialTypeUser,
+				tokenRepositoryKey:     "edge01",
+				tokenScopesKey:         "repo",
+			},
+			want:           credentialTypeUser,
+			wantPermission: gitprovider.PermissionWrite,
+		},
+		"deploy key without repository": {
+			annotations: map[string]string{tokenCredentialTypeKey: credentialTypeDeployKey},
+			wantErr:     true,
+		},
+		"unknown type": {
+			annotations: map[string]string{tokenCredentialTypeKey: "certificate"},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := getCredentialType(cr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCredentialType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("getCredentialType() got = %s, want %s", got, tt.want)
+			}
+			if got := getPermission(cr); got != tt.wantPermission {
+				t.Errorf("getPermission() got = %s, want %s", got, tt.wantPermission)
+			}
+		})
+	}
+}
+
+func TestGetScopes(t *testing.T) {
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		tokenScopesKey: "repo:read, read:user,",
+	}}}
+	if got, want := getScopes(cr, gitprovider.AccessTokenScopeRepo), []string{"repo:read", "read:user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getScopes() got = %v, want %v", got, want)
+	}
+	if got, want := getScopes(&infrav1alpha1.Token{}, gitprovider.AccessTokenScopeRepo), []string{"repo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getScopes() got = %v, want %v", got, want)
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	for annotation, want := range map[string][2]string{
+		"edge01":            {"nephio", "edge01"},
+		"nephio-org/edge01": {"nephio-org", "edge01"},
+	} {
+		cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{tokenRepositoryKey: annotation}}}
+		if owner, repo := getRepository(cr, "nephio"); owner != want[0] || repo != want[1] {
+			t.Errorf("getRepository(%s) got = %s/%s, want %s/%s", annotation, owner, repo, want[0], want[1])
+		}
+	}
+}
+
+func TestUpsertDeployKey(t *testing.T) {
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "test-ns",
+		Name:      "edge01-configsync",
+		Annotations: map[string]string{
+			tokenCredentialTypeKey: credentialTypeDeployKey,
+			tokenRepositoryKey:     "edge01",
+		},
+	}}
+
+	var secret *corev1.Secret
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {})
+	clientMock.On("Patch", nil, mock.AnythingOfType("*v1.Secret"), mock.AnythingOfType("*resource.patch")).Return(nil).Run(func(args mock.Arguments) {
+		secret = args.Get(1).(*corev1.Secret)
+	})
+
+	gitClient := new(gitprovider.MockGitClient)
+	mockeryutils.InitMocks(&gitClient.Mock, []mockeryutils.MockHelper{
+		{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "nephio"}, nil}},
+		// a stale key without secret is replaced
+		{MethodName: "ListDeployKeys", ArgType: []string{"string", "string"}, RetArgList: []interface{}{[]*gitprovider.DeployKey{{ID: 1, Title: "edge01-configsync-test-ns"}}, nil}},
+		{MethodName: "DeleteDeployKey", ArgType: []string{"string", "string", "int64"}, RetArgList: []interface{}{nil}},
+		{MethodName: "CreateDeployKey", ArgType: []string{"string", "string", "gitprovider.CreateDeployKeyOptions"}, RetArgList: []interface{}{&gitprovider.DeployKey{ID: 2}, nil}},
+	})
+
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock)}
+	if _, err := r.upsertCredentials(nil, gitClient, cr); err != nil {
+		t.Fatalf("upsertCredentials() error = %v", err)
+	}
+
+	gitClient.AssertCalled(t, "DeleteDeployKey", "nephio", "edge01", int64(1))
+	createOpts := gitClient.Calls[len(gitClient.Calls)-1].Arguments.Get(2).(gitprovider.CreateDeployKeyOptions)
+	if !createOpts.ReadOnly || createOpts.Title != "edge01-configsync-test-ns" {
+		t.Errorf("CreateDeployKey() opts = %+v, want read only key", createOpts)
+	}
+	if secret == nil {
+		t.Fatalf("secret not written")
+	}
+	if secret.Type != corev1.SecretTypeSSHAuth || secret.Annotations[secretDeployKeyIDKey] != "2" {
+		t.Errorf("secret type = %s, annotations = %v", secret.Type, secret.Annotations)
+	}
+	// the private key matches the public key of the deploy key
+	signer, err := ssh.ParsePrivateKey(secret.Data[corev1.SSHAuthPrivateKey])
+	if err != nil {
+		t.Fatalf("cannot parse private key: %v", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(createOpts.Key))
+	if err != nil {
+		t.Fatalf("cannot parse public key: %v", err)
+	}
+	if !reflect.DeepEqual(signer.PublicKey().Marshal(), pub.Marshal()) {
+		t.Errorf("private key does not match the deploy key")
+	}
+}
+
+func TestUpsertUser(t *testing.T) {
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Name: "edge01-porch",
+		Annotations: map[string]string{
+			tokenCredentialTypeKey: credentialTypeUser,
+			tokenRepositoryKey:     "edge01",
+		},
+	}}
+
+	tests := map[string]struct {
+		secretData map[string][]byte
+		mocks      []mockeryutils.MockHelper
+		wantCreate bool
+	}{
+		"new user": {
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetUser", ArgType: []string{"string"}, RetArgList: []interface{}{nil, gitprovider.ErrNotFound}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "nephio"}, nil}},
+				{MethodName: "CreateUser", ArgType: []string{"gitprovider.CreateUserOptions"}, RetArgList: []interface{}{&gitprovider.User{UserName: "nephio-svc-edge01-porch"}, nil}},
+				{MethodName: "AddCollaborator", ArgType: []string{"string", "string", "string", "string"}, RetArgList: []interface{}{nil}},
+			},
+			wantCreate: true,
+		},
+		"user and secret exist": {
+			secretData: map[string][]byte{"password": []byte("secret")},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetUser", ArgType: []string{"string"}, RetArgList: []interface{}{&gitprovider.User{UserName: "nephio-svc-edge01-porch"}, nil}},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				if tt.secretData != nil {
+					s := args.Get(2).(*corev1.Secret)
+					s.Data = tt.secretData
+					s.Annotations = map[string]string{secretUserKey: "nephio-svc-edge01-porch", secretDataHashKey: dataHash(s.Data)}
+				}
+			})
+			clientMock.On("Patch", nil, mock.AnythingOfType("*v1.Secret"), mock.AnythingOfType("*resource.patch")).Return(nil)
+			gitClient := new(gitprovider.MockGitClient)
+			mockeryutils.InitMocks(&gitClient.Mock, tt.mocks)
+
+			r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock)}
+			if _, err := r.upsertCredentials(nil, gitClient, cr); err != nil {
+				t.Fatalf("upsertCredentials() error = %v", err)
+			}
+			// a user named after the token that was not created by the
+			// controller is never looked up, replaced or deleted
+			gitClient.AssertCalled(t, "GetUser", "nephio-svc-edge01-porch")
+			if tt.wantCreate {
+				gitClient.AssertCalled(t, "AddCollaborator", "nephio", "edge01", "nephio-svc-edge01-porch", gitprovider.PermissionRead)
+			} else {
+				gitClient.AssertNotCalled(t, "CreateUser", mock.Anything)
+			}
+			gitClient.AssertNotCalled(t, "DeleteUser", "edge01-porch")
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Name: "edge01-porch",
+		Annotations: map[string]string{
+			tokenCredentialTypeKey: credentialTypeUser,
+			tokenRepositoryKey:     "edge01",
+		},
+	}}
+	gitClient := new(gitprovider.MockGitClient)
+	mockeryutils.InitMocks(&gitClient.Mock, []mockeryutils.MockHelper{
+		{MethodName: "DeleteUser", ArgType: []string{"string"}, RetArgList: []interface{}{nil}},
+	})
+
+	r := &reconciler{}
+	if err := r.deleteCredentials(nil, gitClient, cr); err != nil {
+		t.Fatalf("deleteCredentials() error = %v", err)
+	}
+	gitClient.AssertCalled(t, "DeleteUser", "nephio-svc-edge01-porch")
+	gitClient.AssertNumberOfCalls(t, "DeleteUser", 1)
+}
@@ -20,11 +20,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
-	"code.gitea.io/sdk/gitea"
 	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
@@ -32,10 +32,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func init() {
@@ -50,17 +53,18 @@ const (
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git client, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
 
 	var e error
-	r.giteaClient, e = giteaclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	r.gitClient, e = gitprovider.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
 	if e != nil {
 		return nil, e
 	}
@@ -79,13 +83,33 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("TokenController").
 		For(&infrav1alpha1.Token{}).
+		// the secret is regenerated when it is deleted or modified
+		Owns(&corev1.Secret{}).
+		// reconnect the git client when its secret changes and requeue the
+		// tokens once it reconnected
+		Watches(&corev1.Secret{}, gitprovider.SecretEventHandler(r.gitClient)).
+		WatchesRawSource(gitprovider.ReconnectSource(ctx, r.gitClient, r.listTokens)).
 		Complete(r)
 }
 
+// listTokens returns the requests of all tokens
+func (r *reconciler) listTokens(ctx context.Context, _ client.Object) []reconcile.Request {
+	tokens := &infrav1alpha1.TokenList{}
+	if err := r.List(ctx, tokens); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list tokens")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(tokens.Items))
+	for _, token := range tokens.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: token.GetNamespace(), Name: token.GetName()}})
+	}
+	return requests
+}
+
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
-	finalizer   *resource.APIFinalizer
+	gitClient gitprovider.GitClient
+	finalizer *resource.APIFinalizer
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -103,8 +127,8 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// check if client exists otherwise retry
-	if !r.giteaClient.IsInitialized() {
-		err := fmt.Errorf("gitea server unreachable")
+	if !r.gitClient.IsInitialized() {
+		err := fmt.Errorf("git server unreachable")
 		log.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
@@ -116,7 +140,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// Delete the token from the git server
 		// when successful remove the finalizer
 		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteToken(ctx, r.giteaClient, cr); err != nil {
+			if err := r.deleteCredentials(ctx, r.gitClient, cr); err != nil {
 				log.Error(err, "cannot delete token in git server")
 				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 			}
@@ -139,92 +163,306 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
-	// create token and secret
-	if err := r.createToken(ctx, r.giteaClient, cr); err != nil {
+	// create or rotate the credentials and update the secret
+	requeueAfter, err := r.upsertCredentials(ctx, r.gitClient, cr)
+	if err != nil {
+		cr.SetConditions(credentialNotVerified(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	cr.SetConditions(infrav1alpha1.Ready())
-	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	return ctrl.Result{RequeueAfter: requeueAfter}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) createToken(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Token) error {
+// upsertToken makes sure the secret holds a valid token of the git server. A
+// new token is created when the secret or the token is gone, and replaced
+// when the rotation interval expired. The previous token is revoked after the
+// grace period. It returns the time after which the token needs attention.
+func (r *reconciler) upsertToken(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) (time.Duration, error) {
 	log := log.FromContext(ctx)
-	tokens, _, err := giteaClient.ListAccessTokens(gitea.ListAccessTokensOptions{})
+	now := time.Now()
+
+	rot, err := getRotation(cr)
+	if err != nil {
+		log.Error(err, "cannot get token rotation")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+
+	tokens, err := gitClient.ListAccessTokens()
 	if err != nil {
 		log.Error(err, "cannot list tokens")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return err
+		return 0, err
 	}
-	tokenFound := false
+	existingTokens := map[string]bool{}
 	for _, token := range tokens {
-		if token.Name == cr.GetTokenName() {
-			tokenFound = true
-			break
+		existingTokens[token.Name] = true
+	}
+
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil {
+		return 0, err
+	}
+
+	tokenName := getSecretTokenName(cr, secret)
+	if secret == nil || secretModified(secret) || len(secret.Data["token"]) == 0 || !existingTokens[tokenName] {
+		// the value of a token can only be retrieved when it is created, so
+		// a token without secret is replaced. The tokens referenced by a
+		// modified secret are revoked as they might have leaked.
+		reason := secretReason(secret)
+		newTokenName := rot.tokenName(cr, now)
+		staleTokenNames := []string{newTokenName, tokenName}
+		if secret != nil {
+			staleTokenNames = append(staleTokenNames, secret.GetAnnotations()[secretPreviousTokenNameKey])
 		}
+		for _, staleTokenName := range staleTokenNames {
+			if !existingTokens[staleTokenName] {
+				continue
+			}
+			if err := gitClient.DeleteAccessToken(staleTokenName); err != nil && !gitprovider.IsNotFound(err) {
+				log.Error(err, "cannot delete stale token", "token", staleTokenName)
+				cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+				return 0, err
+			}
+			delete(existingTokens, staleTokenName)
+		}
+		if err := r.applyToken(ctx, gitClient, cr, secret, rot, newTokenName, "", now); err != nil {
+			return 0, err
+		}
+		log.Info("token created", "name", cr.GetName(), "token", newTokenName, "reason", reason)
+		cr.SetConditions(credentialRegenerated(reason))
+		return r.nextRequeue(cr, rot, now, now, time.Time{}), nil
 	}
-	if !tokenFound {
-		u, _, err := giteaClient.GetMyUserInfo()
-		if err != nil {
-			log.Error(err, "cannot get user info")
-			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+	cr.SetConditions(credentialVerified())
+
+	createdAt, ok := parseTime(secret.GetAnnotations(), secretCreatedAtKey)
+	if !ok {
+		createdAt = secret.GetCreationTimestamp().Time
+	}
+
+	// revoke the previous token once the grace period expired
+	revokeAfter := time.Time{}
+	if previous := secret.GetAnnotations()[secretPreviousTokenNameKey]; previous != "" {
+		revokeAfter, _ = parseTime(secret.GetAnnotations(), secretRevokeAfterKey)
+		if !now.Before(revokeAfter) {
+			if err := gitClient.DeleteAccessToken(previous); err != nil && !gitprovider.IsNotFound(err) {
+				log.Error(err, "cannot revoke previous token", "token", previous)
+				cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+				return 0, err
+			}
+			annotations := secret.GetAnnotations()
+			delete(annotations, secretPreviousTokenNameKey)
+			delete(annotations, secretRevokeAfterKey)
+			secret.SetAnnotations(annotations)
+			if err := r.Update(ctx, secret); err != nil {
+				log.Error(err, "cannot update secret")
+				cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+				return 0, err
+			}
+			log.Info("previous token revoked", "name", cr.GetName(), "token", previous)
+			revokeAfter = time.Time{}
 		}
+	}
+
+	// rotate the token, a single rotation is in progress at a time
+	if rot.enabled() && revokeAfter.IsZero() && !now.Before(createdAt.Add(rot.interval)) {
+		newTokenName := rot.tokenName(cr, now)
+		if err := r.applyToken(ctx, gitClient, cr, secret, rot, newTokenName, tokenName, now); err != nil {
+			return 0, err
+		}
+		log.Info("token rotated", "name", cr.GetName(), "token", newTokenName, "previous", tokenName)
+		return r.nextRequeue(cr, rot, now, now, now.Add(rot.gracePeriod)), nil
+	}
+	return r.nextRequeue(cr, rot, now, createdAt, revokeAfter), nil
+}
+
+// nextRequeue sets the rotation condition and returns the duration until the
+// next rotation or revocation of the previous token
+func (r *reconciler) nextRequeue(cr *infrav1alpha1.Token, rot *rotation, now, createdAt, revokeAfter time.Time) time.Duration {
+	var next time.Time
+	if rot.enabled() {
+		next = createdAt.Add(rot.interval)
+		cr.SetConditions(rotationCondition(createdAt, next))
+	}
+	if !revokeAfter.IsZero() && (next.IsZero() || revokeAfter.Before(next)) {
+		next = revokeAfter
+	}
+	if next.IsZero() {
+		return 0
+	}
+	if d := next.Sub(now); d > 0 {
+		return d
+	}
+	return time.Second
+}
+
+// applyToken creates a new token in the git server and writes it in the
+// secret in a single update, the previous token is recorded in the secret so
+// it gets revoked after the grace period
+func (r *reconciler) applyToken(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token, secret *corev1.Secret, rot *rotation, tokenName, previous string, now time.Time) error {
+	log := log.FromContext(ctx)
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+
+	token, err := gitClient.CreateAccessToken(gitprovider.CreateAccessTokenOptions{
+		Name:      tokenName,
+		Scopes:    getScopes(cr, gitprovider.AccessTokenScopeRepo),
+		ExpiresAt: rot.expiresAt(now),
+	})
+	if err != nil {
+		log.Error(err, "cannot create token")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
 
-		token, _, err := giteaClient.CreateAccessToken(gitea.CreateAccessTokenOption{
-			Name: cr.GetTokenName(),
-			Scopes: []gitea.AccessTokenScope{
-				gitea.AccessTokenScopeRepo,
-			},
-		})
-		if err != nil {
-			log.Error(err, "cannot create token")
+	annotations := map[string]string{
+		secretTokenNameKey: tokenName,
+		secretCreatedAtKey: now.UTC().Format(time.RFC3339),
+	}
+	if previous != "" {
+		annotations[secretPreviousTokenNameKey] = previous
+		annotations[secretRevokeAfterKey] = now.Add(rot.gracePeriod).UTC().Format(time.RFC3339)
+	}
+	return r.writeSecret(ctx, cr, secret, corev1.SecretTypeBasicAuth, annotations, map[string][]byte{
+		"username": []byte(u.UserName),
+		"password": []byte(token.Token), // needed for porch
+		"token":    []byte(token.Token), // needed for configsync
+	})
+}
+
+// getSecret returns the secret of the token, nil if it does not exist
+func (r *reconciler) getSecret(ctx context.Context, cr *infrav1alpha1.Token) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, secret); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			log.FromContext(ctx).Error(err, "cannot get secret")
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+			return nil, err
+		}
+		return nil, nil
+	}
+	if adoptSecret(secret) {
+		log.FromContext(ctx).Info("secret adopted", "name", secret.GetName())
+		if err := r.Update(ctx, secret); err != nil {
+			log.FromContext(ctx).Error(err, "cannot update secret")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return nil, err
 		}
-		log.Info("token created", "name", cr.GetName())
-		secret := &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: corev1.SchemeGroupVersion.Identifier(),
-				Kind:       reflect.TypeFor[corev1.Secret]().Name(),
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace:   cr.GetNamespace(),
-				Name:        cr.GetName(),
-				Annotations: cr.GetAnnotations(),
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion: cr.APIVersion,
-						Kind:       cr.Kind,
-						Name:       cr.Name,
-						UID:        cr.UID,
-						Controller: ptr.To(true),
-					},
-				},
-			},
-			Data: map[string][]byte{
-				"username": []byte(u.UserName),
-				"password": []byte(token.Token), // needed for porch
-				"token":    []byte(token.Token), // needed for configsync
-			},
-			Type: corev1.SecretTypeBasicAuth,
+	}
+	return secret, nil
+}
+
+// writeSecret writes the credentials in the secret of the token, the
+// annotations of the token are copied to the secret
+func (r *reconciler) writeSecret(ctx context.Context, cr *infrav1alpha1.Token, secret *corev1.Secret, secretType corev1.SecretType, secretAnnotations map[string]string, data map[string][]byte) error {
+	log := log.FromContext(ctx)
+	annotations := map[string]string{}
+	for k, v := range cr.GetAnnotations() {
+		annotations[k] = v
+	}
+	for k, v := range secretAnnotations {
+		annotations[k] = v
+	}
+	annotations[secretDataHashKey] = dataHash(data)
+
+	if secret != nil && secret.GetResourceVersion() != "" {
+		if secret.Type == secretType {
+			// update the existing secret, the resource version guarantees the
+			// secret did not change since it was read
+			secret.SetAnnotations(annotations)
+			secret.Data = data
+			if metav1.GetControllerOf(secret) == nil {
+				// adopt the secret so changes trigger a reconcile
+				secret.OwnerReferences = append(secret.OwnerReferences, ownerReference(cr))
+			}
+			if err := r.Update(ctx, secret); err != nil {
+				cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+				log.Error(err, "cannot update secret")
+				return err
+			}
+			log.Info("secret for token updated", "name", cr.GetName())
+			return nil
 		}
-		if err := r.Apply(ctx, secret); err != nil {
+		// the type of a secret is immutable
+		if err := r.Delete(ctx, secret); resource.IgnoreNotFound(err) != nil {
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			log.Error(err, "cannot create secret")
+			log.Error(err, "cannot delete secret")
 			return err
 		}
-		log.Info("secret for token created", "name", cr.GetName())
 	}
+
+	newSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       reflect.TypeFor[corev1.Secret]().Name(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cr.GetNamespace(),
+			Name:            cr.GetName(),
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{ownerReference(cr)},
+		},
+		Data: data,
+		Type: secretType,
+	}
+	if err := r.Apply(ctx, newSecret); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		log.Error(err, "cannot create secret")
+		return err
+	}
+	log.Info("secret for token created", "name", cr.GetName())
 	return nil
 }
 
-func (r *reconciler) deleteToken(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Token) error {
-	_, err := giteaClient.DeleteAccessToken(cr.GetTokenName())
+// ownerReference returns the controller reference of the token, the type meta
+// of the token is not set when it is read from the cache
+func ownerReference(cr *infrav1alpha1.Token) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: infrav1alpha1.GroupVersion.String(),
+		Kind:       infrav1alpha1.TokenKind,
+		Name:       cr.GetName(),
+		UID:        cr.GetUID(),
+		Controller: ptr.To(true),
+	}
+}
+
+// getSecretTokenName returns the name of the token in the git server held by
+// the secret, secrets created before the rotation support hold the token
+// with the plain token name
+func getSecretTokenName(cr *infrav1alpha1.Token, secret *corev1.Secret) string {
+	if secret != nil {
+		if name, ok := secret.GetAnnotations()[secretTokenNameKey]; ok && name != "" {
+			return name
+		}
+	}
+	return cr.GetTokenName()
+}
+
+func (r *reconciler) deleteToken(ctx context.Context, gitClient gitprovider.GitClient, cr *infrav1alpha1.Token) error {
+	// a rotated token has a different name in the git server, the previous
+	// token might still be in its grace period
+	secret, err := r.getSecret(ctx, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "cannot delete token")
-		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
-	log.FromContext(ctx).Info("token deleted", "name", cr.GetTokenName())
+	tokenNames := []string{getSecretTokenName(cr, secret)}
+	if secret != nil {
+		if previous := secret.GetAnnotations()[secretPreviousTokenNameKey]; previous != "" {
+			tokenNames = append(tokenNames, previous)
+		}
+	}
+
+	for _, tokenName := range tokenNames {
+		if err := gitClient.DeleteAccessToken(tokenName); err != nil && !gitprovider.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "cannot delete token")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+		log.FromContext(ctx).Info("token deleted", "name", tokenName)
+	}
 	return nil
 }
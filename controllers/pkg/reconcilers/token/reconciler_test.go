@@ -17,12 +17,12 @@ package token
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
-
-	"code.gitea.io/sdk/gitea"
+	"time"
 
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	"github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/nephio-project/nephio/testing/mockeryutils"
@@ -33,13 +33,13 @@ import (
 
 type fields struct {
 	APIPatchingApplicator resource.APIPatchingApplicator
-	giteaClient           giteaclient.GiteaClient
+	gitClient             gitprovider.GitClient
 	finalizer             *resource.APIFinalizer
 }
 type args struct {
-	ctx         context.Context
-	giteaClient giteaclient.GiteaClient
-	cr          *infrav1alpha1.Token
+	ctx       context.Context
+	gitClient gitprovider.GitClient
+	cr        *infrav1alpha1.Token
 }
 type tokenTests struct {
 	name    string
@@ -50,26 +50,29 @@ type tokenTests struct {
 }
 
 func TestDeleteToken(t *testing.T) {
+	clientMock := new(mocks.MockClient)
+	clientMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {})
+
 	tests := []tokenTests{
 		{
 			name:   "Delete Access token reports error",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "DeleteAccessToken",
 					ArgType:    []string{"string"},
-					RetArgList: []interface{}{nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
+					RetArgList: []interface{}{fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Delete Access token success",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "DeleteAccessToken",
 					ArgType:    []string{"string"},
-					RetArgList: []interface{}{nil, nil}},
+					RetArgList: []interface{}{nil}},
 			},
 			wantErr: false,
 		},
@@ -78,25 +81,55 @@ func TestDeleteToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.deleteToken(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.deleteToken(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("deleteToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestCreateToken(t *testing.T) {
+func TestUpsertToken(t *testing.T) {
 
 	clientMock := new(mocks.MockClient)
 	clientMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {})
 	clientMock.On("Patch", nil, mock.AnythingOfType("*v1.Secret"), mock.AnythingOfType("*resource.patch")).Return(nil).Run(func(args mock.Arguments) {})
 
+	// the secret holds the token of the git server
+	secretMock := new(mocks.MockClient)
+	secretMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		secret := args.Get(2).(*corev1.Secret)
+		secret.Data = map[string][]byte{"token": []byte("abc")}
+		secret.Annotations = map[string]string{secretTokenNameKey: "test-token-test-ns", secretDataHashKey: dataHash(secret.Data)}
+	})
+
+	// the secret was edited after the reconciler wrote it
+	modifiedSecretMock := new(mocks.MockClient)
+	modifiedSecretMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		secret := args.Get(2).(*corev1.Secret)
+		secret.Annotations = map[string]string{secretTokenNameKey: "test-token-test-ns", secretDataHashKey: dataHash(map[string][]byte{"token": []byte("abc")})}
+		secret.Data = map[string][]byte{"token": []byte("xyz")}
+	})
+	modifiedSecretMock.On("Patch", nil, mock.AnythingOfType("*v1.Secret"), mock.AnythingOfType("*resource.patch")).Return(nil)
+
+	// the secret was written by a version that did not track the data hash
+	legacySecretMock := new(mocks.MockClient)
+	legacySecretMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		secret := args.Get(2).(*corev1.Secret)
+		secret.Data = map[string][]byte{"token": []byte("abc")}
+	})
+	legacySecretMock.On("Update", nil, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		secret := args.Get(1).(*corev1.Secret)
+		if secret.GetAnnotations()[secretDataHashKey] != dataHash(secret.Data) {
+			t.Errorf("expected the data hash stamped on the adopted secret, got annotations %v", secret.GetAnnotations())
+		}
+	})
+
 	tests := []tokenTests{
 		{
 			name:   "Create Access token reports user auth error",
@@ -104,14 +137,14 @@ func TestCreateToken(t *testing.T) {
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens",
-					ArgType:    []string{"gitea.ListAccessTokensOptions"},
-					RetArgList: []interface{}{nil, nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
+					ArgType:    []string{},
+					RetArgList: []interface{}{nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Create Access token already exists",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(secretMock), nil, nil},
 			args: args{nil, nil, &infrav1alpha1.Token{
 				TypeMeta: metav1.TypeMeta{
 					APIVersion: corev1.SchemeGroupVersion.Identifier(),
@@ -122,47 +155,114 @@ func TestCreateToken(t *testing.T) {
 				}}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens",
-					ArgType: []string{"gitea.ListAccessTokensOptions"},
-					RetArgList: []interface{}{[]*gitea.AccessToken{
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
+						{ID: 123,
+							Name: "test-token-test-ns"},
+					}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			// the token is kept, no token is revoked or created
+			name:   "Create Access token adopts secret without hash",
+			fields: fields{resource.NewAPIPatchingApplicator(legacySecretMock), nil, nil},
+			args: args{nil, nil, &infrav1alpha1.Token{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-ns",
+					Name:      "test-token",
+				}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens",
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
+						{ID: 123,
+							Name: "test-token-test-ns"},
+					}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Create Access token regenerates modified secret",
+			fields: fields{resource.NewAPIPatchingApplicator(modifiedSecretMock), nil, nil},
+			args: args{nil, nil, &infrav1alpha1.Token{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-ns",
+					Name:      "test-token",
+				}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens",
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
 						{ID: 123,
 							Name: "test-token-test-ns"},
-					}, nil, nil}},
+					}, nil}},
+				{MethodName: "DeleteAccessToken", ArgType: []string{"string"}, RetArgList: []interface{}{nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "CreateAccessToken",
+					ArgType: []string{"gitprovider.CreateAccessTokenOptions"},
+					RetArgList: []interface{}{&gitprovider.AccessToken{ID: 124,
+						Name: "test-token-test-ns", Token: "def"}, nil}},
 			},
 			wantErr: false,
 		},
 		{
 			name:   "Create Access token reports user info not found",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens",
-					ArgType: []string{"gitea.ListAccessTokensOptions"},
-					RetArgList: []interface{}{[]*gitea.AccessToken{
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
 						{ID: 123,
 							Name: "test-token-test-ns"},
-					}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, nil, fmt.Errorf("error getting User Information")}},
+					}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, fmt.Errorf("error getting User Information")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Create Access token reports failed to create",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens",
-					ArgType: []string{"gitea.ListAccessTokensOptions"},
-					RetArgList: []interface{}{[]*gitea.AccessToken{
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
 						{ID: 123,
 							Name: "test-token-test-ns"},
-					}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
+					}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
 				{MethodName: "CreateAccessToken",
-					ArgType:    []string{"gitea.CreateAccessTokenOption"},
-					RetArgList: []interface{}{&gitea.AccessToken{}, nil, fmt.Errorf("failed to create token")}},
+					ArgType:    []string{"gitprovider.CreateAccessTokenOptions"},
+					RetArgList: []interface{}{&gitprovider.AccessToken{}, fmt.Errorf("failed to create token")}},
 			},
 			wantErr: true,
 		},
+		{
+			name:   "Create Access token recreates token without secret",
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
+			args: args{nil, nil, &infrav1alpha1.Token{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-ns",
+					Name:      "test-token",
+				}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens",
+					ArgType: []string{},
+					RetArgList: []interface{}{[]*gitprovider.AccessToken{
+						{ID: 123,
+							Name: "test-token-test-ns"},
+					}, nil}},
+				{MethodName: "DeleteAccessToken", ArgType: []string{"string"}, RetArgList: []interface{}{nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "CreateAccessToken",
+					ArgType: []string{"gitprovider.CreateAccessTokenOptions"},
+					RetArgList: []interface{}{&gitprovider.AccessToken{ID: 124,
+						Name: "test-token-test-ns", Token: "def"}, nil}},
+			},
+			wantErr: false,
+		},
 		{
 			name:   "Create Access token reports success",
 			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
@@ -175,12 +275,12 @@ func TestCreateToken(t *testing.T) {
 					Name:      "test-token",
 				}}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "ListAccessTokens", ArgType: []string{"gitea.ListAccessTokensOptions"}, RetArgList: []interface{}{[]*gitea.AccessToken{}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
+				{MethodName: "ListAccessTokens", ArgType: []string{}, RetArgList: []interface{}{[]*gitprovider.AccessToken{}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
 				{MethodName: "CreateAccessToken",
-					ArgType: []string{"gitea.CreateAccessTokenOption"},
-					RetArgList: []interface{}{&gitea.AccessToken{ID: 123,
-						Name: "test-token-test-ns"}, nil, nil}},
+					ArgType: []string{"gitprovider.CreateAccessTokenOptions"},
+					RetArgList: []interface{}{&gitprovider.AccessToken{ID: 123,
+						Name: "test-token-test-ns"}, nil}},
 			},
 			wantErr: false,
 		},
@@ -189,22 +289,134 @@ func TestCreateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.createToken(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
-				t.Errorf("createToken() error = %v, wantErr %v", err, tt.wantErr)
+			if _, err := r.upsertToken(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
+				t.Errorf("upsertToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
+	legacySecretMock.AssertCalled(t, "Update", nil, mock.AnythingOfType("*v1.Secret"))
 }
 
 func initMockeryMocks(tt *tokenTests) {
-	mockGiteaClient := new(giteaclient.MockGiteaClient)
-	tt.args.giteaClient = mockGiteaClient
-	tt.fields.giteaClient = mockGiteaClient
-	mockeryutils.InitMocks(&mockGiteaClient.Mock, tt.mocks)
+	mockGitClient := new(gitprovider.MockGitClient)
+	tt.args.gitClient = mockGitClient
+	tt.fields.gitClient = mockGitClient
+	mockeryutils.InitMocks(&mockGitClient.Mock, tt.mocks)
+}
+
+func TestRotateToken(t *testing.T) {
+	now := time.Now()
+	cr := &infrav1alpha1.Token{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "test-token",
+			Annotations: map[string]string{
+				tokenRotationIntervalKey: "24h",
+				tokenGracePeriodKey:      "10m",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		annotations      map[string]string
+		mocks            []mockeryutils.MockHelper
+		wantUpdate       bool
+		wantRequeueAfter time.Duration
+		wantTokenName    string
+		wantPrevious     string
+	}{
+		"token not due": {
+			annotations: map[string]string{
+				secretTokenNameKey: "test-token-test-ns-1",
+				secretCreatedAtKey: now.Add(-time.Hour).UTC().Format(time.RFC3339),
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens", ArgType: []string{}, RetArgList: []interface{}{[]*gitprovider.AccessToken{{Name: "test-token-test-ns-1"}}, nil}},
+			},
+			wantRequeueAfter: 23 * time.Hour,
+			wantTokenName:    "test-token-test-ns-1",
+		},
+		"token due": {
+			annotations: map[string]string{
+				secretTokenNameKey: "test-token-test-ns-1",
+				secretCreatedAtKey: now.Add(-25 * time.Hour).UTC().Format(time.RFC3339),
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens", ArgType: []string{}, RetArgList: []interface{}{[]*gitprovider.AccessToken{{Name: "test-token-test-ns-1"}}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "CreateAccessToken", ArgType: []string{"gitprovider.CreateAccessTokenOptions"}, RetArgList: []interface{}{&gitprovider.AccessToken{Token: "new"}, nil}},
+			},
+			wantUpdate:       true,
+			wantRequeueAfter: 10 * time.Minute,
+			wantPrevious:     "test-token-test-ns-1",
+		},
+		"grace period expired": {
+			annotations: map[string]string{
+				secretTokenNameKey:         "test-token-test-ns-2",
+				secretCreatedAtKey:         now.Add(-time.Hour).UTC().Format(time.RFC3339),
+				secretPreviousTokenNameKey: "test-token-test-ns-1",
+				secretRevokeAfterKey:       now.Add(-50 * time.Minute).UTC().Format(time.RFC3339),
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "ListAccessTokens", ArgType: []string{}, RetArgList: []interface{}{[]*gitprovider.AccessToken{{Name: "test-token-test-ns-1"}, {Name: "test-token-test-ns-2"}}, nil}},
+				{MethodName: "DeleteAccessToken", ArgType: []string{"string"}, RetArgList: []interface{}{nil}},
+			},
+			wantUpdate:       true,
+			wantRequeueAfter: 23 * time.Hour,
+			wantTokenName:    "test-token-test-ns-2",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{}
+			clientMock := new(mocks.MockClient)
+			clientMock.On("Get", nil, mock.AnythingOfType("types.NamespacedName"), mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				s := args.Get(2).(*corev1.Secret)
+				s.ResourceVersion = "1"
+				s.Type = corev1.SecretTypeBasicAuth
+				s.Data = map[string][]byte{"token": []byte("old")}
+				s.Annotations = map[string]string{secretDataHashKey: dataHash(s.Data)}
+				for k, v := range tt.annotations {
+					s.Annotations[k] = v
+				}
+			})
+			clientMock.On("Update", nil, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+				secret = args.Get(1).(*corev1.Secret)
+			})
+			gitClient := new(gitprovider.MockGitClient)
+			mockeryutils.InitMocks(&gitClient.Mock, tt.mocks)
+
+			r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(clientMock)}
+			requeueAfter, err := r.upsertToken(nil, gitClient, cr.DeepCopy())
+			if err != nil {
+				t.Fatalf("upsertToken() error = %v", err)
+			}
+			// allow for the time passed since now was taken
+			if requeueAfter > tt.wantRequeueAfter || requeueAfter < tt.wantRequeueAfter-time.Minute {
+				t.Errorf("upsertToken() requeueAfter = %s, want %s", requeueAfter, tt.wantRequeueAfter)
+			}
+			clientMock.AssertNumberOfCalls(t, "Update", map[bool]int{true: 1, false: 0}[tt.wantUpdate])
+			if !tt.wantUpdate {
+				return
+			}
+			got := secret.Annotations[secretTokenNameKey]
+			if tt.wantTokenName == "" {
+				// a rotated token gets a new unique name
+				if !strings.HasPrefix(got, cr.GetTokenName()+"-") || got == tt.wantPrevious {
+					t.Errorf("secret token name = %s, want a new token", got)
+				}
+			} else if got != tt.wantTokenName {
+				t.Errorf("secret token name = %s, want %s", got, tt.wantTokenName)
+			}
+			if got := secret.Annotations[secretPreviousTokenNameKey]; got != tt.wantPrevious {
+				t.Errorf("secret previous token name = %s, want %s", got, tt.wantPrevious)
+			}
+		})
+	}
 }
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"fmt"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// token annotations to configure the rotation, the values are go durations
+	// e.g. 720h
+	tokenRotationIntervalKey = "token.nephio.org/rotation-interval"
+	tokenExpiryKey           = "token.nephio.org/expiry"
+	tokenGracePeriodKey      = "token.nephio.org/rotation-grace-period"
+
+	// secret annotations that track the token of the git server
+	secretTokenNameKey         = "token.nephio.org/token-name"
+	secretCreatedAtKey         = "token.nephio.org/created-at"
+	secretPreviousTokenNameKey = "token.nephio.org/previous-token-name"
+	secretRevokeAfterKey       = "token.nephio.org/revoke-after"
+
+	// defaultGracePeriod is the time the previous token stays valid after a
+	// rotation so porch and configsync pick up the new secret
+	defaultGracePeriod = 5 * time.Minute
+
+	// ConditionTypeRotation reports the last and next rotation of the token
+	ConditionTypeRotation  infrav1alpha1.ConditionType   = "Rotation"
+	ConditionReasonRotated infrav1alpha1.ConditionReason = "Rotated"
+)
+
+type rotation struct {
+	// interval after which the token is replaced, 0 disables the rotation
+	interval time.Duration
+	// expiry is the lifetime of the token in the git server, 0 means no expiry
+	expiry      time.Duration
+	gracePeriod time.Duration
+}
+
+func getDuration(cr *infrav1alpha1.Token, key string) (time.Duration, error) {
+	v, ok := cr.GetAnnotations()[key]
+	if !ok || v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid annotation %s: %s", key, err.Error())
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid annotation %s: negative duration %s", key, v)
+	}
+	return d, nil
+}
+
+// getRotation returns the rotation config of the token, when an expiry is set
+// the token is rotated a grace period before it expires
+func getRotation(cr *infrav1alpha1.Token) (*rotation, error) {
+	interval, err := getDuration(cr, tokenRotationIntervalKey)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := getDuration(cr, tokenExpiryKey)
+	if err != nil {
+		return nil, err
+	}
+	gracePeriod, err := getDuration(cr, tokenGracePeriodKey)
+	if err != nil {
+		return nil, err
+	}
+	if gracePeriod == 0 {
+		gracePeriod = defaultGracePeriod
+	}
+	if expiry != 0 {
+		if expiry <= gracePeriod {
+			return nil, fmt.Errorf("token expiry %s must be larger than the grace period %s", expiry, gracePeriod)
+		}
+		if interval == 0 || interval > expiry-gracePeriod {
+			interval = expiry - gracePeriod
+		}
+	}
+	return &rotation{interval: interval, expiry: expiry, gracePeriod: gracePeriod}, nil
+}
+
+func (r *rotation) enabled() bool {
+	return r.interval != 0
+}
+
+// tokenName returns the name of a new token in the git server, rotated
+// tokens get a unique suffix as the previous token lives on during the grace
+// period
+func (r *rotation) tokenName(cr *infrav1alpha1.Token, now time.Time) string {
+	if !r.enabled() {
+		return cr.GetTokenName()
+	}
+	return fmt.Sprintf("%s-%d", cr.GetTokenName(), now.Unix())
+}
+
+func (r *rotation) expiresAt(now time.Time) time.Time {
+	if r.expiry == 0 {
+		return time.Time{}
+	}
+	return now.Add(r.expiry)
+}
+
+// rotationCondition reports the last rotation as transition time and the
+// next rotation in the message
+func rotationCondition(last, next time.Time) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeRotation),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(last),
+		Reason:             string(ConditionReasonRotated),
+		Message:            fmt.Sprintf("last rotation %s, next rotation %s", last.UTC().Format(time.RFC3339), next.UTC().Format(time.RFC3339)),
+	}}
+}
+
+func parseTime(annotations map[string]string, key string) (time.Time, bool) {
+	v, ok := annotations[key]
+	if !ok || v == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
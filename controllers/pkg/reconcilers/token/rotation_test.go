@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"testing"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRotation(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        *rotation
+		wantErr     bool
+	}{
+		"no rotation": {
+			want: &rotation{gracePeriod: defaultGracePeriod},
+		},
+		"interval": {
+			annotations: map[string]string{tokenRotationIntervalKey: "720h"},
+			want:        &rotation{interval: 720 * time.Hour, gracePeriod: defaultGracePeriod},
+		},
+		"expiry before interval": {
+			annotations: map[string]string{
+				tokenRotationIntervalKey: "720h",
+				tokenExpiryKey:           "24h",
+				tokenGracePeriodKey:      "1h",
+			},
+			want: &rotation{interval: 23 * time.Hour, expiry: 24 * time.Hour, gracePeriod: time.Hour},
+		},
+		"expiry without interval": {
+			annotations: map[string]string{tokenExpiryKey: "1h"},
+			want:        &rotation{interval: 55 * time.Minute, expiry: time.Hour, gracePeriod: defaultGracePeriod},
+		},
+		"expiry within grace period": {
+			annotations: map[string]string{tokenExpiryKey: "1m"},
+			wantErr:     true,
+		},
+		"invalid duration": {
+			annotations: map[string]string{tokenRotationIntervalKey: "monthly"},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := getRotation(&infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != *tt.want {
+				t.Errorf("getRotation() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// secretDataHashKey is the hash of the data written by the reconciler, a
+	// secret with a different hash was modified and its credentials are
+	// replaced
+	secretDataHashKey = "token.nephio.org/data-hash"
+
+	// ConditionTypeCredentialVerified reports if the secret holds the
+	// credentials the reconciler created and they still exist in the git
+	// server
+	ConditionTypeCredentialVerified   infrav1alpha1.ConditionType   = "CredentialVerified"
+	ConditionReasonVerified           infrav1alpha1.ConditionReason = "Verified"
+	ConditionReasonRegenerated        infrav1alpha1.ConditionReason = "Regenerated"
+	ConditionReasonVerificationFailed infrav1alpha1.ConditionReason = "VerificationFailed"
+)
+
+// dataHash returns a sha256 of the secret data, independent of the order of
+// the keys
+func dataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write(data[k])
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// secretModified returns true when the data of the secret is not the data
+// written by the reconciler. Secrets without hash were written by a version
+// that did not track the hash, they are adopted by adoptSecret.
+func secretModified(secret *corev1.Secret) bool {
+	hash, ok := secret.GetAnnotations()[secretDataHashKey]
+	return ok && hash != dataHash(secret.Data)
+}
+
+// adoptSecret stamps the hash of the current data on a secret without hash,
+// such that the credentials of secrets written before the hash was tracked are
+// kept and later modifications are detected. It returns false when the secret
+// already has a hash.
+func adoptSecret(secret *corev1.Secret) bool {
+	if _, ok := secret.GetAnnotations()[secretDataHashKey]; ok || len(secret.Data) == 0 {
+		return false
+	}
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[secretDataHashKey] = dataHash(secret.Data)
+	secret.SetAnnotations(annotations)
+	return true
+}
+
+// secretReason returns why the credentials of the secret are regenerated
+func secretReason(secret *corev1.Secret) string {
+	switch {
+	case secret == nil:
+		return "secret not found"
+	case secretModified(secret):
+		return "secret modified"
+	default:
+		return "credentials not found in git server"
+	}
+}
+
+func credentialCondition(status metav1.ConditionStatus, reason infrav1alpha1.ConditionReason, msg string) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeCredentialVerified),
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(reason),
+		Message:            msg,
+	}}
+}
+
+// credentialVerified reports the secret holds the credentials of the git
+// server
+func credentialVerified() infrav1alpha1.Condition {
+	return credentialCondition(metav1.ConditionTrue, ConditionReasonVerified, "secret holds valid credentials of the git server")
+}
+
+// credentialRegenerated reports the credentials were replaced as the secret
+// was missing, modified or the credentials were gone in the git server
+func credentialRegenerated(reason string) infrav1alpha1.Condition {
+	return credentialCondition(metav1.ConditionTrue, ConditionReasonRegenerated, "credentials regenerated: "+reason)
+}
+
+// credentialNotVerified reports the credentials could not be verified or
+// regenerated
+func credentialNotVerified(msg string) infrav1alpha1.Condition {
+	return credentialCondition(metav1.ConditionFalse, ConditionReasonVerificationFailed, msg)
+}
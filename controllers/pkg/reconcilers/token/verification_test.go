@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecretModified(t *testing.T) {
+	data := map[string][]byte{"username": []byte("nephio"), "token": []byte("abc")}
+	hash := dataHash(data)
+
+	tests := map[string]struct {
+		annotations map[string]string
+		data        map[string][]byte
+		want        bool
+	}{
+		"unchanged": {
+			annotations: map[string]string{secretDataHashKey: hash},
+			data:        map[string][]byte{"token": []byte("abc"), "username": []byte("nephio")},
+			want:        false,
+		},
+		"value changed": {
+			annotations: map[string]string{secretDataHashKey: hash},
+			data:        map[string][]byte{"username": []byte("nephio"), "token": []byte("xyz")},
+			want:        true,
+		},
+		"key added": {
+			annotations: map[string]string{secretDataHashKey: hash},
+			data:        map[string][]byte{"username": []byte("nephio"), "token": []byte("abc"), "password": []byte("abc")},
+			want:        true,
+		},
+		// the key and value separators avoid collisions of shifted bytes
+		"key renamed": {
+			annotations: map[string]string{secretDataHashKey: hash},
+			data:        map[string][]byte{"username": []byte("nephio"), "toke": []byte("nabc")},
+			want:        true,
+		},
+		// secrets written before the hash was tracked are adopted
+		"no hash": {
+			data: data,
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}, Data: tt.data}
+			if got := secretModified(secret); got != tt.want {
+				t.Errorf("secretModified() got = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdoptSecret(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{"token": []byte("abc")}}
+	if !adoptSecret(secret) {
+		t.Fatalf("adoptSecret() got = false, want the secret without hash adopted")
+	}
+	if got, want := secret.GetAnnotations()[secretDataHashKey], dataHash(secret.Data); got != want {
+		t.Errorf("adoptSecret() hash got = %s, want %s", got, want)
+	}
+	if adoptSecret(secret) {
+		t.Errorf("adoptSecret() got = true, want a secret with hash not adopted again")
+	}
+	secret.Data["token"] = []byte("xyz")
+	if !secretModified(secret) {
+		t.Errorf("secretModified() got = false, want a modification of an adopted secret detected")
+	}
+	if adoptSecret(&corev1.Secret{}) {
+		t.Errorf("adoptSecret() got = true, want a secret without data not adopted")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyimport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// clabTopology is the subset of the containerlab topology file that is
+// relevant for the inventory
+type clabTopology struct {
+	Name     string `json:"name"`
+	Topology struct {
+		Kinds map[string]clabNode `json:"kinds,omitempty"`
+		Nodes map[string]clabNode `json:"nodes,omitempty"`
+		Links []clabLink          `json:"links,omitempty"`
+	} `json:"topology"`
+}
+
+type clabNode struct {
+	Kind     string            `json:"kind,omitempty"`
+	MgmtIPv4 string            `json:"mgmt-ipv4,omitempty"`
+	MgmtIPv6 string            `json:"mgmt-ipv6,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type clabLink struct {
+	Endpoints []string `json:"endpoints"`
+}
+
+// clabProviders maps the containerlab node kinds to the inventory provider
+var clabProviders = map[string]string{
+	"srl":           nokiaSRLProvider,
+	"nokia_srlinux": nokiaSRLProvider,
+}
+
+// srlInterfaceRegex matches the containerlab srl interface name, e.g. e1-1 or e1-3-1
+var srlInterfaceRegex = regexp.MustCompile(`^e(\d+)-(\d+)(?:-(\d+))?$`)
+
+// parseContainerlab parses a containerlab topology file
+func parseContainerlab(data []byte) (*topology, error) {
+	clab := &clabTopology{}
+	if err := yaml.Unmarshal(data, clab); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal containerlab topology: %w", err)
+	}
+
+	t := newTopology(clab.Name)
+	for name, n := range clab.Topology.Nodes {
+		// node attributes not set on the node are inherited from the kind
+		kind := clab.Topology.Kinds[n.Kind]
+		labels := map[string]string{}
+		for k, v := range kind.Labels {
+			labels[k] = v
+		}
+		for k, v := range n.Labels {
+			labels[k] = v
+		}
+		provider, ok := clabProviders[n.Kind]
+		if !ok {
+			provider = n.Kind
+		}
+		nd := &node{
+			name:     name,
+			provider: provider,
+			labels:   labels,
+		}
+		if n.MgmtIPv4 != "" {
+			nd.address = &n.MgmtIPv4
+		} else if n.MgmtIPv6 != "" {
+			nd.address = &n.MgmtIPv6
+		}
+		t.nodes[name] = nd
+	}
+
+	for _, l := range clab.Topology.Links {
+		if len(l.Endpoints) != 2 {
+			return nil, fmt.Errorf("link %v must have 2 endpoints", l.Endpoints)
+		}
+		eps := [2]linkEndpoint{}
+		for i, ep := range l.Endpoints {
+			nodeName, itfceName, ok := strings.Cut(ep, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid link endpoint %s, expected <node>:<interface>", ep)
+			}
+			eps[i] = linkEndpoint{nodeName: nodeName, interfaceName: itfceName}
+			if n, ok := t.nodes[nodeName]; ok && n.isNetworkNode() {
+				eps[i].interfaceName = toSRLInterfaceName(itfceName)
+			}
+		}
+		t.addLink(eps[0], eps[1])
+	}
+	return t, t.validate()
+}
+
+// toSRLInterfaceName converts the containerlab srl interface name to
+// the srl interface name, e.g. e1-1 -> ethernet-1/1
+func toSRLInterfaceName(name string) string {
+	m := srlInterfaceRegex.FindStringSubmatch(name)
+	if m == nil {
+		return name
+	}
+	if m[3] != "" {
+		return fmt.Sprintf("ethernet-%s/%s/%s", m[1], m[2], m[3])
+	}
+	return fmt.Sprintf("ethernet-%s/%s", m[1], m[2])
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyimport
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// lldpDump is a normalized dump of the lldp neighbors of the fabric nodes,
+// e.g. collected from /system/lldp/interface/neighbor on each node.
+type lldpDump struct {
+	Name      string              `json:"name"`
+	Nodes     map[string]lldpNode `json:"nodes,omitempty"`
+	Neighbors []lldpNeighbor      `json:"neighbors"`
+}
+
+type lldpNode struct {
+	Provider string            `json:"provider,omitempty"`
+	Address  string            `json:"address,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type lldpNeighbor struct {
+	Node              string `json:"node"`
+	Interface         string `json:"interface"`
+	NeighborNode      string `json:"neighborNode"`
+	NeighborInterface string `json:"neighborInterface"`
+}
+
+// parseLLDP parses a lldp neighbor dump, nodes that are only seen as a
+// neighbor and are not listed in the nodes are added without a provider.
+func parseLLDP(data []byte) (*topology, error) {
+	dump := &lldpDump{}
+	if err := yaml.Unmarshal(data, dump); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal lldp neighbor dump: %w", err)
+	}
+
+	t := newTopology(dump.Name)
+	for name, n := range dump.Nodes {
+		n := n
+		nd := &node{
+			name:     name,
+			provider: n.Provider,
+			labels:   n.Labels,
+		}
+		if n.Address != "" {
+			nd.address = &n.Address
+		}
+		t.nodes[name] = nd
+	}
+	for _, nb := range dump.Neighbors {
+		if nb.Node == "" || nb.Interface == "" || nb.NeighborNode == "" || nb.NeighborInterface == "" {
+			return nil, fmt.Errorf("invalid lldp neighbor %v, node, interface, neighborNode and neighborInterface are required", nb)
+		}
+		for _, name := range []string{nb.Node, nb.NeighborNode} {
+			if _, ok := t.nodes[name]; !ok {
+				t.nodes[name] = &node{name: name}
+			}
+		}
+		t.addLink(
+			linkEndpoint{nodeName: nb.Node, interfaceName: nb.Interface},
+			linkEndpoint{nodeName: nb.NeighborNode, interfaceName: nb.NeighborInterface},
+		)
+	}
+	return t, t.validate()
+}
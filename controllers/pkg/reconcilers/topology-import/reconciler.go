@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyimport
+
+import (
+	"context"
+	"fmt"
+
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+func init() {
+	reconcilerinterface.Register("topologyimports", &reconciler{})
+}
+
+const (
+	// importKey selects the configmaps holding a topology to import, the value
+	// defines the format of the topology: containerlab or lldp
+	importKey = "nephio.org/topology-import"
+	// topologyDataKey is the configmap data key holding the topology
+	topologyDataKey = "topology"
+
+	formatContainerlab = "containerlab"
+	formatLLDP         = "lldp"
+)
+
+var parsers = map[string]func([]byte) (*topology, error){
+	formatContainerlab: parseContainerlab,
+	formatLLDP:         parseLLDP,
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps/finalizers,verbs=update
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=nodes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=links,verbs=get;list;watch;create;update;patch;delete
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	if err := invv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
+
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("TopologyImportController").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetLabels()[importKey]
+			return ok
+		}))).
+		Owns(&invv1alpha1.Node{}).
+		Owns(&invv1alpha1.Endpoint{}).
+		Owns(&invv1alpha1.Link{}).
+		Complete(r)
+}
+
+type reconciler struct {
+	resource.APIPatchingApplicator
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconcile", "req", req)
+
+	cr := &corev1.ConfigMap{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// if the resource no longer exists the reconcile loop is done
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			log.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		return ctrl.Result{}, nil
+	}
+	// the imported resources are owned by the configmap and are garbage
+	// collected when it is deleted
+	if resource.WasDeleted(cr) {
+		return ctrl.Result{}, nil
+	}
+	// the typed client does not set the gvk, which is needed for the owner labels
+	cr.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	format := cr.GetLabels()[importKey]
+	parse, ok := parsers[format]
+	if !ok {
+		log.Info("unsupported topology format", "format", format)
+		return ctrl.Result{}, nil
+	}
+	t, err := parse([]byte(cr.Data[topologyDataKey]))
+	if err != nil {
+		// the topology needs to be fixed by the user, so we dont requeue
+		log.Error(err, "cannot parse topology", "format", format)
+		return ctrl.Result{}, nil
+	}
+	// the topology label on the configmap overrides the name in the topology
+	if name, ok := cr.GetLabels()[invv1alpha1.NephioTopologyKey]; ok {
+		t.name = name
+	}
+
+	if err := r.applyInventory(ctx, cr, t.getInventory(cr)); err != nil {
+		msg := "cannot apply inventory"
+		log.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	log.Info("topology imported", "topology", t.name, "nodes", len(t.nodes), "links", len(t.links))
+	return ctrl.Result{}, nil
+}
+
+// applyInventory applies the new inventory resources and deletes the
+// resources of a previous import that are no longer part of the topology
+func (r *reconciler) applyInventory(ctx context.Context, cr client.Object, objs []client.Object) error {
+	newResources := map[string]struct{}{}
+	for _, o := range objs {
+		newResources[getKey(o)] = struct{}{}
+	}
+
+	opts := []client.ListOption{
+		client.InNamespace(cr.GetNamespace()),
+		resourcev1alpha1.GetOwnerLabelsFromCR(cr),
+	}
+	existing := []client.Object{}
+	nodes := &invv1alpha1.NodeList{}
+	if err := r.List(ctx, nodes, opts...); err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		existing = append(existing, &nodes.Items[i])
+	}
+	eps := &invv1alpha1.EndpointList{}
+	if err := r.List(ctx, eps, opts...); err != nil {
+		return err
+	}
+	for i := range eps.Items {
+		existing = append(existing, &eps.Items[i])
+	}
+	links := &invv1alpha1.LinkList{}
+	if err := r.List(ctx, links, opts...); err != nil {
+		return err
+	}
+	for i := range links.Items {
+		existing = append(existing, &links.Items[i])
+	}
+
+	for _, o := range existing {
+		if _, ok := newResources[getKey(o)]; ok {
+			continue
+		}
+		log.FromContext(ctx).Info("prune inventory", "key", getKey(o))
+		if err := r.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	for _, o := range objs {
+		if err := r.Apply(ctx, o); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("cannot apply %s", getKey(o)))
+		}
+	}
+	return nil
+}
+
+func getKey(o client.Object) string {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		// typed list items do not carry their gvk
+		switch o.(type) {
+		case *invv1alpha1.Node:
+			kind = invv1alpha1.NodeKind
+		case *invv1alpha1.Endpoint:
+			kind = invv1alpha1.EndpointKind
+		case *invv1alpha1.Link:
+			kind = invv1alpha1.LinkKind
+		}
+	}
+	return fmt.Sprintf("%s.%s", kind, o.GetName())
+}
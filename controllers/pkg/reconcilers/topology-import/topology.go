@@ -0,0 +1,235 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyimport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	nokiaSRLProvider = "srl.nokia.com"
+)
+
+// topology is the source independent model of an imported topology
+type topology struct {
+	name  string
+	nodes map[string]*node
+	links []link
+}
+
+type node struct {
+	name     string
+	provider string
+	address  *string
+	labels   map[string]string
+}
+
+// isNetworkNode returns true if the node is a fabric node, the other nodes
+// are modelled as clusters attached to the fabric
+func (r *node) isNetworkNode() bool {
+	return r.provider == nokiaSRLProvider
+}
+
+type link struct {
+	endpoints [2]linkEndpoint
+}
+
+type linkEndpoint struct {
+	nodeName      string
+	interfaceName string
+}
+
+func (r link) name() string {
+	return toResourceName(fmt.Sprintf("%s-%s-%s-%s",
+		r.endpoints[0].nodeName, r.endpoints[0].interfaceName,
+		r.endpoints[1].nodeName, r.endpoints[1].interfaceName))
+}
+
+func newTopology(name string) *topology {
+	return &topology{
+		name:  name,
+		nodes: map[string]*node{},
+		links: []link{},
+	}
+}
+
+// addLink adds a link to the topology, links are normalized such that the same
+// link seen from both sides is only added once.
+func (r *topology) addLink(a, b linkEndpoint) {
+	if b.nodeName < a.nodeName || (b.nodeName == a.nodeName && b.interfaceName < a.interfaceName) {
+		a, b = b, a
+	}
+	l := link{endpoints: [2]linkEndpoint{a, b}}
+	for _, existing := range r.links {
+		if existing == l {
+			return
+		}
+	}
+	r.links = append(r.links, l)
+}
+
+func (r *topology) validate() error {
+	if r.name == "" {
+		return fmt.Errorf("topology name is required")
+	}
+	for _, l := range r.links {
+		for _, ep := range l.endpoints {
+			if _, ok := r.nodes[ep.nodeName]; !ok {
+				return fmt.Errorf("link %s references unknown node %s", l.name(), ep.nodeName)
+			}
+		}
+	}
+	return nil
+}
+
+// getInventory returns the inventory resources of the topology: a Node per
+// node, an Endpoint per link endpoint and a Link per link. The resources are
+// labelled with the provider and topology labels the network reconciler uses
+// to select them and with the owner labels of the cr the topology is imported
+// from.
+func (r *topology) getInventory(cr client.Object) []client.Object {
+	objs := []client.Object{}
+
+	nodeNames := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+	for _, name := range nodeNames {
+		n := r.nodes[name]
+		labels := getLabels(cr, r.name, n.provider)
+		for k, v := range n.labels {
+			labels[k] = v
+		}
+		objs = append(objs, invv1alpha1.BuildNode(
+			getObjectMeta(cr, n.name, labels),
+			invv1alpha1.NodeSpec{
+				Provider: n.provider,
+				Address:  n.address,
+				UserDefinedLabels: resourcev1alpha1.UserDefinedLabels{
+					Labels: n.labels,
+				},
+			},
+			invv1alpha1.NodeStatus{},
+		))
+	}
+
+	for _, l := range r.links {
+		objs = append(objs, invv1alpha1.BuildLink(
+			getObjectMeta(cr, l.name(), getLabels(cr, r.name, "")),
+			invv1alpha1.LinkSpec{
+				Endpoints: []invv1alpha1.LinkEndpoint{
+					{NodeName: l.endpoints[0].nodeName, InterfaceName: l.endpoints[0].interfaceName},
+					{NodeName: l.endpoints[1].nodeName, InterfaceName: l.endpoints[1].interfaceName},
+				},
+			},
+			invv1alpha1.LinkStatus{},
+		))
+
+		for i, ep := range l.endpoints {
+			n := r.nodes[ep.nodeName]
+			peer := r.nodes[l.endpoints[1-i].nodeName]
+
+			labels := getLabels(cr, r.name, n.provider)
+			labels[invv1alpha1.NephioNodeNameKey] = ep.nodeName
+			labels[invv1alpha1.NephioInterfaceNameKey] = toLabelValue(ep.interfaceName)
+			labels[invv1alpha1.NephioLinkNameKey] = l.name()
+			// a fabric endpoint connected to a cluster is selected by the
+			// cluster name in the interfaces of the network
+			if n.isNetworkNode() && !peer.isNetworkNode() {
+				labels[invv1alpha1.NephioClusterNameKey] = peer.name
+			}
+			objs = append(objs, invv1alpha1.BuildEndpoint(
+				getObjectMeta(cr, toResourceName(fmt.Sprintf("%s-%s", ep.nodeName, ep.interfaceName)), labels),
+				invv1alpha1.EndpointSpec{
+					EndpointProperties: invv1alpha1.EndpointProperties{
+						NodeName:      ep.nodeName,
+						InterfaceName: ep.interfaceName,
+					},
+					Provider: invv1alpha1.Provider{
+						Provider: n.provider,
+					},
+				},
+				invv1alpha1.EndpointStatus{},
+			))
+		}
+	}
+	return objs
+}
+
+func getLabels(cr client.Object, topology, provider string) map[string]string {
+	labels := resourcev1alpha1.GetOwnerLabelsFromCR(cr)
+	labels[invv1alpha1.NephioTopologyKey] = topology
+	if provider != "" {
+		labels[invv1alpha1.NephioProviderKey] = provider
+	}
+	return labels
+}
+
+func getObjectMeta(cr client.Object, name string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: cr.GetNamespace(),
+		Labels:    labels,
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion:         cr.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+			Kind:               cr.GetObjectKind().GroupVersionKind().Kind,
+			Name:               cr.GetName(),
+			UID:                cr.GetUID(),
+			Controller:         ptr.To(true),
+			BlockOwnerDeletion: ptr.To(true),
+		}},
+	}
+}
+
+// toResourceName converts a name to a valid k8s resource name,
+// e.g. leaf1-ethernet-1/1 -> leaf1-ethernet-1-1
+func toResourceName(s string) string {
+	s = strings.ToLower(s)
+	b := strings.Builder{}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.':
+			b.WriteRune(c)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = name[:validation.DNS1123SubdomainMaxLength]
+	}
+	return name
+}
+
+// toLabelValue converts a value to a valid label value
+func toLabelValue(s string) string {
+	v := strings.NewReplacer("/", "-", ":", "-").Replace(s)
+	if len(v) > validation.LabelValueMaxLength {
+		v = v[:validation.LabelValueMaxLength]
+	}
+	return v
+}
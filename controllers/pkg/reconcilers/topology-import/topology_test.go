@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyimport
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const clabTopologyFile = `
+name: nephio
+topology:
+  kinds:
+    srl:
+      type: ixrd3
+      image: ghcr.io/nokia/srlinux
+      labels:
+        inv.nephio.org/platform: ixrd3
+  nodes:
+    leaf:
+      kind: srl
+      mgmt-ipv4: 172.18.0.10
+    edge1:
+      kind: k8s-kind
+      labels:
+        nephio.org/site: edge1
+  links:
+    - endpoints: ["leaf:e1-1", "edge1:eth1"]
+    - endpoints: ["edge1:eth2", "leaf:e1-3-1"]
+`
+
+const lldpDumpFile = `
+name: nephio
+nodes:
+  leaf1:
+    provider: srl.nokia.com
+  leaf2:
+    provider: srl.nokia.com
+neighbors:
+- {node: leaf1, interface: ethernet-1/49, neighborNode: leaf2, neighborInterface: ethernet-1/49}
+- {node: leaf2, interface: ethernet-1/49, neighborNode: leaf1, neighborInterface: ethernet-1/49}
+- {node: leaf2, interface: ethernet-1/1, neighborNode: edge2, neighborInterface: eth1}
+`
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		parse     func([]byte) (*topology, error)
+		data      string
+		wantNodes []string
+		wantLinks []string
+		wantErr   bool
+	}{
+		"Containerlab": {
+			parse:     parseContainerlab,
+			data:      clabTopologyFile,
+			wantNodes: []string{"edge1", "leaf"},
+			wantLinks: []string{"edge1-eth1-leaf-ethernet-1-1", "edge1-eth2-leaf-ethernet-1-3-1"},
+		},
+		"ContainerlabUnknownNode": {
+			parse: parseContainerlab,
+			data: `
+name: nephio
+topology:
+  nodes:
+    leaf: {kind: srl}
+  links:
+    - endpoints: ["leaf:e1-1", "edge1:eth1"]
+`,
+			wantErr: true,
+		},
+		"ContainerlabInvalidEndpoint": {
+			parse: parseContainerlab,
+			data: `
+name: nephio
+topology:
+  nodes:
+    leaf: {kind: srl}
+  links:
+    - endpoints: ["leaf-e1-1", "leaf:e1-2"]
+`,
+			wantErr: true,
+		},
+		"LLDP": {
+			parse:     parseLLDP,
+			data:      lldpDumpFile,
+			wantNodes: []string{"edge2", "leaf1", "leaf2"},
+			wantLinks: []string{"edge2-eth1-leaf2-ethernet-1-1", "leaf1-ethernet-1-49-leaf2-ethernet-1-49"},
+		},
+		"LLDPNoName": {
+			parse:   parseLLDP,
+			data:    `neighbors: []`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			topo, err := tc.parse([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			nodes := []string{}
+			for name := range topo.nodes {
+				nodes = append(nodes, name)
+			}
+			sort.Strings(nodes)
+			links := []string{}
+			for _, l := range topo.links {
+				links = append(links, l.name())
+			}
+			sort.Strings(links)
+			if diff := cmp.Diff(tc.wantNodes, nodes); diff != "" {
+				t.Errorf("nodes -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLinks, links); diff != "" {
+				t.Errorf("links -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetInventory(t *testing.T) {
+	topo, err := parseContainerlab([]byte(clabTopologyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "lab", Namespace: "default", UID: "1234"},
+	}
+
+	got := map[string]map[string]string{}
+	for _, o := range topo.getInventory(cr) {
+		got[getKey(o)] = o.GetLabels()
+		if len(o.GetOwnerReferences()) != 1 || o.GetOwnerReferences()[0].UID != cr.UID {
+			t.Errorf("%s: expected owner reference to the configmap", getKey(o))
+		}
+		if ref := metav1.GetControllerOf(o); ref == nil || ref.UID != cr.UID {
+			t.Errorf("%s: expected the configmap to be the controller", getKey(o))
+		}
+	}
+
+	want := map[string]map[string]string{
+		"Node.leaf": {
+			invv1alpha1.NephioProviderKey: nokiaSRLProvider,
+			"inv.nephio.org/platform":     "ixrd3",
+		},
+		"Node.edge1": {
+			invv1alpha1.NephioProviderKey: "k8s-kind",
+			invv1alpha1.NephioSiteKey:     "edge1",
+		},
+		"Link.edge1-eth1-leaf-ethernet-1-1":   {},
+		"Link.edge1-eth2-leaf-ethernet-1-3-1": {},
+		"Endpoint.leaf-ethernet-1-1": {
+			invv1alpha1.NephioProviderKey:      nokiaSRLProvider,
+			invv1alpha1.NephioNodeNameKey:      "leaf",
+			invv1alpha1.NephioInterfaceNameKey: "ethernet-1-1",
+			invv1alpha1.NephioClusterNameKey:   "edge1",
+			invv1alpha1.NephioLinkNameKey:      "edge1-eth1-leaf-ethernet-1-1",
+		},
+		"Endpoint.leaf-ethernet-1-3-1": {
+			invv1alpha1.NephioProviderKey:      nokiaSRLProvider,
+			invv1alpha1.NephioNodeNameKey:      "leaf",
+			invv1alpha1.NephioInterfaceNameKey: "ethernet-1-3-1",
+			invv1alpha1.NephioClusterNameKey:   "edge1",
+			invv1alpha1.NephioLinkNameKey:      "edge1-eth2-leaf-ethernet-1-3-1",
+		},
+		"Endpoint.edge1-eth1": {
+			invv1alpha1.NephioProviderKey:      "k8s-kind",
+			invv1alpha1.NephioNodeNameKey:      "edge1",
+			invv1alpha1.NephioInterfaceNameKey: "eth1",
+			invv1alpha1.NephioLinkNameKey:      "edge1-eth1-leaf-ethernet-1-1",
+		},
+		"Endpoint.edge1-eth2": {
+			invv1alpha1.NephioProviderKey:      "k8s-kind",
+			invv1alpha1.NephioNodeNameKey:      "edge1",
+			invv1alpha1.NephioInterfaceNameKey: "eth2",
+			invv1alpha1.NephioLinkNameKey:      "edge1-eth2-leaf-ethernet-1-3-1",
+		},
+	}
+	for key, labels := range want {
+		gotLabels, ok := got[key]
+		if !ok {
+			t.Errorf("missing inventory resource %s", key)
+			continue
+		}
+		if gotLabels[invv1alpha1.NephioTopologyKey] != "nephio" {
+			t.Errorf("%s: expected topology label, got: %v", key, gotLabels)
+		}
+		for k, v := range labels {
+			if gotLabels[k] != v {
+				t.Errorf("%s: label %s want %q, got %q", key, k, v, gotLabels[k])
+			}
+		}
+		if _, ok := labels[invv1alpha1.NephioClusterNameKey]; !ok {
+			if _, ok := gotLabels[invv1alpha1.NephioClusterNameKey]; ok {
+				t.Errorf("%s: unexpected cluster name label", key)
+			}
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("want %d resources, got %d", len(want), len(got))
+	}
+}
+
+func TestToSRLInterfaceName(t *testing.T) {
+	cases := map[string]string{
+		"e1-1":         "ethernet-1/1",
+		"e1-3-1":       "ethernet-1/3/1",
+		"ethernet-1/1": "ethernet-1/1",
+		"mgmt0":        "mgmt0",
+	}
+	for in, want := range cases {
+		if got := toSRLInterfaceName(in); got != want {
+			t.Errorf("toSRLInterfaceName(%s) want %s, got %s", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ContentHashKey is the annotation of the remote copies with the hash of
+	// the content that was applied
+	ContentHashKey = "replication.nephio.org/content-hash"
+	// defaultCheckInterval is the interval the remote copies are checked for
+	// drift, overwritten by REPLICATION_CHECK_INTERVAL
+	defaultCheckInterval = 5 * time.Minute
+)
+
+// Result is the outcome of applying a remote copy
+type Result string
+
+const (
+	ResultCreated   Result = "Created"
+	ResultUpdated   Result = "Updated"
+	ResultUnchanged Result = "Unchanged"
+	ResultHealed    Result = "Healed"
+)
+
+const (
+	// reasonDeleted is a copy that was deleted on the remote cluster
+	reasonDeleted = "deleted"
+	// reasonModified is a copy that was changed on the remote cluster
+	reasonModified = "modified"
+)
+
+// CheckInterval returns the interval the reconcilers requeue synced objects
+// to check their remote copies for drift
+func CheckInterval(ctx context.Context) time.Duration {
+	v, ok := os.LookupEnv("REPLICATION_CHECK_INTERVAL")
+	if !ok {
+		return defaultCheckInterval
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.FromContext(ctx).Error(err, "invalid REPLICATION_CHECK_INTERVAL, using default", "value", v, "default", defaultCheckInterval)
+		return defaultCheckInterval
+	}
+	return d
+}
+
+// Replicator applies remote copies and heals the copies that drifted from
+// what was applied. The hash of the content of a copy is kept in its
+// ContentHashKey annotation, a copy whose annotation matches the desired hash
+// but whose content does not was modified on the remote cluster. The copies
+// applied before are remembered, a copy that is missing afterwards was
+// deleted on the remote cluster. Every heal is counted in the
+// nephio_replication_heals_total metric.
+type Replicator struct {
+	// Controller is the name of the controller in the metric
+	Controller string
+
+	mu      sync.Mutex
+	applied map[string]bool
+}
+
+// NewReplicator returns a Replicator for the controller
+func NewReplicator(controller string) *Replicator {
+	return &Replicator{Controller: controller, applied: map[string]bool{}}
+}
+
+// Apply applies the desired copy to the cluster when the remote copy is
+// missing or differs from it. Fields the remote cluster adds to the copy,
+// e.g. defaults, are not considered drift.
+func (r *Replicator) Apply(ctx context.Context, c resource.APIPatchingApplicator, clusterName string, desired client.Object) (Result, error) {
+	kind := getKind(desired)
+	log := log.FromContext(ctx).WithValues("cluster", clusterName, "kind", kind, "name", desired.GetName(), "namespace", desired.GetNamespace())
+	key := r.key(clusterName, kind, desired.GetNamespace(), desired.GetName())
+
+	content, err := getContent(desired)
+	if err != nil {
+		return "", err
+	}
+	hash, err := hashContent(content)
+	if err != nil {
+		return "", err
+	}
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ContentHashKey] = hash
+	desired.SetAnnotations(annotations)
+
+	current := newEmpty(desired)
+	result := ResultUpdated
+	reason := ""
+	if err := c.Get(ctx, types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}, current); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return "", errors.Wrap(err, "cannot get remote copy")
+		}
+		result = ResultCreated
+		if r.wasApplied(key) {
+			reason = reasonDeleted
+		}
+	} else {
+		currentContent, err := getContent(current)
+		if err != nil {
+			return "", err
+		}
+		currentHash := current.GetAnnotations()[ContentHashKey]
+		matches := isSubset(content, currentContent)
+		if matches && currentHash == hash {
+			r.setApplied(key)
+			return ResultUnchanged, nil
+		}
+		// the copy has the hash of the desired content but not the content,
+		// a different hash is a change of the source
+		if !matches && currentHash == hash {
+			reason = reasonModified
+		}
+	}
+
+	if err := c.Apply(ctx, desired); err != nil {
+		return "", err
+	}
+	r.setApplied(key)
+	if reason == "" {
+		return result, nil
+	}
+	log.Info("remote copy healed", "reason", reason)
+	heals.WithLabelValues(r.Controller, clusterName, kind, reason).Inc()
+	return ResultHealed, nil
+}
+
+// Forget removes the copy from the copies applied before, it is called when
+// the copy is deleted on purpose
+func (r *Replicator) Forget(clusterName, kind, namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.applied, r.key(clusterName, kind, namespace, name))
+}
+
+func (r *Replicator) key(clusterName, kind, namespace, name string) string {
+	return clusterName + "/" + kind + "/" + namespace + "/" + name
+}
+
+func (r *Replicator) wasApplied(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.applied[key]
+}
+
+func (r *Replicator) setApplied(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.applied == nil {
+		r.applied = map[string]bool{}
+	}
+	r.applied[key] = true
+}
+
+// newEmpty returns a zero object of the type and kind of the object. The
+// remote copy is read into it, the decoder of the client keeps the fields an
+// object already has so a field removed on the remote cluster would still be
+// in a copy of the desired object.
+func newEmpty(o client.Object) client.Object {
+	empty := reflect.New(reflect.TypeOf(o).Elem()).Interface().(client.Object)
+	empty.GetObjectKind().SetGroupVersionKind(o.GetObjectKind().GroupVersionKind())
+	return empty
+}
+
+// getKind returns the kind of the object, typed objects read through a
+// client often have no kind set so the name of their type is used
+func getKind(o client.Object) string {
+	if kind := o.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	t := reflect.TypeOf(o)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// getContent returns the fields of the object that are replicated, the
+// labels and annotations without the hash annotation and every top level
+// field apart from the type, metadata and status
+func getContent(o client.Object) (map[string]any, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert object")
+	}
+	// the content of an unstructured object is not a copy, it is not modified
+	content := map[string]any{}
+	for k, v := range u {
+		switch k {
+		case "apiVersion", "kind", "metadata", "status":
+		default:
+			content[k] = v
+		}
+	}
+	annotations := map[string]string{}
+	for k, v := range o.GetAnnotations() {
+		if k != ContentHashKey {
+			annotations[k] = v
+		}
+	}
+	content["metadata"] = map[string]any{"annotations": toAny(annotations), "labels": toAny(o.GetLabels())}
+	return content, nil
+}
+
+// hashContent returns the sha256 of the content, the keys of maps are
+// marshaled sorted so the hash is stable
+func hashContent(content map[string]any) (string, error) {
+	b, err := json.Marshal(content)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal object")
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+func toAny(m map[string]string) map[string]any {
+	r := map[string]any{}
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
+
+// isSubset returns true when every field of desired has the same value in
+// current, fields only in current are ignored
+func isSubset(desired, current any) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		c, ok := current.(map[string]any)
+		if !ok {
+			return len(d) == 0 && current == nil
+		}
+		for k, v := range d {
+			if !isSubset(v, c[k]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		c, ok := current.([]any)
+		if !ok || len(c) != len(d) {
+			return len(d) == 0 && current == nil
+		}
+		for i := range d {
+			if !isSubset(d[i], c[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, current)
+	}
+}
+
+var heals = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "nephio_replication_heals_total",
+	Help: "Remote copies re-applied because they were deleted or modified on the remote cluster",
+}, []string{"controller", "cluster", "kind", "reason"})
+
+func init() {
+	crmetrics.Registry.MustRegister(heals)
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mocks "github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spire", Name: "spire-bundle", Labels: map[string]string{"app": "spire"}},
+		Data:       map[string]string{"bundle.crt": "bundle"},
+	}
+}
+
+func TestReplicatorApply(t *testing.T) {
+	notFound := kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "spire-bundle")
+	cases := map[string]struct {
+		// applied is true when the copy was applied before
+		applied bool
+		getErr  error
+		// remote changes the copy on the remote cluster
+		remote     func(cm *corev1.ConfigMap)
+		want       Result
+		wantApply  bool
+		wantHealed string
+	}{
+		"Created": {
+			getErr:    notFound,
+			want:      ResultCreated,
+			wantApply: true,
+		},
+		"Deleted": {
+			applied:    true,
+			getErr:     notFound,
+			want:       ResultHealed,
+			wantApply:  true,
+			wantHealed: reasonDeleted,
+		},
+		"Unchanged": {
+			applied: true,
+			want:    ResultUnchanged,
+		},
+		"Defaulted": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				cm.SetUID("1234")
+				cm.Labels["example.com/added"] = "true"
+			},
+			want: ResultUnchanged,
+		},
+		"Modified": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				cm.Data["bundle.crt"] = "tampered"
+			},
+			want:       ResultHealed,
+			wantApply:  true,
+			wantHealed: reasonModified,
+		},
+		"LabelRemoved": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				delete(cm.Labels, "app")
+			},
+			want:       ResultHealed,
+			wantApply:  true,
+			wantHealed: reasonModified,
+		},
+		"KeyRemoved": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				delete(cm.Data, "bundle.crt")
+			},
+			want:       ResultHealed,
+			wantApply:  true,
+			wantHealed: reasonModified,
+		},
+		"HashRemoved": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				delete(cm.Annotations, ContentHashKey)
+			},
+			want:      ResultUpdated,
+			wantApply: true,
+		},
+		"SourceChanged": {
+			applied: true,
+			remote: func(cm *corev1.ConfigMap) {
+				cm.Data["bundle.crt"] = "old"
+				cm.Annotations[ContentHashKey] = "old"
+			},
+			want:      ResultUpdated,
+			wantApply: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			applied := false
+			remoteMock := new(mocks.MockClient)
+			remoteMock.On("Get", context.TODO(), types.NamespacedName{Namespace: "spire", Name: "spire-bundle"}, mock.AnythingOfType("*v1.ConfigMap")).Return(tc.getErr).Run(func(args mock.Arguments) {
+				if tc.getErr != nil {
+					return
+				}
+				// the remote copy is the applied copy changed on the cluster
+				remote := newTestConfigMap()
+				remote.SetAnnotations(map[string]string{ContentHashKey: testContentHash(t, remote)})
+				if tc.remote != nil {
+					tc.remote(remote)
+				}
+				decodeRemote(t, remote, args.Get(2).(*corev1.ConfigMap))
+			})
+			remoteMock.On("Create", context.TODO(), mock.AnythingOfType("*v1.ConfigMap")).Return(nil).Run(func(args mock.Arguments) {
+				applied = true
+			})
+			remoteMock.On("Patch", context.TODO(), mock.AnythingOfType("*v1.ConfigMap"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				applied = true
+			})
+
+			cluster := "heal-" + name
+			r := NewReplicator("test")
+			if tc.applied {
+				r.setApplied(r.key(cluster, "ConfigMap", "spire", "spire-bundle"))
+			}
+			cm := newTestConfigMap()
+			got, err := r.Apply(context.TODO(), resource.NewAPIPatchingApplicator(remoteMock), cluster, cm)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantApply, applied)
+			assert.NotEmpty(t, cm.GetAnnotations()[ContentHashKey])
+			for _, reason := range []string{reasonDeleted, reasonModified} {
+				want := 0.0
+				if reason == tc.wantHealed {
+					want = 1
+				}
+				assert.Equal(t, want, testutil.ToFloat64(heals.WithLabelValues("test", cluster, "ConfigMap", reason)), reason)
+			}
+		})
+	}
+}
+
+// testContentHash returns the content hash of the object
+func testContentHash(t *testing.T, o client.Object) string {
+	t.Helper()
+	content, err := getContent(o)
+	assert.NoError(t, err)
+	hash, err := hashContent(content)
+	assert.NoError(t, err)
+	return hash
+}
+
+// decodeRemote decodes the remote copy into the object with the serializer of
+// the client, the decoder keeps the fields the object already has
+func decodeRemote(t *testing.T, remote *corev1.ConfigMap, into *corev1.ConfigMap) {
+	t.Helper()
+	remote.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	b, err := json.Marshal(remote)
+	assert.NoError(t, err)
+	assert.NoError(t, runtime.DecodeInto(scheme.Codecs.UniversalDecoder(), b, into))
+}
+
+func TestNewEmpty(t *testing.T) {
+	// a remote copy with a key and the hash removed is decoded into the object
+	desired := newTestConfigMap()
+	desired.Data["extra"] = "value"
+	desired.SetAnnotations(map[string]string{ContentHashKey: "hash"})
+	remote := newTestConfigMap()
+
+	current := newEmpty(desired).(*corev1.ConfigMap)
+	decodeRemote(t, remote, current)
+	assert.Equal(t, remote.Data, current.Data)
+	assert.Empty(t, current.GetAnnotations()[ContentHashKey])
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	u.SetName("spire-bundle")
+	empty := newEmpty(u).(*unstructured.Unstructured)
+	assert.Equal(t, u.GroupVersionKind(), empty.GroupVersionKind())
+	assert.Empty(t, empty.GetName())
+}
+
+func TestReplicatorForget(t *testing.T) {
+	r := NewReplicator("test")
+	r.setApplied(r.key("edge01", "Secret", "default", "git-token"))
+	r.Forget("edge01", "Secret", "default", "git-token")
+	assert.False(t, r.wasApplied(r.key("edge01", "Secret", "default", "git-token")))
+}
+
+func TestCheckInterval(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		want  string
+	}{
+		"Default": {want: "5m0s"},
+		"Set":     {value: "30s", want: "30s"},
+		"Invalid": {value: "often", want: "5m0s"},
+		"Zero":    {value: "0s", want: "5m0s"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv("REPLICATION_CHECK_INTERVAL", tc.value)
+			}
+			assert.Equal(t, tc.want, CheckInterval(context.TODO()).String())
+		})
+	}
+}
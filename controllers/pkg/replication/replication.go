@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replication holds the helpers the reconcilers use to copy objects
+// of the management cluster to remote clusters.
+package replication
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResetMetadata removes the metadata of the object that is specific to the
+// cluster it was read from and sets the namespace of the remote copy
+func ResetMetadata(o client.Object, namespace string) {
+	o.SetResourceVersion("")
+	o.SetUID("")
+	o.SetGeneration(0)
+	o.SetCreationTimestamp(metav1.Time{})
+	o.SetDeletionTimestamp(nil)
+	o.SetManagedFields(nil)
+	o.SetOwnerReferences(nil)
+	o.SetFinalizers(nil)
+	o.SetNamespace(namespace)
+}
+
+// RemoteCopy returns a copy of the object for a remote cluster in the
+// namespace, without the cluster specific metadata and the status
+func RemoteCopy(u *unstructured.Unstructured, namespace string) *unstructured.Unstructured {
+	newu := u.DeepCopy()
+	ResetMetadata(newu, namespace)
+	unstructured.RemoveNestedField(newu.Object, "status")
+	return newu
+}
+
+// NamespaceExists returns true when the namespace exists in the cluster
+func NamespaceExists(ctx context.Context, c client.Reader, namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// Render executes the go templates in the string values of the object with
+// the data, e.g. {{ .ClusterName }}. The keys of the object are not rendered
+// and a missing key in the data is an error.
+func Render(u *unstructured.Unstructured, data any) error {
+	rendered, err := render(u.Object, data, "")
+	if err != nil {
+		return err
+	}
+	u.Object = rendered.(map[string]any)
+	return nil
+}
+
+func render(v any, data any, path string) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			r, err := render(e, data, path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+		return v, nil
+	case []any:
+		for i, e := range v {
+			r, err := render(e, data, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+		return v, nil
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		t, err := template.New(path).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse template of %s: %w", path, err)
+		}
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("cannot render template of %s: %w", path, err)
+		}
+		return buf.String(), nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRemoteCopy(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":              "cm",
+			"namespace":         "default",
+			"resourceVersion":   "10",
+			"uid":               "1234",
+			"creationTimestamp": "2025-01-01T00:00:00Z",
+			"finalizers":        []any{"example.com/finalizer"},
+			"ownerReferences":   []any{map[string]any{"apiVersion": "v1", "kind": "Secret", "name": "owner", "uid": "5678"}},
+			"labels":            map[string]any{"app": "demo"},
+		},
+		"data":   map[string]any{"key": "value"},
+		"status": map[string]any{"phase": "ready"},
+	}}
+
+	got := RemoteCopy(u, "remote")
+	want := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      "cm",
+			"namespace": "remote",
+			"labels":    map[string]any{"app": "demo"},
+		},
+		"data": map[string]any{"key": "value"},
+	}
+	if diff := cmp.Diff(want, got.Object); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if u.GetNamespace() != "default" || u.GetResourceVersion() != "10" {
+		t.Errorf("RemoteCopy() modified the object")
+	}
+}
+
+func TestResetMetadata(t *testing.T) {
+	o := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default", ResourceVersion: "1", UID: "1234", Finalizers: []string{"example.com/finalizer"}}}
+	ResetMetadata(o, "remote")
+	if diff := cmp.Diff(metav1.ObjectMeta{Name: "secret", Namespace: "remote"}, o.ObjectMeta); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := struct {
+		ClusterName string
+		Values      map[string]string
+	}{
+		ClusterName: "edge01",
+		Values:      map[string]string{"region": "west"},
+	}
+	cases := map[string]struct {
+		object  map[string]any
+		want    map[string]any
+		wantErr bool
+	}{
+		"Values": {
+			object: map[string]any{
+				"data": map[string]any{
+					"cluster": "{{ .ClusterName }}",
+					"region":  "{{ .Values.region }}-1",
+					"plain":   "value",
+					"{{ key":  "not rendered",
+				},
+				"list":  []any{"{{ .ClusterName }}", int64(1)},
+				"count": int64(2),
+			},
+			want: map[string]any{
+				"data": map[string]any{
+					"cluster": "edge01",
+					"region":  "west-1",
+					"plain":   "value",
+					"{{ key":  "not rendered",
+				},
+				"list":  []any{"edge01", int64(1)},
+				"count": int64(2),
+			},
+		},
+		"MissingValue": {
+			object:  map[string]any{"data": map[string]any{"zone": "{{ .Values.zone }}"}},
+			wantErr: true,
+		},
+		"InvalidTemplate": {
+			object:  map[string]any{"data": map[string]any{"zone": "{{ .Values.zone "}},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: tc.object}
+			err := Render(u, data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, u.Object); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"strings"
 
+	"github.com/nephio-project/nephio/controllers/pkg/gitwebhook"
 	porchclient "github.com/nephio-project/nephio/controllers/pkg/porch/client"
 	ctrlrconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconciler "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
@@ -53,9 +54,12 @@ import (
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/bootstrap-secret"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/generic-specializer"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/network"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/network-drift"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/remote-sync"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/repository"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/spire-bootstrap"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/token"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/topology-import"
 )
 
 var (
@@ -68,6 +72,7 @@ func main() {
 	var probeAddr string
 	var enabledReconcilersString string
 	var approvalRequeueDuration int64
+	var gitWebhookAddr string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -75,6 +80,7 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&enabledReconcilersString, "reconcilers", "", "reconcilers that should be enabled; use * to mean 'enable all'")
+	flag.StringVar(&gitWebhookAddr, "git-webhook-bind-address", ":8082", "The address the git push webhook endpoint binds to, empty disables it.")
 	flag.Int64Var(&approvalRequeueDuration, "approval-requeue-duration", 15, "Interval to allow before requeue of the approval controller reconcile key")
 
 	opts := zap.Options{
@@ -171,6 +177,13 @@ func main() {
 		setupLog.Info("enabled reconcilers", "reconcilers", strings.Join(enabled, ","))
 	}
 
+	if gitWebhookAddr != "" {
+		if err := mgr.Add(gitwebhook.New(gitWebhookAddr, porchClient)); err != nil {
+			setupLog.Error(err, "cannot add git webhook receiver")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")